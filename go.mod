@@ -7,14 +7,17 @@ require (
 	github.com/creachadair/jrpc2 v0.11.1
 	github.com/fsnotify/fsnotify v1.4.9
 	github.com/gammazero/workerpool v1.1.1
+	github.com/golang/protobuf v1.3.4
 	github.com/google/go-cmp v0.5.4
 	github.com/google/uuid v1.1.5
+	github.com/hashicorp/go-hclog v0.14.1
 	github.com/hashicorp/go-multierror v1.1.0
+	github.com/hashicorp/go-plugin v1.4.0
 	github.com/hashicorp/go-version v1.2.1
 	github.com/hashicorp/hcl-lang v0.0.0-20201209145723-0c4061e492db
 	github.com/hashicorp/hcl/v2 v2.8.2
 	github.com/hashicorp/terraform-exec v0.12.0
-	github.com/hashicorp/terraform-json v0.8.0
+	github.com/hashicorp/terraform-json v0.10.0
 	github.com/hashicorp/terraform-schema v0.0.0-20201208163444-44d0347ab290
 	github.com/mh-cbon/go-fmt-fail v0.0.0-20160815164508-67765b3fbcb5
 	github.com/mitchellh/cli v1.1.2
@@ -26,4 +29,7 @@ require (
 	github.com/spf13/afero v1.5.1
 	github.com/stretchr/testify v1.7.0
 	github.com/vektra/mockery/v2 v2.6.0
+	github.com/zclconf/go-cty v1.7.1-0.20201110003513-1338293a79a9
+	google.golang.org/grpc v1.27.1
+	gopkg.in/yaml.v3 v3.0.0-20200313102051-9f266ea9e77c
 )
@@ -96,6 +96,9 @@ func (c *InspectModuleCommand) inspect(rootPath string) error {
 	err = walker.StartWalking(ctx, rootPath, func(ctx context.Context, dir string) error {
 		mod, err := modMgr.AddAndStartLoadingModule(ctx, dir)
 		if err != nil {
+			if module.IsModuleAlreadyAdded(err) {
+				return nil
+			}
 			return err
 		}
 		<-mod.LoadingDone()
@@ -32,6 +32,20 @@ type ServeCommand struct {
 	tfExecTimeout string
 	cpuProfile    string
 	memProfile    string
+
+	providerSchemaPaths stringSliceFlag
+}
+
+// stringSliceFlag represents a flag which can be passed multiple times
+type stringSliceFlag []string
+
+func (f *stringSliceFlag) String() string {
+	return strings.Join(*f, ",")
+}
+
+func (f *stringSliceFlag) Set(value string) error {
+	*f = append(*f, value)
+	return nil
 }
 
 func (c *ServeCommand) flags() *flag.FlagSet {
@@ -45,6 +59,8 @@ func (c *ServeCommand) flags() *flag.FlagSet {
 	fs.StringVar(&c.tfExecLogPath, "tf-log-file", "", "path to a file for Terraform executions"+
 		" to be logged into with support for variables (e.g. Timestamp, Pid, Ppid) via Go template"+
 		" syntax {{.VarName}}")
+	fs.Var(&c.providerSchemaPaths, "provider-schema", "path to a JSON file (or directory of files) "+
+		"with provider schemas as produced by 'terraform providers schema -json' (can be passed multiple times)")
 	fs.StringVar(&c.cpuProfile, "cpuprofile", "", "file into which to write CPU profile (if not empty)")
 	fs.StringVar(&c.memProfile, "memprofile", "", "file into which to write memory profile (if not empty)")
 
@@ -134,6 +150,20 @@ func (c *ServeCommand) Run(args []string) int {
 		logger.Printf("Terraform exec path set to %q", path)
 	}
 
+	if len(c.providerSchemaPaths) > 0 {
+		paths := make([]string, len(c.providerSchemaPaths))
+		for i, path := range c.providerSchemaPaths {
+			absPath, err := filepath.Abs(path)
+			if err != nil {
+				c.Ui.Error(fmt.Sprintf("Invalid provider schema path %q: %s", path, err))
+				return 1
+			}
+			paths[i] = absPath
+		}
+		ctx = lsctx.WithProviderSchemaPaths(ctx, paths)
+		logger.Printf("Provider schemas will be loaded from %q", paths)
+	}
+
 	logger.Printf("Starting terraform-ls %s", c.Version)
 
 	ctx = lsctx.WithLanguageServerVersion(ctx, c.Version)
@@ -28,6 +28,7 @@ var (
 	ctxTfExecLogPath        = &contextKey{"terraform executor log path"}
 	ctxTfExecTimeout        = &contextKey{"terraform execution timeout"}
 	ctxWatcher              = &contextKey{"watcher"}
+	ctxDirWatcher           = &contextKey{"directory watcher"}
 	ctxModuleMngr           = &contextKey{"module manager"}
 	ctxTfFormatterFinder    = &contextKey{"terraform formatter finder"}
 	ctxModuleCaFi           = &contextKey{"module candidate finder"}
@@ -36,9 +37,13 @@ var (
 	ctxRootDir              = &contextKey{"root directory"}
 	ctxCommandPrefix        = &contextKey{"command prefix"}
 	ctxDiags                = &contextKey{"diagnostics"}
+	ctxDiagsScheduler       = &contextKey{"diagnostics scheduler"}
 	ctxLsVersion            = &contextKey{"language server version"}
 	ctxProgressToken        = &contextKey{"progress token"}
 	ctxExperimentalFeatures = &contextKey{"experimental features"}
+	ctxValidationOptions    = &contextKey{"validation options"}
+	ctxCompletionOptions    = &contextKey{"completion options"}
+	ctxProviderSchemaPaths  = &contextKey{"provider schema paths"}
 )
 
 func missingContextErr(ctxKey *contextKey) *MissingContextErr {
@@ -103,6 +108,15 @@ func TerraformExecTimeout(ctx context.Context) (time.Duration, bool) {
 	return path, ok
 }
 
+func WithProviderSchemaPaths(ctx context.Context, paths []string) context.Context {
+	return context.WithValue(ctx, ctxProviderSchemaPaths, paths)
+}
+
+func ProviderSchemaPaths(ctx context.Context) ([]string, bool) {
+	paths, ok := ctx.Value(ctxProviderSchemaPaths).([]string)
+	return paths, ok
+}
+
 func WithWatcher(ctx context.Context, w watcher.Watcher) context.Context {
 	return context.WithValue(ctx, ctxWatcher, w)
 }
@@ -115,6 +129,18 @@ func Watcher(ctx context.Context) (watcher.Watcher, error) {
 	return w, nil
 }
 
+func WithDirWatcher(ctx context.Context, w watcher.DirWatcher) context.Context {
+	return context.WithValue(ctx, ctxDirWatcher, w)
+}
+
+func DirWatcher(ctx context.Context) (watcher.DirWatcher, error) {
+	w, ok := ctx.Value(ctxDirWatcher).(watcher.DirWatcher)
+	if !ok {
+		return nil, missingContextErr(ctxDirWatcher)
+	}
+	return w, nil
+}
+
 func WithModuleManager(ctx context.Context, wm module.ModuleManager) context.Context {
 	return context.WithValue(ctx, ctxModuleMngr, wm)
 }
@@ -253,6 +279,18 @@ func LanguageServerVersion(ctx context.Context) (string, bool) {
 	return version, true
 }
 
+func WithDiagnosticsScheduler(ctx context.Context, s *diagnostics.Scheduler) context.Context {
+	return context.WithValue(ctx, ctxDiagsScheduler, s)
+}
+
+func DiagnosticsScheduler(ctx context.Context) (*diagnostics.Scheduler, error) {
+	s, ok := ctx.Value(ctxDiagsScheduler).(*diagnostics.Scheduler)
+	if !ok {
+		return nil, missingContextErr(ctxDiagsScheduler)
+	}
+	return s, nil
+}
+
 func WithProgressToken(ctx context.Context, pt lsp.ProgressToken) context.Context {
 	return context.WithValue(ctx, ctxProgressToken, pt)
 }
@@ -286,3 +324,47 @@ func ExperimentalFeatures(ctx context.Context) (settings.ExperimentalFeatures, e
 	}
 	return *expFeatures, nil
 }
+
+func WithValidationOptions(ctx context.Context, opts *settings.ValidationOptions) context.Context {
+	return context.WithValue(ctx, ctxValidationOptions, opts)
+}
+
+func SetValidationOptions(ctx context.Context, opts settings.ValidationOptions) error {
+	o, ok := ctx.Value(ctxValidationOptions).(*settings.ValidationOptions)
+	if !ok {
+		return missingContextErr(ctxValidationOptions)
+	}
+
+	*o = opts
+	return nil
+}
+
+func ValidationOptions(ctx context.Context) (settings.ValidationOptions, error) {
+	opts, ok := ctx.Value(ctxValidationOptions).(*settings.ValidationOptions)
+	if !ok {
+		return settings.ValidationOptions{}, missingContextErr(ctxValidationOptions)
+	}
+	return *opts, nil
+}
+
+func WithCompletionOptions(ctx context.Context, opts *settings.CompletionOptions) context.Context {
+	return context.WithValue(ctx, ctxCompletionOptions, opts)
+}
+
+func SetCompletionOptions(ctx context.Context, opts settings.CompletionOptions) error {
+	o, ok := ctx.Value(ctxCompletionOptions).(*settings.CompletionOptions)
+	if !ok {
+		return missingContextErr(ctxCompletionOptions)
+	}
+
+	*o = opts
+	return nil
+}
+
+func CompletionOptions(ctx context.Context) (settings.CompletionOptions, error) {
+	opts, ok := ctx.Value(ctxCompletionOptions).(*settings.CompletionOptions)
+	if !ok {
+		return settings.CompletionOptions{}, missingContextErr(ctxCompletionOptions)
+	}
+	return *opts, nil
+}
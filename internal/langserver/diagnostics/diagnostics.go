@@ -4,7 +4,12 @@ import (
 	"context"
 	"log"
 	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
 	"sync"
+	"time"
 
 	"github.com/creachadair/jrpc2"
 	"github.com/hashicorp/hcl/v2"
@@ -18,64 +23,530 @@ type diagContext struct {
 	uri    lsp.DocumentURI
 	source string
 	diags  []lsp.Diagnostic
+
+	// version represents version of the document the diagnostics
+	// were computed for, or 0 if they are not tied to any version
+	version int
+
+	// clear represents diagnostics which are to be
+	// cleared instead of publishing the ones above
+	clear *clearRequest
+}
+
+// clearRequest represents cached diagnostics to be cleared, either
+// of a single file, of a single source for files in a directory,
+// or of all files in a directory
+type clearRequest struct {
+	uri    lsp.DocumentURI
+	dir    lsp.DocumentURI
+	source string
 }
 
 type diagnosticSource string
 
 type fileDiagnostics map[diagnosticSource][]lsp.Diagnostic
 
+type queueKey struct {
+	uri    lsp.DocumentURI
+	source string
+	clear  clearRequest
+}
+
+// DocumentVersionFunc returns the current version
+// of the given document, if it is open
+type DocumentVersionFunc func(uri lsp.DocumentURI) (int, bool)
+
 // Notifier is a type responsible for queueing hcl diagnostics to be converted
-// and sent to the client
+// and sent to the client.
+//
+// In pull mode diagnostics are not sent, but kept for the client
+// to pull them via Report or Reports instead.
 type Notifier struct {
-	logger         *log.Logger
-	sessCtx        context.Context
-	diags          chan diagContext
-	diagsCache     map[lsp.DocumentURI]fileDiagnostics
-	closeDiagsOnce sync.Once
+	logger      *log.Logger
+	sessCtx     context.Context
+	versionFunc DocumentVersionFunc
+
+	// cacheMu guards diagsCache and resultIDs, as well as
+	// sending, such that queued diagnostics are sent in order
+	diagsCache map[lsp.DocumentURI]fileDiagnostics
+	resultIDs  map[lsp.DocumentURI]string
+	cacheMu    *sync.Mutex
+
+	// resultIDPrefix makes result IDs unique across sessions,
+	// such that IDs the client kept from a previous session
+	// never match diagnostics of this one
+	resultIDPrefix string
+	lastResultID   uint64
+
+	pullMode       bool
+	refreshSupport bool
+
+	// queue holds diagnostics waiting to be sent, where newer
+	// diagnostics replace any queued ones for the same file and source
+	queue      []queueKey
+	queued     map[queueKey]diagContext
+	queueMu    *sync.Mutex
+	queueReady chan struct{}
+	closed     bool
 }
 
 func NewNotifier(sessCtx context.Context, logger *log.Logger) *Notifier {
 	n := &Notifier{
 		logger:     logger,
 		sessCtx:    sessCtx,
-		diags:      make(chan diagContext, 50),
 		diagsCache: make(map[lsp.DocumentURI]fileDiagnostics),
+		resultIDs:  make(map[lsp.DocumentURI]string),
+		cacheMu:    &sync.Mutex{},
+		queued:     make(map[queueKey]diagContext),
+		queueMu:    &sync.Mutex{},
+		queueReady: make(chan struct{}, 1),
+
+		resultIDPrefix: strconv.FormatInt(time.Now().UnixNano(), 36),
 	}
 	go n.notify()
 	return n
 }
 
+// SetDocumentVersionFunc sets a function used to look up current versions
+// of open documents, such that diagnostics computed for an older version
+// are discarded rather than sent
+func (n *Notifier) SetDocumentVersionFunc(f DocumentVersionFunc) {
+	n.versionFunc = f
+}
+
+// SetPullMode makes the notifier keep diagnostics for the client
+// to pull them instead of sending them. If refreshSupport is true,
+// the client is asked to pull diagnostics again whenever they change.
+func (n *Notifier) SetPullMode(refreshSupport bool) {
+	n.cacheMu.Lock()
+	defer n.cacheMu.Unlock()
+
+	n.pullMode = true
+	n.refreshSupport = refreshSupport
+}
+
+// Report represents all current diagnostics of a file
+type Report struct {
+	URI lsp.DocumentURI
+
+	// ResultID identifies this particular result and only
+	// changes along with the diagnostics
+	ResultID    string
+	Diagnostics []lsp.Diagnostic
+
+	// Version is the current version of the document,
+	// or 0 if it is not open
+	Version int
+}
+
+// Report returns current diagnostics of the given file, incl. any
+// queued ones, or false if no diagnostics were ever published for it
+func (n *Notifier) Report(docURI lsp.DocumentURI) (Report, bool) {
+	n.Flush()
+
+	n.cacheMu.Lock()
+	defer n.cacheMu.Unlock()
+
+	if _, ok := n.resultIDs[docURI]; !ok {
+		return Report{}, false
+	}
+	return n.report(docURI), true
+}
+
+// Reports returns current diagnostics of all files,
+// for which any diagnostics were ever published
+func (n *Notifier) Reports() []Report {
+	n.Flush()
+
+	n.cacheMu.Lock()
+	defer n.cacheMu.Unlock()
+
+	reports := make([]Report, 0, len(n.resultIDs))
+	for docURI := range n.resultIDs {
+		reports = append(reports, n.report(docURI))
+	}
+	sort.Slice(reports, func(i, j int) bool {
+		return reports[i].URI < reports[j].URI
+	})
+	return reports
+}
+
+func (n *Notifier) report(docURI lsp.DocumentURI) Report {
+	r := Report{
+		URI:         docURI,
+		ResultID:    n.resultIDs[docURI],
+		Diagnostics: n.cachedDiags(docURI),
+	}
+	if n.versionFunc != nil {
+		r.Version, _ = n.versionFunc(docURI)
+	}
+	return r
+}
+
+// Flush sends all queued diagnostics right away,
+// rather than waiting for them to be sent in the background
+func (n *Notifier) Flush() {
+	n.sendQueued()
+}
+
 // PublishHCLDiags accepts a map of hcl diagnostics per file and queues them for publishing.
 // A dir path is passed which is joined with the filename keys of the map, to form a file URI.
 // A source string is passed and set for each diagnostic, this is typically displayed in the client UI.
 func (n *Notifier) PublishHCLDiags(ctx context.Context, dirPath string, diags map[string]hcl.Diagnostics, source string) {
+	n.PublishDetailedHCLDiags(ctx, dirPath, diags, source, nil)
+}
+
+// PublishDetailedHCLDiags is like PublishHCLDiags, but also sets details
+// returned by detailsFunc on each diagnostic, such as its code,
+// related information, or tags (so that clients can e.g. render
+// unused code faded out).
+func (n *Notifier) PublishDetailedHCLDiags(ctx context.Context, dirPath string, diags map[string]hcl.Diagnostics, source string, detailsFunc ilsp.DiagnosticDetailsFunc) {
+	if ctx.Err() != nil {
+		// diagnostics were computed by a cancelled (stale) run
+		return
+	}
+
+	lspDiags := make(map[string][]lsp.Diagnostic, len(diags))
+	for filename, ds := range diags {
+		lspDiags[filename] = ilsp.DetailedHCLDiagsToLSP(ds, dirPath, source, detailsFunc)
+	}
+	n.publish(ctx, dirPath, lspDiags, source)
+}
+
+// PublishDiags accepts a map of already converted diagnostics per file
+// and queues them for publishing, like PublishHCLDiags. The source
+// is only used to merge them with diagnostics of other sources.
+func (n *Notifier) PublishDiags(ctx context.Context, dirPath string, diags map[string][]lsp.Diagnostic, source string) {
+	n.publish(ctx, dirPath, diags, source)
+}
+
+// ClearDiagsForURI queues clearing of all diagnostics previously published
+// for the given file, e.g. after the file was deleted.
+func (n *Notifier) ClearDiagsForURI(ctx context.Context, docURI lsp.DocumentURI) {
+	n.enqueue(diagContext{
+		ctx:   ctx,
+		clear: &clearRequest{uri: docURI},
+	})
+}
+
+// ClearDiagsForSource queues clearing of diagnostics previously published
+// from the given source for files in the given directory, leaving
+// diagnostics from other sources in place.
+func (n *Notifier) ClearDiagsForSource(ctx context.Context, dirPath string, source string) {
+	n.enqueue(diagContext{
+		ctx: ctx,
+		clear: &clearRequest{
+			dir:    lsp.DocumentURI(uri.FromPath(dirPath)),
+			source: source,
+		},
+	})
+}
+
+// ClearDiagsForDir queues clearing of all diagnostics previously published
+// for files in the given directory, e.g. after the module was removed.
+func (n *Notifier) ClearDiagsForDir(ctx context.Context, dirPath string) {
+	n.enqueue(diagContext{
+		ctx:   ctx,
+		clear: &clearRequest{dir: lsp.DocumentURI(uri.FromPath(dirPath))},
+	})
+}
+
+func (n *Notifier) isSessionClosed() bool {
 	select {
 	case <-n.sessCtx.Done():
-		n.closeDiagsOnce.Do(func() {
-			close(n.diags)
-		})
-		return
+		n.queueMu.Lock()
+		n.closed = true
+		n.queued = make(map[queueKey]diagContext)
+		n.queue = nil
+		n.queueMu.Unlock()
+		return true
 	default:
 	}
+	return false
+}
+
+func (n *Notifier) publish(ctx context.Context, dirPath string, diags map[string][]lsp.Diagnostic, source string) {
+	if ctx.Err() != nil {
+		// diagnostics were computed by a cancelled (stale) run
+		return
+	}
 
 	for filename, ds := range diags {
-		n.diags <- diagContext{
+		docURI := lsp.DocumentURI(uri.FromPath(filepath.Join(dirPath, filename)))
+		if isDocumentExcluded(ctx, docURI) {
+			// diagnostics of the document were cleared
+			// since the run computing these was scheduled
+			continue
+		}
+		n.enqueue(diagContext{
 			ctx: ctx, source: source,
-			diags: ilsp.HCLDiagsToLSP(ds, source),
-			uri:   lsp.DocumentURI(uri.FromPath(filepath.Join(dirPath, filename))),
+			diags:   ds,
+			uri:     docURI,
+			version: documentVersion(ctx, docURI),
+		})
+	}
+}
+
+func (n *Notifier) enqueue(d diagContext) {
+	if n.isSessionClosed() {
+		return
+	}
+
+	key := queueKey{uri: d.uri, source: d.source}
+	if d.clear != nil {
+		key.clear = *d.clear
+	}
+
+	n.queueMu.Lock()
+	if n.closed {
+		n.queueMu.Unlock()
+		return
+	}
+	if queued, ok := n.queued[key]; ok && isOlderVersion(d.version, queued.version) {
+		n.queueMu.Unlock()
+		return
+	} else if ok {
+		// move replaced entry to the end, so that it is
+		// not sent ahead of clearing queued after it
+		n.removeFromQueue(key)
+	}
+	n.queue = append(n.queue, key)
+	n.queued[key] = d
+	n.queueMu.Unlock()
+
+	select {
+	case n.queueReady <- struct{}{}:
+	default:
+	}
+}
+
+func (n *Notifier) removeFromQueue(key queueKey) {
+	for i, k := range n.queue {
+		if k == key {
+			n.queue = append(n.queue[:i], n.queue[i+1:]...)
+			return
 		}
 	}
 }
 
+// dequeueAll returns all queued diagnostics in the order they were queued
+func (n *Notifier) dequeueAll() []diagContext {
+	n.queueMu.Lock()
+	defer n.queueMu.Unlock()
+
+	diags := make([]diagContext, 0, len(n.queue))
+	for _, key := range n.queue {
+		diags = append(diags, n.queued[key])
+	}
+	n.queue = nil
+	n.queued = make(map[queueKey]diagContext)
+
+	return diags
+}
+
 func (n *Notifier) notify() {
-	for d := range n.diags {
-		if err := jrpc2.PushNotify(d.ctx, "textDocument/publishDiagnostics", lsp.PublishDiagnosticsParams{
-			URI:         d.uri,
-			Diagnostics: n.mergeDiags(d.uri, d.source, d.diags),
-		}); err != nil {
-			n.logger.Printf("Error pushing diagnostics: %s", err)
+	for {
+		select {
+		case <-n.sessCtx.Done():
+			n.isSessionClosed()
+			return
+		case <-n.queueReady:
+			if ctx := n.sendQueued(); ctx != nil && n.shouldRefresh() {
+				n.refresh(ctx)
+			}
+		}
+	}
+}
+
+// sendQueued sends all queued diagnostics and returns
+// context of the last diagnostics which changed, if any
+func (n *Notifier) sendQueued() context.Context {
+	n.cacheMu.Lock()
+	defer n.cacheMu.Unlock()
+
+	var changedCtx context.Context
+	for _, d := range n.dequeueAll() {
+		if n.send(d) {
+			changedCtx = d.ctx
+		}
+	}
+	return changedCtx
+}
+
+// send sends the diagnostics and returns true
+// if diagnostics of any file changed as a result
+func (n *Notifier) send(d diagContext) bool {
+	if d.clear != nil {
+		return n.clear(d.ctx, *d.clear)
+	}
+	if n.isStale(d) {
+		n.logger.Printf("discarding diagnostics of %s for old version %d", d.uri, d.version)
+		return false
+	}
+	if n.isUnchanged(d.uri, d.source, d.diags) {
+		// avoid republishing diagnostics of files
+		// which were not affected by the latest change
+		return false
+	}
+	n.pushDiags(d.ctx, d.uri, d.version, n.mergeDiags(d.uri, d.source, d.diags))
+	return true
+}
+
+// pushDiags sends the given diagnostics of a file to the client,
+// unless the client pulls them, and assigns them a new result ID
+func (n *Notifier) pushDiags(ctx context.Context, docURI lsp.DocumentURI, version int, diags []lsp.Diagnostic) {
+	n.lastResultID++
+	n.resultIDs[docURI] = n.resultIDPrefix + "-" + strconv.FormatUint(n.lastResultID, 10)
+
+	if n.pullMode {
+		return
+	}
+	if err := jrpc2.PushNotify(ctx, "textDocument/publishDiagnostics", lsp.PublishDiagnosticsParams{
+		URI:         docURI,
+		Version:     float64(version),
+		Diagnostics: diags,
+	}); err != nil {
+		n.logger.Printf("Error pushing diagnostics: %s", err)
+	}
+}
+
+func (n *Notifier) shouldRefresh() bool {
+	n.cacheMu.Lock()
+	defer n.cacheMu.Unlock()
+
+	return n.pullMode && n.refreshSupport
+}
+
+// refresh asks the client to pull diagnostics again.
+// The request is sent in the background, as the client
+// may pull diagnostics before responding to it.
+func (n *Notifier) refresh(ctx context.Context) {
+	go func() {
+		_, err := jrpc2.PushCall(detachedContext{ctx}, "workspace/diagnostic/refresh", nil)
+		if err != nil {
+			n.logger.Printf("Error refreshing diagnostics: %s", err)
+		}
+	}()
+}
+
+// isStale returns true if the diagnostics were computed
+// for an older version of the document than the current one
+func (n *Notifier) isStale(d diagContext) bool {
+	if d.version == 0 || n.versionFunc == nil {
+		return false
+	}
+	current, ok := n.versionFunc(d.uri)
+	if !ok {
+		return false
+	}
+	return isOlderVersion(d.version, current)
+}
+
+func isOlderVersion(version, otherVersion int) bool {
+	return version != 0 && otherVersion != 0 && version < otherVersion
+}
+
+// clear removes the requested diagnostics from the cache, publishes
+// whatever remains for each affected file and returns true if any
+// file was affected
+func (n *Notifier) clear(ctx context.Context, req clearRequest) bool {
+	uris := make([]lsp.DocumentURI, 0)
+	switch {
+	case req.uri != "":
+		if n.uncacheURI(req.uri) {
+			uris = append(uris, req.uri)
+		}
+	case req.source != "":
+		uris = n.uncacheSource(req.dir, req.source)
+	default:
+		uris = n.uncacheDir(req.dir)
+	}
+
+	for _, docURI := range uris {
+		n.pushDiags(ctx, docURI, 0, n.cachedDiags(docURI))
+	}
+	return len(uris) > 0
+}
+
+// uncacheURI removes cached diagnostics of the given file
+// and returns true if there were any
+func (n *Notifier) uncacheURI(docURI lsp.DocumentURI) bool {
+	if _, ok := n.diagsCache[docURI]; !ok {
+		return false
+	}
+	delete(n.diagsCache, docURI)
+	return true
+}
+
+// uncacheSource removes cached diagnostics of the given source for all
+// files directly within the given directory and returns URIs of these files
+func (n *Notifier) uncacheSource(dirURI lsp.DocumentURI, source string) []lsp.DocumentURI {
+	uris := make([]lsp.DocumentURI, 0)
+	for _, docURI := range n.cachedURIsInDir(dirURI) {
+		fileDiags := n.diagsCache[docURI]
+		if _, ok := fileDiags[diagnosticSource(source)]; !ok {
+			continue
+		}
+
+		delete(fileDiags, diagnosticSource(source))
+		if len(fileDiags) == 0 {
+			delete(n.diagsCache, docURI)
+		}
+		uris = append(uris, docURI)
+	}
+	return uris
+}
+
+// uncacheDir removes cached diagnostics of all files directly
+// within the given directory and returns URIs of these files
+func (n *Notifier) uncacheDir(dirURI lsp.DocumentURI) []lsp.DocumentURI {
+	uris := n.cachedURIsInDir(dirURI)
+	for _, docURI := range uris {
+		delete(n.diagsCache, docURI)
+	}
+	return uris
+}
+
+func (n *Notifier) cachedURIsInDir(dirURI lsp.DocumentURI) []lsp.DocumentURI {
+	uris := make([]lsp.DocumentURI, 0)
+	for docURI := range n.diagsCache {
+		if isDirectlyWithin(docURI, dirURI) {
+			uris = append(uris, docURI)
 		}
 	}
+	return uris
+}
+
+func isDirectlyWithin(docURI, dirURI lsp.DocumentURI) bool {
+	prefix := strings.TrimSuffix(string(dirURI), "/") + "/"
+	name := strings.TrimPrefix(string(docURI), prefix)
+	return name != string(docURI) && !strings.Contains(name, "/")
+}
+
+// cachedDiags returns all cached diagnostics of the given file
+func (n *Notifier) cachedDiags(docURI lsp.DocumentURI) []lsp.Diagnostic {
+	all := []lsp.Diagnostic{}
+	for _, diags := range n.diagsCache[docURI] {
+		all = append(all, diags...)
+	}
+	return all
+}
+
+// isUnchanged returns true if the given diagnostics
+// match the ones last published for the same uri and source
+func (n *Notifier) isUnchanged(uri lsp.DocumentURI, source string, diags []lsp.Diagnostic) bool {
+	fileDiags, ok := n.diagsCache[uri]
+	if !ok {
+		return false
+	}
+	cachedDiags, ok := fileDiags[diagnosticSource(source)]
+	if !ok {
+		return false
+	}
+	if len(cachedDiags) == 0 && len(diags) == 0 {
+		return true
+	}
+	return reflect.DeepEqual(cachedDiags, diags)
 }
 
 // mergeDiags will return all diags from all cached sources for a given uri.
@@ -91,9 +562,5 @@ func (n *Notifier) mergeDiags(uri lsp.DocumentURI, source string, diags []lsp.Di
 	fileDiags[diagnosticSource(source)] = diags
 	n.diagsCache[uri] = fileDiags
 
-	all := []lsp.Diagnostic{}
-	for _, diags := range fileDiags {
-		all = append(all, diags...)
-	}
-	return all
+	return n.cachedDiags(uri)
 }
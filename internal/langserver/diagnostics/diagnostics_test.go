@@ -4,6 +4,7 @@ import (
 	"context"
 	"io/ioutil"
 	"log"
+	"sync"
 	"testing"
 
 	"github.com/hashicorp/hcl/v2"
@@ -25,8 +26,13 @@ func TestDiags_Closes(t *testing.T) {
 		},
 	}, "test")
 
-	if _, open := <-n.diags; open {
-		t.Fatal("channel should be closed")
+	n.queueMu.Lock()
+	defer n.queueMu.Unlock()
+	if !n.closed {
+		t.Fatal("notifier should be closed")
+	}
+	if len(n.queue) > 0 {
+		t.Fatal("no diagnostics should be queued")
 	}
 }
 
@@ -119,3 +125,272 @@ func TestMergeDiags_OverwritesSource_EvenWithEmptySlice(t *testing.T) {
 		t.Fatalf("returns diags is incorrect length: expected %d, got %d", 1, len(all))
 	}
 }
+
+func TestUncacheDir_RemovesOnlyFilesWithinDir(t *testing.T) {
+	n := NewNotifier(context.Background(), discardLogger)
+
+	uris := []lsp.DocumentURI{
+		"file:///test/main.tf",
+		"file:///test/variables.tf",
+		"file:///test/nested/main.tf",
+		"file:///test-other/main.tf",
+	}
+	for _, uri := range uris {
+		n.mergeDiags(uri, "source1", []lsp.Diagnostic{
+			{
+				Severity: lsp.SeverityError,
+				Message:  "diag1",
+			},
+		})
+	}
+
+	cleared := n.uncacheDir("file:///test")
+	if len(cleared) != 2 {
+		t.Fatalf("expected 2 URIs to be cleared, got %d: %q", len(cleared), cleared)
+	}
+
+	for _, uri := range uris[:2] {
+		if _, ok := n.diagsCache[uri]; ok {
+			t.Fatalf("expected diags for %q to be cleared", uri)
+		}
+	}
+	for _, uri := range uris[2:] {
+		if _, ok := n.diagsCache[uri]; !ok {
+			t.Fatalf("expected diags for %q to be kept", uri)
+		}
+	}
+}
+
+func TestUncacheSource_KeepsOtherSources(t *testing.T) {
+	n := NewNotifier(context.Background(), discardLogger)
+
+	mainURI := lsp.DocumentURI("file:///test/main.tf")
+	varsURI := lsp.DocumentURI("file:///test/variables.tf")
+	otherURI := lsp.DocumentURI("file:///test-other/main.tf")
+	diags := []lsp.Diagnostic{
+		{
+			Severity: lsp.SeverityError,
+			Message:  "diag1",
+		},
+	}
+	n.mergeDiags(mainURI, "source1", diags)
+	n.mergeDiags(mainURI, "source2", diags)
+	n.mergeDiags(varsURI, "source1", diags)
+	n.mergeDiags(otherURI, "source1", diags)
+
+	cleared := n.uncacheSource("file:///test", "source1")
+	if len(cleared) != 2 {
+		t.Fatalf("expected 2 URIs to be cleared, got %d: %q", len(cleared), cleared)
+	}
+
+	if all := n.cachedDiags(mainURI); len(all) != 1 {
+		t.Fatalf("expected diags of other source to be kept, got %d", len(all))
+	}
+	if _, ok := n.diagsCache[varsURI]; ok {
+		t.Fatalf("expected file without any remaining diags to be uncached")
+	}
+	if _, ok := n.diagsCache[otherURI]; !ok {
+		t.Fatalf("expected diags outside of dir to be kept")
+	}
+}
+
+func TestEnqueue_ClearingFollowsEarlierDiags(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	// notifier without the goroutine sending queued diagnostics
+	n := &Notifier{
+		logger:     discardLogger,
+		sessCtx:    ctx,
+		diagsCache: make(map[lsp.DocumentURI]fileDiagnostics),
+		queued:     make(map[queueKey]diagContext),
+		queueMu:    &sync.Mutex{},
+		queueReady: make(chan struct{}, 1),
+	}
+
+	uri := lsp.DocumentURI("file:///test/main.tf")
+	n.enqueue(diagContext{ctx: ctx, uri: uri, source: "HCL", version: 1})
+	n.ClearDiagsForURI(ctx, uri)
+	n.enqueue(diagContext{ctx: ctx, uri: uri, source: "HCL", version: 2})
+
+	queued := n.dequeueAll()
+	if len(queued) != 2 {
+		t.Fatalf("expected 2 queued entries, given %d", len(queued))
+	}
+	if queued[0].clear == nil {
+		t.Fatal("expected clearing to be sent first")
+	}
+	if queued[1].version != 2 {
+		t.Fatalf("expected diags queued after clearing to be sent last, given version %d", queued[1].version)
+	}
+}
+
+func TestPublish_SkipsExcludedDocuments(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	// notifier without the goroutine sending queued diagnostics
+	n := &Notifier{
+		logger:     discardLogger,
+		sessCtx:    ctx,
+		diagsCache: make(map[lsp.DocumentURI]fileDiagnostics),
+		queued:     make(map[queueKey]diagContext),
+		queueMu:    &sync.Mutex{},
+		queueReady: make(chan struct{}, 1),
+	}
+
+	excluded := newDocumentSet()
+	excluded.Add("file:///test/closed.tf")
+	n.PublishDiags(withExcludedDocuments(ctx, excluded), "/test", map[string][]lsp.Diagnostic{
+		"closed.tf": {},
+		"main.tf":   {},
+	}, "HCL")
+
+	queued := n.dequeueAll()
+	if len(queued) != 1 {
+		t.Fatalf("expected 1 queued entry, given %d", len(queued))
+	}
+	if queued[0].uri != "file:///test/main.tf" {
+		t.Fatalf("expected diagnostics of other documents to be queued, given %q", queued[0].uri)
+	}
+}
+
+func TestIsUnchanged_ComparesWithCachedSource(t *testing.T) {
+	uri := lsp.DocumentURI("test.tf")
+	diags := []lsp.Diagnostic{
+		{
+			Severity: lsp.SeverityError,
+			Message:  "diag1",
+		},
+	}
+
+	n := NewNotifier(context.Background(), discardLogger)
+	if n.isUnchanged(uri, "source1", diags) {
+		t.Fatal("expected diags never published to be reported as changed")
+	}
+
+	n.mergeDiags(uri, "source1", diags)
+	if !n.isUnchanged(uri, "source1", diags) {
+		t.Fatal("expected identical diags to be reported as unchanged")
+	}
+	if n.isUnchanged(uri, "source2", diags) {
+		t.Fatal("expected diags of another source to be reported as changed")
+	}
+	if n.isUnchanged(uri, "source1", []lsp.Diagnostic{}) {
+		t.Fatal("expected cleared diags to be reported as changed")
+	}
+
+	n.mergeDiags(uri, "source1", []lsp.Diagnostic{})
+	if !n.isUnchanged(uri, "source1", nil) {
+		t.Fatal("expected empty diags to be reported as unchanged")
+	}
+}
+
+func TestEnqueue_KeepsNewerVersion(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	// notifier without the goroutine sending queued diagnostics
+	n := &Notifier{
+		logger:     discardLogger,
+		sessCtx:    ctx,
+		diagsCache: make(map[lsp.DocumentURI]fileDiagnostics),
+		queued:     make(map[queueKey]diagContext),
+		queueMu:    &sync.Mutex{},
+		queueReady: make(chan struct{}, 1),
+	}
+
+	uri := lsp.DocumentURI("file:///test/main.tf")
+	n.enqueue(diagContext{ctx: ctx, uri: uri, source: "HCL", version: 2})
+	n.enqueue(diagContext{ctx: ctx, uri: uri, source: "HCL", version: 1})
+	n.enqueue(diagContext{ctx: ctx, uri: uri, source: "early validation", version: 1})
+
+	queued := n.dequeueAll()
+	if len(queued) != 2 {
+		t.Fatalf("expected 2 queued diagnostics, given %d", len(queued))
+	}
+	if queued[0].version != 2 {
+		t.Fatalf("expected newer version to be kept, given %d", queued[0].version)
+	}
+}
+
+func TestIsStale(t *testing.T) {
+	n := NewNotifier(context.Background(), discardLogger)
+	n.SetDocumentVersionFunc(func(uri lsp.DocumentURI) (int, bool) {
+		if uri == "file:///test/main.tf" {
+			return 3, true
+		}
+		return 0, false
+	})
+
+	testCases := []struct {
+		uri           lsp.DocumentURI
+		version       int
+		expectedStale bool
+	}{
+		{"file:///test/main.tf", 2, true},
+		{"file:///test/main.tf", 3, false},
+		{"file:///test/main.tf", 0, false},
+		{"file:///test/closed.tf", 2, false},
+	}
+	for _, tc := range testCases {
+		stale := n.isStale(diagContext{uri: tc.uri, version: tc.version})
+		if stale != tc.expectedStale {
+			t.Fatalf("%s (version %d): expected stale: %t, given: %t",
+				tc.uri, tc.version, tc.expectedStale, stale)
+		}
+	}
+}
+
+func TestReport_PullMode(t *testing.T) {
+	n := NewNotifier(context.Background(), discardLogger)
+	// diagnostics are never pushed in pull mode, so a context
+	// without any server to push them through is enough
+	n.SetPullMode(false)
+
+	docURI := lsp.DocumentURI("file:///test/main.tf")
+	diags := map[string][]lsp.Diagnostic{
+		"main.tf": {
+			{
+				Severity: lsp.SeverityError,
+				Message:  "diag1",
+			},
+		},
+	}
+
+	if _, ok := n.Report(docURI); ok {
+		t.Fatal("expected no report for file without any diagnostics published")
+	}
+
+	n.PublishDiags(context.Background(), "/test", diags, "source1")
+	first, ok := n.Report(docURI)
+	if !ok {
+		t.Fatal("expected report of published diagnostics")
+	}
+	if len(first.Diagnostics) != 1 || first.ResultID == "" {
+		t.Fatalf("unexpected report: %#v", first)
+	}
+
+	n.PublishDiags(context.Background(), "/test", diags, "source1")
+	unchanged, _ := n.Report(docURI)
+	if unchanged.ResultID != first.ResultID {
+		t.Fatalf("expected result ID of unchanged diags to be kept: %q != %q",
+			unchanged.ResultID, first.ResultID)
+	}
+
+	n.PublishDiags(context.Background(), "/test", diags, "source2")
+	changed, _ := n.Report(docURI)
+	if len(changed.Diagnostics) != 2 || changed.ResultID == first.ResultID {
+		t.Fatalf("expected merged diags with new result ID, given: %#v", changed)
+	}
+
+	n.ClearDiagsForDir(context.Background(), "/test")
+	cleared, ok := n.Report(docURI)
+	if !ok {
+		t.Fatal("expected report of cleared diagnostics")
+	}
+	if len(cleared.Diagnostics) != 0 || cleared.ResultID == changed.ResultID {
+		t.Fatalf("expected no diags with new result ID, given: %#v", cleared)
+	}
+
+	if reports := n.Reports(); len(reports) != 1 {
+		t.Fatalf("expected 1 report, given %d", len(reports))
+	}
+}
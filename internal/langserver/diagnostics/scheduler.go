@@ -0,0 +1,322 @@
+package diagnostics
+
+import (
+	"context"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	lsp "github.com/hashicorp/terraform-ls/internal/protocol"
+	"github.com/hashicorp/terraform-ls/internal/uri"
+)
+
+// DiagnosticsFunc computes and publishes diagnostics. It is expected
+// to stop early (without publishing) once the context is cancelled.
+type DiagnosticsFunc func(ctx context.Context)
+
+// Scheduler runs computation of diagnostics for documents in the background.
+// Diagnostics are computed for the whole module (directory) of a document,
+// so requests for any documents of the same module within the delay
+// are coalesced into a single run and any run in progress is cancelled
+// by a newer request, such that only diagnostics for the latest
+// versions of all documents of the module get published.
+type Scheduler struct {
+	logger  *log.Logger
+	sessCtx context.Context
+	delay   time.Duration
+
+	// runs are keyed by URI of the directory of their documents
+	runs   map[lsp.DocumentURI]*scheduledRun
+	runsMu *sync.Mutex
+}
+
+type scheduledRun struct {
+	// versions of documents whose changes the run covers
+	versions map[lsp.DocumentURI]int
+	// excluded holds documents whose diagnostics
+	// were cleared since the run was scheduled
+	excluded *documentSet
+	timer    *time.Timer
+	cancel   context.CancelFunc
+
+	// done is closed once the run finished,
+	// or once it was stopped before it started
+	done chan struct{}
+	// prevDone is closed once the previous run
+	// for the same module finished (if any)
+	prevDone <-chan struct{}
+}
+
+const defaultDelay = 200 * time.Millisecond
+
+func NewScheduler(sessCtx context.Context, logger *log.Logger) *Scheduler {
+	s := &Scheduler{
+		logger:  logger,
+		sessCtx: sessCtx,
+		delay:   defaultDelay,
+		runs:    make(map[lsp.DocumentURI]*scheduledRun, 0),
+		runsMu:  &sync.Mutex{},
+	}
+	go func() {
+		<-sessCtx.Done()
+		s.cancelAll()
+	}()
+	return s
+}
+
+// SetDelay sets how long to wait for further requests
+// for the same module before diagnostics are computed
+func (s *Scheduler) SetDelay(delay time.Duration) {
+	s.delay = delay
+}
+
+// Schedule schedules f to compute diagnostics of the module of the document
+// for the given version of the document (or 0 if the version is unknown),
+// superseding any run scheduled for the same module, unless that run
+// is for a newer version of the document. Versions of other documents
+// covered by the superseded run are carried over to the new run.
+//
+// Values of ctx are available to f, but f is not cancelled
+// along with ctx, since ctx typically belongs to a request
+// which is finished before diagnostics are computed.
+func (s *Scheduler) Schedule(ctx context.Context, docURI lsp.DocumentURI, version int, f DiagnosticsFunc) {
+	s.runsMu.Lock()
+	defer s.runsMu.Unlock()
+
+	if s.sessCtx.Err() != nil {
+		return
+	}
+
+	dirURI := documentDir(docURI)
+	versions := map[lsp.DocumentURI]int{docURI: version}
+	excluded := newDocumentSet()
+
+	var prevDone <-chan struct{}
+	if prev, ok := s.runs[dirURI]; ok {
+		if isOlderVersion(version, prev.versions[docURI]) {
+			s.logger.Printf("ignoring diagnostics of %s for old version %d", docURI, version)
+			return
+		}
+		for uri, v := range prev.versions {
+			if uri != docURI && !prev.excluded.Has(uri) {
+				versions[uri] = v
+			}
+		}
+		for _, uri := range prev.excluded.List() {
+			if uri != docURI {
+				excluded.Add(uri)
+			}
+		}
+		prev.cancel()
+		if prev.timer.Stop() {
+			// previous run never started, so we only
+			// need to wait for whatever it waited for
+			prevDone = prev.prevDone
+			close(prev.done)
+		} else {
+			prevDone = prev.done
+		}
+	}
+
+	runCtx := withDocumentVersions(detachedContext{ctx}, versions)
+	runCtx, cancel := context.WithCancel(withExcludedDocuments(runCtx, excluded))
+	run := &scheduledRun{
+		versions: versions,
+		excluded: excluded,
+		cancel:   cancel,
+		done:     make(chan struct{}),
+		prevDone: prevDone,
+	}
+	run.timer = time.AfterFunc(s.delay, func() {
+		defer close(run.done)
+		defer cancel()
+
+		// runs for the same module never overlap, so that a stale
+		// run can't publish after a newer one, incl. diagnostics
+		// of other documents than the one it was scheduled for
+		if prevDone != nil {
+			<-prevDone
+		}
+		if runCtx.Err() == nil {
+			f(runCtx)
+		}
+
+		s.runsMu.Lock()
+		if s.runs[dirURI] == run {
+			delete(s.runs, dirURI)
+		}
+		s.runsMu.Unlock()
+	})
+	s.runs[dirURI] = run
+}
+
+// Wait blocks until no run is scheduled or in progress for the module
+// of the given document, such that all its diagnostics are published,
+// or until ctx is cancelled
+func (s *Scheduler) Wait(ctx context.Context, docURI lsp.DocumentURI) error {
+	dirURI := documentDir(docURI)
+	for {
+		s.runsMu.Lock()
+		run, ok := s.runs[dirURI]
+		s.runsMu.Unlock()
+		if !ok {
+			return nil
+		}
+
+		// the run may be superseded by a newer one
+		// while we wait, so we check again after
+		select {
+		case <-run.done:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// Cancel cancels any run scheduled for the module of the given
+// document, which stops diagnostics of all documents of the module
+// from being published. Use Exclude to stop publishing of diagnostics
+// of a single document.
+func (s *Scheduler) Cancel(docURI lsp.DocumentURI) {
+	s.runsMu.Lock()
+	defer s.runsMu.Unlock()
+
+	dirURI := documentDir(docURI)
+	if run, ok := s.runs[dirURI]; ok {
+		s.cancelRun(dirURI, run)
+	}
+}
+
+// Exclude stops any run scheduled or in progress for the module
+// of the given document from publishing diagnostics of the document,
+// e.g. before its diagnostics are cleared, while diagnostics
+// of other documents of the module are still published
+func (s *Scheduler) Exclude(docURI lsp.DocumentURI) {
+	s.runsMu.Lock()
+	defer s.runsMu.Unlock()
+
+	if run, ok := s.runs[documentDir(docURI)]; ok {
+		run.excluded.Add(docURI)
+	}
+}
+
+// CancelDir cancels any run scheduled for documents
+// directly within the given directory
+func (s *Scheduler) CancelDir(dirPath string) {
+	s.runsMu.Lock()
+	defer s.runsMu.Unlock()
+
+	dirURI := lsp.DocumentURI(strings.TrimSuffix(uri.FromPath(dirPath), "/"))
+	if run, ok := s.runs[dirURI]; ok {
+		s.cancelRun(dirURI, run)
+	}
+}
+
+// cancelRun cancels the run, which stops it from publishing
+// any diagnostics, even if already in progress
+func (s *Scheduler) cancelRun(dirURI lsp.DocumentURI, run *scheduledRun) {
+	if run.timer.Stop() {
+		close(run.done)
+	}
+	run.cancel()
+	delete(s.runs, dirURI)
+}
+
+func (s *Scheduler) cancelAll() {
+	s.runsMu.Lock()
+	defer s.runsMu.Unlock()
+
+	for dirURI, run := range s.runs {
+		s.cancelRun(dirURI, run)
+	}
+}
+
+// documentDir returns URI of the directory of the given document
+func documentDir(docURI lsp.DocumentURI) lsp.DocumentURI {
+	i := strings.LastIndex(string(docURI), "/")
+	if i < 0 {
+		return docURI
+	}
+	return docURI[:i]
+}
+
+// detachedContext carries values of the parent context,
+// but is never cancelled along with it
+type detachedContext struct {
+	parent context.Context
+}
+
+func (detachedContext) Deadline() (time.Time, bool) { return time.Time{}, false }
+func (detachedContext) Done() <-chan struct{}       { return nil }
+func (detachedContext) Err() error                  { return nil }
+func (c detachedContext) Value(key interface{}) interface{} {
+	return c.parent.Value(key)
+}
+
+type documentVersionsKey struct{}
+
+func withDocumentVersions(ctx context.Context, versions map[lsp.DocumentURI]int) context.Context {
+	return context.WithValue(ctx, documentVersionsKey{}, versions)
+}
+
+// documentVersion returns version of the given document
+// which diagnostics in the context are computed for, if known
+func documentVersion(ctx context.Context, docURI lsp.DocumentURI) int {
+	versions, ok := ctx.Value(documentVersionsKey{}).(map[lsp.DocumentURI]int)
+	if !ok {
+		return 0
+	}
+	return versions[docURI]
+}
+
+type excludedDocumentsKey struct{}
+
+func withExcludedDocuments(ctx context.Context, excluded *documentSet) context.Context {
+	return context.WithValue(ctx, excludedDocumentsKey{}, excluded)
+}
+
+// isDocumentExcluded returns true if diagnostics of the given
+// document must not be published from the given context
+func isDocumentExcluded(ctx context.Context, docURI lsp.DocumentURI) bool {
+	excluded, ok := ctx.Value(excludedDocumentsKey{}).(*documentSet)
+	if !ok {
+		return false
+	}
+	return excluded.Has(docURI)
+}
+
+// documentSet is a set of documents safe for concurrent use
+type documentSet struct {
+	uris map[lsp.DocumentURI]bool
+	mu   *sync.RWMutex
+}
+
+func newDocumentSet() *documentSet {
+	return &documentSet{
+		uris: make(map[lsp.DocumentURI]bool, 0),
+		mu:   &sync.RWMutex{},
+	}
+}
+
+func (s *documentSet) Add(docURI lsp.DocumentURI) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.uris[docURI] = true
+}
+
+func (s *documentSet) Has(docURI lsp.DocumentURI) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.uris[docURI]
+}
+
+func (s *documentSet) List() []lsp.DocumentURI {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	uris := make([]lsp.DocumentURI, 0, len(s.uris))
+	for docURI := range s.uris {
+		uris = append(uris, docURI)
+	}
+	return uris
+}
@@ -0,0 +1,249 @@
+package diagnostics
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	lsp "github.com/hashicorp/terraform-ls/internal/protocol"
+)
+
+func TestScheduler_debounce(t *testing.T) {
+	s := NewScheduler(context.Background(), discardLogger)
+	s.SetDelay(50 * time.Millisecond)
+
+	docURI := lsp.DocumentURI("file:///test/main.tf")
+	runs := make(chan int, 10)
+	for v := 1; v <= 3; v++ {
+		s.Schedule(context.Background(), docURI, v, func(ctx context.Context) {
+			runs <- documentVersion(ctx, docURI)
+		})
+	}
+
+	expectRuns(t, runs, []int{3})
+}
+
+func TestScheduler_cancelsStaleRun(t *testing.T) {
+	s := NewScheduler(context.Background(), discardLogger)
+	s.SetDelay(10 * time.Millisecond)
+
+	docURI := lsp.DocumentURI("file:///test/main.tf")
+	runs := make(chan int, 10)
+
+	started := make(chan struct{})
+	s.Schedule(context.Background(), docURI, 1, func(ctx context.Context) {
+		close(started)
+		<-ctx.Done()
+		runs <- 1
+	})
+	<-started
+
+	s.Schedule(context.Background(), docURI, 2, func(ctx context.Context) {
+		runs <- 2
+	})
+
+	// the stale run has to finish before the newer one starts
+	expectRuns(t, runs, []int{1, 2})
+}
+
+func TestScheduler_ignoresOlderVersion(t *testing.T) {
+	s := NewScheduler(context.Background(), discardLogger)
+	s.SetDelay(50 * time.Millisecond)
+
+	docURI := lsp.DocumentURI("file:///test/main.tf")
+	runs := make(chan int, 10)
+	s.Schedule(context.Background(), docURI, 5, func(ctx context.Context) {
+		runs <- 5
+	})
+	s.Schedule(context.Background(), docURI, 4, func(ctx context.Context) {
+		runs <- 4
+	})
+
+	expectRuns(t, runs, []int{5})
+}
+
+func TestScheduler_cancelDir(t *testing.T) {
+	s := NewScheduler(context.Background(), discardLogger)
+	s.SetDelay(50 * time.Millisecond)
+
+	runs := make(chan int, 10)
+	s.Schedule(context.Background(), "file:///test/main.tf", 1, func(ctx context.Context) {
+		runs <- 1
+	})
+	s.Schedule(context.Background(), "file:///test/nested/main.tf", 2, func(ctx context.Context) {
+		runs <- 2
+	})
+	s.Schedule(context.Background(), "file:///test-other/main.tf", 2, func(ctx context.Context) {
+		runs <- 2
+	})
+	s.CancelDir("/test")
+
+	// only runs outside of the directory remain
+	expectRuns(t, runs, []int{2, 2})
+}
+
+func TestScheduler_exclude(t *testing.T) {
+	s := NewScheduler(context.Background(), discardLogger)
+	s.SetDelay(50 * time.Millisecond)
+
+	closedURI := lsp.DocumentURI("file:///test/closed.tf")
+	mainURI := lsp.DocumentURI("file:///test/main.tf")
+	otherURI := lsp.DocumentURI("file:///test/other.tf")
+
+	type excludedDocs struct {
+		closed, main bool
+	}
+	runs := make(chan excludedDocs, 10)
+	f := func(ctx context.Context) {
+		runs <- excludedDocs{
+			closed: isDocumentExcluded(ctx, closedURI),
+			main:   isDocumentExcluded(ctx, mainURI),
+		}
+	}
+
+	s.Schedule(context.Background(), closedURI, 1, f)
+	s.Exclude(closedURI)
+	// exclusion is carried over to a run superseding the scheduled one
+	s.Schedule(context.Background(), mainURI, 1, f)
+
+	select {
+	case r := <-runs:
+		if !r.closed || r.main {
+			t.Fatalf("expected only the closed document to be excluded, given %#v", r)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected run of the module to remain scheduled")
+	}
+
+	// exclusion ends once the document itself is scheduled again
+	s.Schedule(context.Background(), otherURI, 1, f)
+	s.Exclude(closedURI)
+	s.Schedule(context.Background(), closedURI, 2, f)
+
+	select {
+	case r := <-runs:
+		if r.closed {
+			t.Fatal("expected document scheduled again not to be excluded")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected run of the module to remain scheduled")
+	}
+}
+
+func TestScheduler_outlivesRequestContext(t *testing.T) {
+	s := NewScheduler(context.Background(), discardLogger)
+	s.SetDelay(10 * time.Millisecond)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	docURI := lsp.DocumentURI("file:///test/main.tf")
+	runs := make(chan int, 10)
+	s.Schedule(ctx, docURI, 1, func(ctx context.Context) {
+		runs <- 1
+	})
+	cancel()
+
+	expectRuns(t, runs, []int{1})
+}
+
+func TestScheduler_modulesAreIndependent(t *testing.T) {
+	s := NewScheduler(context.Background(), discardLogger)
+	s.SetDelay(10 * time.Millisecond)
+
+	var mu sync.Mutex
+	ran := make(map[lsp.DocumentURI]bool, 0)
+	var wg sync.WaitGroup
+	for _, docURI := range []lsp.DocumentURI{"file:///test/main.tf", "file:///test/nested/main.tf"} {
+		docURI := docURI
+		wg.Add(1)
+		s.Schedule(context.Background(), docURI, 1, func(ctx context.Context) {
+			mu.Lock()
+			ran[docURI] = true
+			mu.Unlock()
+			wg.Done()
+		})
+	}
+	wg.Wait()
+
+	if len(ran) != 2 {
+		t.Fatalf("expected diagnostics of both modules, given: %#v", ran)
+	}
+}
+
+func TestScheduler_documentsOfModuleAreSerialized(t *testing.T) {
+	s := NewScheduler(context.Background(), discardLogger)
+	s.SetDelay(10 * time.Millisecond)
+
+	mainURI := lsp.DocumentURI("file:///test/main.tf")
+	varsURI := lsp.DocumentURI("file:///test/variables.tf")
+	runs := make(chan int, 10)
+
+	started := make(chan struct{})
+	s.Schedule(context.Background(), mainURI, 1, func(ctx context.Context) {
+		close(started)
+		<-ctx.Done()
+		runs <- 1
+	})
+	<-started
+
+	// a newer run for another document of the same module
+	// supersedes the stale run and covers both documents
+	s.Schedule(context.Background(), varsURI, 2, func(ctx context.Context) {
+		runs <- documentVersion(ctx, mainURI)*10 + documentVersion(ctx, varsURI)
+	})
+
+	expectRuns(t, runs, []int{1, 12})
+}
+
+func expectRuns(t *testing.T, runs <-chan int, expected []int) {
+	t.Helper()
+	for _, version := range expected {
+		select {
+		case v := <-runs:
+			if v != version {
+				t.Fatalf("expected run for version %d, given %d", version, v)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("timed out waiting for run for version %d", version)
+		}
+	}
+
+	select {
+	case v := <-runs:
+		t.Fatalf("unexpected run for version %d", v)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func TestScheduler_wait(t *testing.T) {
+	s := NewScheduler(context.Background(), discardLogger)
+	s.SetDelay(10 * time.Millisecond)
+
+	docURI := lsp.DocumentURI("file:///test/main.tf")
+	runs := make(chan int, 10)
+	for v := 1; v <= 2; v++ {
+		v := v
+		s.Schedule(context.Background(), docURI, v, func(ctx context.Context) {
+			runs <- v
+		})
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	err := s.Wait(ctx, docURI)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(runs) != 1 {
+		t.Fatalf("expected the latest run to be finished, given %d runs", len(runs))
+	}
+
+	s.Schedule(context.Background(), docURI, 3, func(ctx context.Context) {
+		runs <- 3
+	})
+	s.Cancel(docURI)
+	err = s.Wait(ctx, docURI)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,85 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/hashicorp/hcl/v2"
+	lsctx "github.com/hashicorp/terraform-ls/internal/context"
+	ilsp "github.com/hashicorp/terraform-ls/internal/lsp"
+	lsp "github.com/hashicorp/terraform-ls/internal/protocol"
+	"github.com/hashicorp/terraform-ls/internal/terraform/validation"
+)
+
+func (h *logHandler) TextDocumentCodeAction(ctx context.Context, params lsp.CodeActionParams) ([]ilsp.CodeAction, error) {
+	actions := make([]ilsp.CodeAction, 0)
+
+	fs, err := lsctx.DocumentStorage(ctx)
+	if err != nil {
+		return actions, err
+	}
+
+	mf, err := lsctx.ModuleFinder(ctx)
+	if err != nil {
+		return actions, err
+	}
+
+	fh := ilsp.FileHandlerFromDocumentURI(params.TextDocument.URI)
+	file, err := fs.GetDocument(fh)
+	if err != nil {
+		return actions, err
+	}
+
+	mod, err := mf.ModuleByPath(file.Dir())
+	if err != nil {
+		return actions, err
+	}
+
+	constraints := validation.UnsatisfiedVersionConstraints(mod.ParsedFiles(),
+		mod.TerraformVersion(), mod.ProviderVersions())
+	for _, c := range constraints {
+		if c.Range.Filename != file.Filename() {
+			continue
+		}
+		rng := ilsp.HCLRangeToLSP(c.Range)
+		if !ilsp.RangesOverlap(rng, params.Range) {
+			continue
+		}
+
+		diag := c.Diagnostic()
+		details := validation.DiagnosticDetails{
+			diag: {Code: c.Code()},
+		}
+		diags := ilsp.DetailedHCLDiagsToLSP(hcl.Diagnostics{diag}, mod.Path(),
+			versionConstraintsSource, diagnosticDetails(details))
+		actions = append(actions,
+			versionConstraintAction(params.TextDocument.URI, rng, diags,
+				fmt.Sprintf("Relax version constraint to %q", c.RelaxedConstraint()),
+				c.RelaxedConstraint()),
+			versionConstraintAction(params.TextDocument.URI, rng, diags,
+				fmt.Sprintf("Pin version to %q", c.PinnedConstraint()),
+				c.PinnedConstraint()),
+		)
+	}
+
+	return actions, nil
+}
+
+func versionConstraintAction(uri lsp.DocumentURI, rng lsp.Range, diags []lsp.Diagnostic, title, constraint string) ilsp.CodeAction {
+	return ilsp.CodeAction{
+		Title:       title,
+		Kind:        lsp.QuickFix,
+		Diagnostics: diags,
+		Edit: lsp.WorkspaceEdit{
+			Changes: map[string][]lsp.TextEdit{
+				string(uri): {
+					{
+						Range:   rng,
+						NewText: strconv.Quote(constraint),
+					},
+				},
+			},
+		},
+	}
+}
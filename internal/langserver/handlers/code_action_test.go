@@ -0,0 +1,65 @@
+package handlers
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform-ls/internal/langserver"
+	"github.com/hashicorp/terraform-ls/internal/terraform/module"
+)
+
+func TestLangServer_codeAction_satisfiedConstraint(t *testing.T) {
+	tmpDir := TempDir(t)
+	InitPluginCache(t, tmpDir.Dir())
+
+	ls := langserver.NewLangServerMock(t, NewMockSession(&MockSessionInput{
+		Modules: map[string]*module.ModuleMock{
+			tmpDir.Dir(): {
+				TfExecFactory: validTfMockCalls(),
+			},
+		},
+	}))
+	stop := ls.Start(t)
+	defer stop()
+
+	ls.Call(t, &langserver.CallRequest{
+		Method: "initialize",
+		ReqParams: fmt.Sprintf(`{
+	    "capabilities": {},
+	    "rootUri": %q,
+	    "processId": 12345
+	}`, tmpDir.URI())})
+	ls.Notify(t, &langserver.CallRequest{
+		Method:    "initialized",
+		ReqParams: "{}",
+	})
+	ls.Call(t, &langserver.CallRequest{
+		Method: "textDocument/didOpen",
+		ReqParams: fmt.Sprintf(`{
+		"textDocument": {
+			"version": 0,
+			"languageId": "terraform",
+			"text": "terraform {\n  required_version = \"~> 0.12.0\"\n}\n",
+			"uri": "%s/main.tf"
+		}
+	}`, tmpDir.URI())})
+
+	ls.CallAndExpectResponse(t, &langserver.CallRequest{
+		Method: "textDocument/codeAction",
+		ReqParams: fmt.Sprintf(`{
+			"textDocument": {
+				"uri": "%s/main.tf"
+			},
+			"range": {
+				"start": { "line": 1, "character": 21 },
+				"end": { "line": 1, "character": 32 }
+			},
+			"context": {
+				"diagnostics": []
+			}
+		}`, tmpDir.URI())}, `{
+			"jsonrpc": "2.0",
+			"id": 3,
+			"result": []
+		}`)
+}
@@ -12,6 +12,8 @@ import (
 	lsp "github.com/hashicorp/terraform-ls/internal/protocol"
 )
 
+const validateSource = "terraform validate"
+
 func TerraformValidateHandler(ctx context.Context, args cmd.CommandArgs) (interface{}, error) {
 	dirUri, ok := args.GetString("uri")
 	if !ok || dirUri == "" {
@@ -48,11 +50,26 @@ func TerraformValidateHandler(ctx context.Context, args cmd.CommandArgs) (interf
 		progress.End(ctx, "Finished")
 	}()
 	progress.Report(ctx, "Running terraform validate ...")
-	hclDiags, err := mod.ExecuteTerraformValidate(ctx)
+	validateDiags, err := mod.ExecuteTerraformValidate(ctx)
 	if err != nil {
 		return nil, err
 	}
-	diags.PublishHCLDiags(ctx, mod.Path(), hclDiags, "terraform validate")
+
+	lspDiags := make(map[string][]lsp.Diagnostic, len(validateDiags))
+	for filename, fileDiags := range validateDiags {
+		lspDiags[filename] = make([]lsp.Diagnostic, 0, len(fileDiags))
+		for _, d := range fileDiags {
+			// summaries belong to Terraform and may change between
+			// versions, so no codes are guessed from them
+			lspDiags[filename] = append(lspDiags[filename],
+				ilsp.TFJSONDiagToLSP(d, mod.Path(), validateSource, ilsp.DiagnosticDetails{}))
+		}
+	}
+
+	// output only covers files currently in the module, so results
+	// of any previous run need clearing for files removed since
+	diags.ClearDiagsForSource(ctx, mod.Path(), validateSource)
+	diags.PublishDiags(ctx, mod.Path(), lspDiags, validateSource)
 
 	return nil, nil
 }
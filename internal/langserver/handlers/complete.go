@@ -3,9 +3,12 @@ package handlers
 import (
 	"context"
 
+	"github.com/hashicorp/hcl-lang/lang"
 	lsctx "github.com/hashicorp/terraform-ls/internal/context"
 	ilsp "github.com/hashicorp/terraform-ls/internal/lsp"
 	lsp "github.com/hashicorp/terraform-ls/internal/protocol"
+	"github.com/hashicorp/terraform-ls/internal/terraform/references"
+	"github.com/hashicorp/terraform-ls/internal/terraform/snippets"
 )
 
 func (h *logHandler) TextDocumentComplete(ctx context.Context, params lsp.CompletionParams) (lsp.CompletionList, error) {
@@ -51,8 +54,46 @@ func (h *logHandler) TextDocumentComplete(ctx context.Context, params lsp.Comple
 		return list, err
 	}
 
+	text, err := file.Text()
+	if err != nil {
+		return list, err
+	}
+	refMod := &references.Module{
+		Files:    module.ParsedFiles(),
+		Children: module.ParseModuleCalls(),
+		Schema:   schema,
+	}
+	refCandidates, hasRefs := refMod.CandidatesAtPos(file.Filename(), text, fPos.Position())
+	h.logger.Printf("received reference candidates: %#v", refCandidates)
+
 	h.logger.Printf("Looking for candidates at %q -> %#v", file.Filename(), fPos.Position())
 	candidates, err := d.CandidatesAtPos(file.Filename(), fPos.Position())
 	h.logger.Printf("received candidates: %#v", candidates)
+	if err == nil && cc.TextDocument.Completion.CompletionItem.SnippetSupport {
+		opts, _ := lsctx.CompletionOptions(ctx)
+		candidates = snippets.BlockBodyCandidates(refMod.Files[file.Filename()], fPos.Position(),
+			schema, candidates, opts.IncludeOptionalAttributes)
+	}
+
+	if hasRefs {
+		if err != nil {
+			// the decoder may not support completion within
+			// the expression, but references can still be completed
+			h.logger.Printf("unable to find other candidates: %s", err)
+			return ilsp.ToCompletionList(refCandidates, cc.TextDocument), nil
+		}
+		candidates = mergeCandidates(refCandidates, candidates)
+	}
+
 	return ilsp.ToCompletionList(candidates, cc.TextDocument), err
 }
+
+// mergeCandidates returns reference candidates followed by other
+// candidates at the same position, such as keywords or object keys
+func mergeCandidates(refCandidates, candidates lang.Candidates) lang.Candidates {
+	merged := lang.NewCandidates()
+	merged.List = append(merged.List, refCandidates.List...)
+	merged.List = append(merged.List, candidates.List...)
+	merged.IsComplete = refCandidates.IsComplete && candidates.IsComplete
+	return merged
+}
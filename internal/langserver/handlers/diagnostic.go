@@ -0,0 +1,145 @@
+package handlers
+
+import (
+	"context"
+	"path/filepath"
+
+	lsctx "github.com/hashicorp/terraform-ls/internal/context"
+	"github.com/hashicorp/terraform-ls/internal/langserver/diagnostics"
+	ilsp "github.com/hashicorp/terraform-ls/internal/lsp"
+	lsp "github.com/hashicorp/terraform-ls/internal/protocol"
+	"github.com/hashicorp/terraform-ls/internal/terraform/module"
+	"github.com/hashicorp/terraform-ls/internal/uri"
+)
+
+// TextDocumentDiagnostic returns diagnostics of the given document
+// for clients which pull diagnostics, rather than having them pushed.
+// Diagnostics of the document's module are computed first
+// if they weren't computed yet, e.g. as the document isn't open.
+func TextDocumentDiagnostic(ctx context.Context, params ilsp.DocumentDiagnosticParams) (interface{}, error) {
+	diags, err := lsctx.Diagnostics(ctx)
+	if err != nil {
+		return nil, err
+	}
+	scheduler, err := lsctx.DiagnosticsScheduler(ctx)
+	if err != nil {
+		return nil, err
+	}
+	modMgr, err := lsctx.ModuleManager(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// wait for diagnostics of the latest change to be computed
+	err = scheduler.Wait(ctx, params.TextDocument.URI)
+	if err != nil {
+		return nil, err
+	}
+
+	// diagnostics are cached under URIs built from paths
+	fh := ilsp.FileHandlerFromDocumentURI(params.TextDocument.URI)
+	docURI := lsp.DocumentURI(uri.FromPath(fh.FullPath()))
+
+	report, ok := diags.Report(docURI)
+	if !ok {
+		mod, err := modMgr.ModuleByPath(fh.Dir())
+		if err != nil {
+			if module.IsModuleNotFound(err) {
+				return ilsp.NewFullDocumentDiagnosticReport("", nil), nil
+			}
+			return nil, err
+		}
+		err = computeModuleDiags(ctx, modMgr, mod, diags)
+		if err != nil {
+			return nil, err
+		}
+		report, _ = diags.Report(docURI)
+	}
+
+	if params.PreviousResultID != "" && params.PreviousResultID == report.ResultID {
+		return ilsp.NewUnchangedDocumentDiagnosticReport(report.ResultID), nil
+	}
+	return ilsp.NewFullDocumentDiagnosticReport(report.ResultID, report.Diagnostics), nil
+}
+
+// WorkspaceDiagnostic returns diagnostics of all files of all known
+// modules, incl. files which aren't open, for clients which pull
+// diagnostics. Diagnostics which didn't change since the result IDs
+// previously reported to the client are reported as unchanged.
+func WorkspaceDiagnostic(ctx context.Context, params ilsp.WorkspaceDiagnosticParams) (ilsp.WorkspaceDiagnosticReport, error) {
+	report := ilsp.WorkspaceDiagnosticReport{
+		Items: make([]interface{}, 0),
+	}
+
+	diags, err := lsctx.Diagnostics(ctx)
+	if err != nil {
+		return report, err
+	}
+	modMgr, err := lsctx.ModuleManager(ctx)
+	if err != nil {
+		return report, err
+	}
+
+	// diagnostics of modules which were computed once are kept
+	// up to date by changes, so we only compute the remaining ones
+	computedDirs := make(map[string]bool, 0)
+	for _, r := range diags.Reports() {
+		computedDirs[ilsp.FileHandlerFromDocumentURI(r.URI).Dir()] = true
+	}
+	for _, mod := range modMgr.ListModules() {
+		if computedDirs[filepath.Clean(mod.Path())] {
+			continue
+		}
+		err = computeModuleDiags(ctx, modMgr, mod, diags)
+		if err != nil {
+			return report, err
+		}
+	}
+
+	previousIDs := make(map[lsp.DocumentURI]string, len(params.PreviousResultIDs))
+	for _, prev := range params.PreviousResultIDs {
+		fh := ilsp.FileHandlerFromDocumentURI(prev.URI)
+		previousIDs[lsp.DocumentURI(uri.FromPath(fh.FullPath()))] = prev.Value
+	}
+
+	for _, r := range diags.Reports() {
+		var version *int
+		if r.Version != 0 {
+			v := r.Version
+			version = &v
+		}
+
+		if prevID, ok := previousIDs[r.URI]; ok && prevID == r.ResultID {
+			report.Items = append(report.Items, ilsp.WorkspaceUnchangedDocumentDiagnosticReport{
+				UnchangedDocumentDiagnosticReport: ilsp.NewUnchangedDocumentDiagnosticReport(r.ResultID),
+				URI:                               r.URI,
+				Version:                           version,
+			})
+			continue
+		}
+		report.Items = append(report.Items, ilsp.WorkspaceFullDocumentDiagnosticReport{
+			FullDocumentDiagnosticReport: ilsp.NewFullDocumentDiagnosticReport(r.ResultID, r.Diagnostics),
+			URI:                          r.URI,
+			Version:                      version,
+		})
+	}
+
+	return report, nil
+}
+
+// computeModuleDiags computes diagnostics of the given module
+// synchronously, parsing its files first if necessary.
+// Diagnostics of any callers are computed along with
+// the callers themselves, so they are left as they are.
+func computeModuleDiags(ctx context.Context, modMgr module.ModuleManager, mod module.Module, diags *diagnostics.Notifier) error {
+	if !mod.IsParsed() {
+		err := mod.ParseFiles()
+		if err != nil {
+			return err
+		}
+	}
+
+	publishOwnModuleDiags(ctx, modMgr, mod, diags)
+
+	return ctx.Err()
+}
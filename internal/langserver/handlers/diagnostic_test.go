@@ -0,0 +1,119 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform-ls/internal/langserver"
+	ilsp "github.com/hashicorp/terraform-ls/internal/lsp"
+	"github.com/hashicorp/terraform-ls/internal/terraform/module"
+)
+
+func TestLangServer_pullDiagnostics(t *testing.T) {
+	tmpDir := TempDir(t)
+	InitPluginCache(t, tmpDir.Dir())
+
+	ls := langserver.NewLangServerMock(t, NewMockSession(&MockSessionInput{
+		Modules: map[string]*module.ModuleMock{
+			tmpDir.Dir(): {
+				TfExecFactory: validTfMockCalls(),
+			},
+		},
+	}))
+	stop := ls.Start(t)
+	defer stop()
+
+	rsp := ls.Call(t, &langserver.CallRequest{
+		Method: "initialize",
+		ReqParams: fmt.Sprintf(`{
+	    "capabilities": {
+	    	"textDocument": {
+	    		"diagnostic": {}
+	    	}
+	    },
+	    "rootUri": %q,
+	    "processId": 12345
+	}`, tmpDir.URI())})
+	var initResult struct {
+		Capabilities struct {
+			DiagnosticProvider *ilsp.DiagnosticOptions `json:"diagnosticProvider"`
+		} `json:"capabilities"`
+	}
+	if err := json.Unmarshal(rsp.Result, &initResult); err != nil {
+		t.Fatal(err)
+	}
+	if initResult.Capabilities.DiagnosticProvider == nil {
+		t.Fatal("expected diagnostic provider to be advertised")
+	}
+
+	ls.Notify(t, &langserver.CallRequest{
+		Method:    "initialized",
+		ReqParams: "{}",
+	})
+	ls.Call(t, &langserver.CallRequest{
+		Method: "textDocument/didOpen",
+		ReqParams: fmt.Sprintf(`{
+		"textDocument": {
+			"version": 0,
+			"languageId": "terraform",
+			"text": "variable \"test\" {\n",
+			"uri": "%s/main.tf"
+		}
+	}`, tmpDir.URI())})
+
+	rsp = ls.Call(t, &langserver.CallRequest{
+		Method: "textDocument/diagnostic",
+		ReqParams: fmt.Sprintf(`{
+			"textDocument": {
+				"uri": "%s/main.tf"
+			}
+		}`, tmpDir.URI())})
+	var report ilsp.FullDocumentDiagnosticReport
+	if err := json.Unmarshal(rsp.Result, &report); err != nil {
+		t.Fatal(err)
+	}
+	if report.Kind != "full" || report.ResultID == "" || len(report.Items) != 1 {
+		t.Fatalf("unexpected report: %#v", report)
+	}
+
+	ls.CallAndExpectResponse(t, &langserver.CallRequest{
+		Method: "textDocument/diagnostic",
+		ReqParams: fmt.Sprintf(`{
+			"textDocument": {
+				"uri": "%s/main.tf"
+			},
+			"previousResultId": %q
+		}`, tmpDir.URI(), report.ResultID)}, fmt.Sprintf(`{
+			"jsonrpc": "2.0",
+			"id": 4,
+			"result": {
+				"kind": "unchanged",
+				"resultId": %q
+			}
+		}`, report.ResultID))
+
+	ls.CallAndExpectResponse(t, &langserver.CallRequest{
+		Method: "workspace/diagnostic",
+		ReqParams: fmt.Sprintf(`{
+			"previousResultIds": [
+				{
+					"uri": "%s/main.tf",
+					"value": %q
+				}
+			]
+		}`, tmpDir.URI(), report.ResultID)}, fmt.Sprintf(`{
+			"jsonrpc": "2.0",
+			"id": 5,
+			"result": {
+				"items": [
+					{
+						"kind": "unchanged",
+						"resultId": %q,
+						"uri": "%s/main.tf",
+						"version": null
+					}
+				]
+			}
+		}`, report.ResultID, tmpDir.URI()))
+}
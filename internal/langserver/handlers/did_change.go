@@ -48,17 +48,17 @@ func TextDocumentDidChange(ctx context.Context, params lsp.DidChangeTextDocument
 		return err
 	}
 
-	mf, err := lsctx.ModuleFinder(ctx)
+	modMgr, err := lsctx.ModuleManager(ctx)
 	if err != nil {
 		return err
 	}
 
-	module, err := mf.ModuleByPath(fh.Dir())
+	module, err := modMgr.ModuleByPath(fh.Dir())
 	if err != nil {
 		return err
 	}
 
-	err = module.ParseFiles()
+	err = module.ParseFile(fh.Filename())
 	if err != nil {
 		return err
 	}
@@ -67,7 +67,13 @@ func TextDocumentDidChange(ctx context.Context, params lsp.DidChangeTextDocument
 	if err != nil {
 		return err
 	}
-	diags.PublishHCLDiags(ctx, module.Path(), module.ParsedDiagnostics(), "HCL")
+	scheduler, err := lsctx.DiagnosticsScheduler(ctx)
+	if err != nil {
+		return err
+	}
+	scheduler.Schedule(ctx, p.TextDocument.URI, int(p.TextDocument.Version), func(ctx context.Context) {
+		publishModuleDiags(ctx, modMgr, module, diags)
+	})
 
 	return nil
 }
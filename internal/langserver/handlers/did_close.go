@@ -6,6 +6,7 @@ import (
 	lsctx "github.com/hashicorp/terraform-ls/internal/context"
 	ilsp "github.com/hashicorp/terraform-ls/internal/lsp"
 	lsp "github.com/hashicorp/terraform-ls/internal/protocol"
+	"github.com/hashicorp/terraform-ls/internal/uri"
 )
 
 func TextDocumentDidClose(ctx context.Context, params lsp.DidCloseTextDocumentParams) error {
@@ -15,5 +16,31 @@ func TextDocumentDidClose(ctx context.Context, params lsp.DidCloseTextDocumentPa
 	}
 
 	fh := ilsp.FileHandlerFromDocumentURI(params.TextDocument.URI)
-	return fs.CloseAndRemoveDocument(fh)
+	err = fs.CloseAndRemoveDocument(fh)
+	if err != nil {
+		return err
+	}
+
+	rootDir, _ := lsctx.RootDirectory(ctx)
+	if rootDir != "" && isPathWithin(fh.FullPath(), rootDir) {
+		// diagnostics of workspace files remain
+		// relevant even when the file is closed
+		return nil
+	}
+
+	diags, err := lsctx.Diagnostics(ctx)
+	if err != nil {
+		return err
+	}
+	scheduler, err := lsctx.DiagnosticsScheduler(ctx)
+	if err != nil {
+		return err
+	}
+
+	// diagnostics are cached under URIs built from paths
+	docURI := lsp.DocumentURI(uri.FromPath(fh.FullPath()))
+	scheduler.Exclude(docURI)
+	diags.ClearDiagsForURI(ctx, docURI)
+
+	return nil
 }
@@ -47,7 +47,7 @@ func (lh *logHandler) TextDocumentDidOpen(ctx context.Context, params lsp.DidOpe
 	if err != nil {
 		if module.IsModuleNotFound(err) {
 			mod, err = modMgr.AddAndStartLoadingModule(ctx, f.Dir())
-			if err != nil {
+			if err != nil && !module.IsModuleAlreadyAdded(err) {
 				return err
 			}
 		} else {
@@ -59,8 +59,7 @@ func (lh *logHandler) TextDocumentDidOpen(ctx context.Context, params lsp.DidOpe
 
 	// We reparse because the file being opened may not match
 	// (originally parsed) content on the disk
-	// TODO: Do this only if we can verify the file differs?
-	err = mod.ParseFiles()
+	err = mod.ParseFile(f.Filename())
 	if err != nil {
 		return fmt.Errorf("failed to parse files: %w", err)
 	}
@@ -69,7 +68,13 @@ func (lh *logHandler) TextDocumentDidOpen(ctx context.Context, params lsp.DidOpe
 	if err != nil {
 		return err
 	}
-	diags.PublishHCLDiags(ctx, mod.Path(), mod.ParsedDiagnostics(), "HCL")
+	scheduler, err := lsctx.DiagnosticsScheduler(ctx)
+	if err != nil {
+		return err
+	}
+	scheduler.Schedule(ctx, params.TextDocument.URI, f.Version(), func(ctx context.Context) {
+		publishModuleDiags(ctx, modMgr, mod, diags)
+	})
 
 	candidates := modMgr.ModuleCandidatesByPath(f.Dir())
 
@@ -0,0 +1,166 @@
+package handlers
+
+import (
+	"context"
+
+	"github.com/hashicorp/hcl/v2"
+	lsctx "github.com/hashicorp/terraform-ls/internal/context"
+	"github.com/hashicorp/terraform-ls/internal/langserver/diagnostics"
+	ilsp "github.com/hashicorp/terraform-ls/internal/lsp"
+	lsp "github.com/hashicorp/terraform-ls/internal/protocol"
+	"github.com/hashicorp/terraform-ls/internal/terraform/module"
+	"github.com/hashicorp/terraform-ls/internal/terraform/validation"
+)
+
+const (
+	earlyValidationSource    = "early validation"
+	unusedDeclarationsSource = "unused declarations"
+	deprecationsSource       = "deprecations"
+	versionConstraintsSource = "version constraints"
+	moduleCallsSource        = "module calls"
+)
+
+// publishModuleDiags publishes all diagnostics of the given (parsed) module,
+// incl. diagnostics of module calls in any modules calling it.
+// Publishing stops early once ctx is cancelled, e.g. by a newer change.
+func publishModuleDiags(ctx context.Context, modMgr module.ModuleManager, mod module.Module, notifier *diagnostics.Notifier) {
+	publishOwnModuleDiags(ctx, modMgr, mod, notifier)
+	if ctx.Err() != nil {
+		return
+	}
+	publishCallerModuleCallDiags(ctx, modMgr, mod.Path(), notifier)
+}
+
+// publishOwnModuleDiags publishes diagnostics of files of the given
+// (parsed) module only, leaving diagnostics of its callers as they are
+func publishOwnModuleDiags(ctx context.Context, modMgr module.ModuleManager, mod module.Module, notifier *diagnostics.Notifier) {
+	publishers := []func(){
+		func() { notifier.PublishHCLDiags(ctx, mod.Path(), mod.ParsedDiagnostics(), "HCL") },
+		func() { publishEarlyValidationDiags(ctx, modMgr, mod, notifier) },
+		func() { publishUnusedDeclarationDiags(ctx, mod, notifier) },
+		func() { publishDeprecationDiags(ctx, modMgr, mod, notifier) },
+		func() { publishVersionConstraintDiags(ctx, mod, notifier) },
+		func() { publishModuleCallDiags(ctx, mod, notifier) },
+	}
+	for _, publish := range publishers {
+		if ctx.Err() != nil {
+			return
+		}
+		publish()
+	}
+}
+
+// publishEarlyValidationDiags validates the parsed files of the given module
+// without Terraform, along with calls to functions removed
+// in the installed version, and publishes the resulting diagnostics.
+func publishEarlyValidationDiags(ctx context.Context, mf module.ModuleFinder, mod module.Module, notifier *diagnostics.Notifier) {
+	// Validation of references doesn't need the schema,
+	// so we still validate what we can if it's unavailable
+	bodySchema, _ := mf.SchemaForPath(mod.Path())
+
+	diags := validation.ValidateModule(mod.ParsedFiles(), bodySchema)
+	diags.Merge(validation.RemovedFunctions(mod.ParsedFiles(), mod.TerraformVersion()))
+
+	notifier.PublishDetailedHCLDiags(ctx, mod.Path(), withoutFilesWithSyntaxErrors(mod, diags.Files), earlyValidationSource,
+		diagnosticDetails(diags.Details))
+}
+
+// publishUnusedDeclarationDiags reports unused variables, locals and data sources
+// of the given module, unless the user opted out of it for that module.
+// Diagnostics are tagged as unnecessary, so clients can render them faded out.
+func publishUnusedDeclarationDiags(ctx context.Context, mod module.Module, notifier *diagnostics.Notifier) {
+	diags := validation.UnusedDeclarations(mod.ParsedFiles())
+
+	opts, _ := lsctx.ValidationOptions(ctx)
+	for _, modPath := range opts.IgnoreUnusedDeclarations {
+		if mod.MatchesPath(modPath) {
+			for filename := range diags.Files {
+				diags.Files[filename] = make(hcl.Diagnostics, 0)
+			}
+			break
+		}
+	}
+
+	notifier.PublishDetailedHCLDiags(ctx, mod.Path(), withoutFilesWithSyntaxErrors(mod, diags.Files), unusedDeclarationsSource,
+		diagnosticDetails(diags.Details, lsp.Unnecessary))
+}
+
+// publishDeprecationDiags reports deprecated resources, attributes, blocks
+// and language constructs used within the given module.
+// Diagnostics are tagged as deprecated, so clients can render them struck through.
+func publishDeprecationDiags(ctx context.Context, mf module.ModuleFinder, mod module.Module, notifier *diagnostics.Notifier) {
+	bodySchema, _ := mf.SchemaForPath(mod.Path())
+
+	diags := validation.Deprecations(mod.ParsedFiles(), bodySchema, mod.TerraformVersion())
+
+	notifier.PublishDetailedHCLDiags(ctx, mod.Path(), withoutFilesWithSyntaxErrors(mod, diags.Files), deprecationsSource,
+		diagnosticDetails(diags.Details, lsp.Deprecated))
+}
+
+// publishVersionConstraintDiags reports required_version and required_providers
+// constraints of the given module which are not satisfied by installed versions.
+func publishVersionConstraintDiags(ctx context.Context, mod module.Module, notifier *diagnostics.Notifier) {
+	diags := validation.VersionConstraints(mod.ParsedFiles(), mod.TerraformVersion(), mod.ProviderVersions())
+
+	notifier.PublishDetailedHCLDiags(ctx, mod.Path(), withoutFilesWithSyntaxErrors(mod, diags.Files), versionConstraintsSource,
+		diagnosticDetails(diags.Details))
+}
+
+// publishModuleCallDiags validates module calls of the given module
+// against variables and outputs declared in the called modules.
+func publishModuleCallDiags(ctx context.Context, mod module.Module, notifier *diagnostics.Notifier) {
+	diags := validation.ModuleCalls(mod.ParsedFiles(), mod.ParseModuleCalls())
+
+	notifier.PublishDetailedHCLDiags(ctx, mod.Path(), withoutFilesWithSyntaxErrors(mod, diags.Files), moduleCallsSource,
+		diagnosticDetails(diags.Details))
+}
+
+// withoutFilesWithSyntaxErrors blanks out diagnostics of any files
+// of the given module with syntax errors, to avoid false positives
+// while the user is typing
+func withoutFilesWithSyntaxErrors(mod module.Module, diags map[string]hcl.Diagnostics) map[string]hcl.Diagnostics {
+	for filename, pDiags := range mod.ParsedDiagnostics() {
+		if pDiags.HasErrors() {
+			diags[filename] = make(hcl.Diagnostics, 0)
+		}
+	}
+	return diags
+}
+
+// diagnosticDetails returns details of diagnostics produced by validation,
+// i.e. their codes, links to documentation and related information,
+// along with the given tags
+func diagnosticDetails(details validation.DiagnosticDetails, tags ...lsp.DiagnosticTag) ilsp.DiagnosticDetailsFunc {
+	return func(diag *hcl.Diagnostic) ilsp.DiagnosticDetails {
+		d := details[diag]
+		dd := ilsp.DiagnosticDetails{
+			Code:            string(d.Code),
+			CodeDescription: d.Code.DocsURL(),
+			Tags:            tags,
+		}
+
+		if d.OriginalDeclaration != nil {
+			dd.Related = append(dd.Related, ilsp.RelatedInformation{
+				Range:   *d.OriginalDeclaration,
+				Message: "Originally declared here",
+			})
+		}
+
+		return dd
+	}
+}
+
+// publishCallerModuleCallDiags revalidates module calls of all modules
+// which call the module in the given directory, e.g. after its
+// variables or outputs have changed.
+func publishCallerModuleCallDiags(ctx context.Context, modMgr module.ModuleManager, dir string, notifier *diagnostics.Notifier) {
+	for _, mod := range modMgr.CallersOfModule(dir) {
+		if ctx.Err() != nil {
+			return
+		}
+		if !mod.IsParsed() {
+			continue
+		}
+		publishModuleCallDiags(ctx, mod, notifier)
+	}
+}
@@ -138,6 +138,16 @@ func TestLangServer_workspaceExecuteCommand_modules_multiple(t *testing.T) {
 			prod.Dir(): {
 				TfExecFactory: validTfMockCalls(),
 			},
+			// uninitialized modules are discovered too
+			filepath.Join(testData, "main-module-multienv", "main"): {
+				TfExecFactory: validTfMockCalls(),
+			},
+			filepath.Join(testData, "main-module-multienv", "modules", "application"): {
+				TfExecFactory: validTfMockCalls(),
+			},
+			filepath.Join(testData, "main-module-multienv", "modules", "database"): {
+				TfExecFactory: validTfMockCalls(),
+			},
 		},
 	}))
 	stop := ls.Start(t)
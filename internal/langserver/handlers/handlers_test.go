@@ -37,6 +37,9 @@ func initializeResponse(t *testing.T, commandPrefix string) string {
 				"hoverProvider": true,
 				"signatureHelpProvider": {},
 				"documentSymbolProvider": true,
+				"codeActionProvider": {
+					"codeActionKinds": ["quickfix"]
+				},
 				"codeLensProvider": {},
 				"documentLinkProvider": {},
 				"documentFormattingProvider": true,
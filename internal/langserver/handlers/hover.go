@@ -6,6 +6,7 @@ import (
 	lsctx "github.com/hashicorp/terraform-ls/internal/context"
 	ilsp "github.com/hashicorp/terraform-ls/internal/lsp"
 	lsp "github.com/hashicorp/terraform-ls/internal/protocol"
+	"github.com/hashicorp/terraform-ls/internal/terraform/references"
 )
 
 func (h *logHandler) TextDocumentHover(ctx context.Context, params lsp.TextDocumentPositionParams) (*lsp.Hover, error) {
@@ -49,6 +50,17 @@ func (h *logHandler) TextDocumentHover(ctx context.Context, params lsp.TextDocum
 		return nil, err
 	}
 
+	refMod := &references.Module{
+		Files:         mod.ParsedFiles(),
+		Children:      mod.ParseModuleCalls(),
+		VariableFiles: mod.ParseVariableFiles(),
+		Schema:        schema,
+	}
+	if hoverData, ok := refMod.HoverAtPos(file.Filename(), fPos.Position()); ok {
+		h.logger.Printf("received reference hover data: %#v", hoverData)
+		return ilsp.HoverData(hoverData, cc.TextDocument), nil
+	}
+
 	h.logger.Printf("Looking for hover data at %q -> %#v", file.Filename(), fPos.Position())
 	hoverData, err := d.HoverAtPos(file.Filename(), fPos.Position())
 	h.logger.Printf("received hover data: %#v", hoverData)
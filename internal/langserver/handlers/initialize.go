@@ -11,22 +11,28 @@ import (
 	ilsp "github.com/hashicorp/terraform-ls/internal/lsp"
 	lsp "github.com/hashicorp/terraform-ls/internal/protocol"
 	"github.com/hashicorp/terraform-ls/internal/settings"
+	"github.com/hashicorp/terraform-ls/internal/terraform/module"
 	"github.com/mitchellh/go-homedir"
 )
 
-func (lh *logHandler) Initialize(ctx context.Context, params lsp.InitializeParams) (lsp.InitializeResult, error) {
-	serverCaps := lsp.InitializeResult{
-		Capabilities: lsp.ServerCapabilities{
-			TextDocumentSync: lsp.TextDocumentSyncOptions{
-				OpenClose: true,
-				Change:    lsp.Incremental,
+func (lh *logHandler) Initialize(ctx context.Context, params ilsp.InitializeParams) (ilsp.InitializeResult, error) {
+	serverCaps := ilsp.InitializeResult{
+		Capabilities: ilsp.ServerCapabilities{
+			ServerCapabilities: lsp.ServerCapabilities{
+				TextDocumentSync: lsp.TextDocumentSyncOptions{
+					OpenClose: true,
+					Change:    lsp.Incremental,
+				},
+				CompletionProvider: lsp.CompletionOptions{
+					ResolveProvider: false,
+				},
+				HoverProvider:              true,
+				DocumentFormattingProvider: true,
+				DocumentSymbolProvider:     true,
+				CodeActionProvider: lsp.CodeActionOptions{
+					CodeActionKinds: []lsp.CodeActionKind{lsp.QuickFix},
+				},
 			},
-			CompletionProvider: lsp.CompletionOptions{
-				ResolveProvider: false,
-			},
-			HoverProvider:              true,
-			DocumentFormattingProvider: true,
-			DocumentSymbolProvider:     true,
 		},
 	}
 
@@ -108,6 +114,40 @@ func (lh *logHandler) Initialize(ctx context.Context, params lsp.InitializeParam
 	// set experimental feature flags
 	lsctx.SetExperimentalFeatures(ctx, out.Options.ExperimentalFeatures)
 
+	validationOpts := out.Options.Validation
+	ignoreUnusedPaths := make([]string, 0)
+	for _, rawPath := range validationOpts.IgnoreUnusedDeclarations {
+		modPath, err := resolvePath(rootDir, rawPath)
+		if err != nil {
+			lh.logger.Printf("Ignoring module path for unused declarations %s: %s", rawPath, err)
+			continue
+		}
+		ignoreUnusedPaths = append(ignoreUnusedPaths, modPath)
+	}
+	validationOpts.IgnoreUnusedDeclarations = ignoreUnusedPaths
+	lsctx.SetValidationOptions(ctx, validationOpts)
+
+	lsctx.SetCompletionOptions(ctx, out.Options.Completion)
+
+	schemaPaths := make([]string, 0)
+	for _, rawPath := range out.Options.ProviderSchemaPaths {
+		schemaPath, err := resolvePath(rootDir, rawPath)
+		if err != nil {
+			lh.logger.Printf("Ignoring provider schema path %s: %s", rawPath, err)
+			continue
+		}
+		schemaPaths = append(schemaPaths, schemaPath)
+	}
+	if len(schemaPaths) > 0 {
+		err = modMgr.LoadProviderSchemas(schemaPaths)
+		if err != nil {
+			jrpc2.PushNotify(ctx, "window/showMessage", &lsp.ShowMessageParams{
+				Type:    lsp.Warning,
+				Message: fmt.Sprintf("Failed to load some provider schemas: %s", err),
+			})
+		}
+	}
+
 	if len(out.UnusedKeys) > 0 {
 		jrpc2.PushNotify(ctx, "window/showMessage", &lsp.ShowMessageParams{
 			Type:    lsp.Warning,
@@ -116,6 +156,43 @@ func (lh *logHandler) Initialize(ctx context.Context, params lsp.InitializeParam
 	}
 	cfgOpts := out.Options
 
+	fs, err := lsctx.DocumentStorage(ctx)
+	if err != nil {
+		return serverCaps, err
+	}
+	diags, err := lsctx.Diagnostics(ctx)
+	if err != nil {
+		return serverCaps, err
+	}
+	diagsScheduler, err := lsctx.DiagnosticsScheduler(ctx)
+	if err != nil {
+		return serverCaps, err
+	}
+
+	// Clients which support pull diagnostics pull them,
+	// while others still have them pushed
+	if params.Diagnostic != nil {
+		diags.SetPullMode(params.WorkspaceDiagnostics.RefreshSupport)
+		serverCaps.Capabilities.DiagnosticProvider = &ilsp.DiagnosticOptions{
+			InterFileDependencies: true,
+			WorkspaceDiagnostics:  true,
+		}
+	}
+	md := &moduleDiscovery{
+		logger:         lh.logger,
+		fs:             fs,
+		modMgr:         modMgr,
+		watcher:        w,
+		diags:          diags,
+		diagsScheduler: diagsScheduler,
+		notifyCtx:      ctx,
+	}
+
+	dw, err := lsctx.DirWatcher(ctx)
+	if err != nil {
+		return serverCaps, err
+	}
+
 	// Static user-provided paths take precedence over dynamic discovery
 	if len(cfgOpts.ModulePaths) > 0 {
 		lh.logger.Printf("Attempting to add %d static module paths", len(cfgOpts.ModulePaths))
@@ -130,6 +207,9 @@ func (lh *logHandler) Initialize(ctx context.Context, params lsp.InitializeParam
 			}
 			mod, err := addAndLoadModule(modPath)
 			if err != nil {
+				if module.IsModuleAlreadyAdded(err) {
+					continue
+				}
 				return serverCaps, err
 			}
 
@@ -137,9 +217,16 @@ func (lh *logHandler) Initialize(ctx context.Context, params lsp.InitializeParam
 			lh.logger.Printf("Adding %d module paths for watching (%s)", len(paths), modPath)
 			err = w.AddPaths(paths)
 			if err != nil {
-				return serverCaps, err
+				lh.logger.Printf("Unable to watch all paths of module %s: %s", modPath, err)
+			}
+
+			// configuration may also be changed outside of the editor
+			err = dw.AddDir(modPath)
+			if err != nil {
+				lh.logger.Printf("Unable to watch %s: %s", modPath, err)
 			}
 		}
+		dw.AddDirHook(md.handleChangeEvent)
 
 		return serverCaps, nil
 	}
@@ -161,6 +248,19 @@ func (lh *logHandler) Initialize(ctx context.Context, params lsp.InitializeParam
 
 	walker.SetLogger(lh.logger)
 	walker.SetExcludeModulePaths(excludeModulePaths)
+	walker.SetIgnorePatterns(cfgOpts.IgnorePaths)
+
+	// Modules may be added or removed after the initial walk,
+	// so all walked directories are watched for changes
+	walker.SetDirFunc(func(dir string) {
+		err := dw.AddDir(dir)
+		if err != nil {
+			lh.logger.Printf("Unable to watch %s: %s", dir, err)
+		}
+	})
+	md.walker = walker
+	dw.AddDirHook(md.handleDirEvent)
+
 	// Walker runs asynchronously so we're intentionally *not*
 	// passing the request context here
 	bCtx := context.Background()
@@ -168,6 +268,11 @@ func (lh *logHandler) Initialize(ctx context.Context, params lsp.InitializeParam
 		lh.logger.Printf("Adding module: %s", dir)
 		mod, err := modMgr.AddAndStartLoadingModule(ctx, dir)
 		if err != nil {
+			if module.IsModuleAlreadyAdded(err) {
+				// e.g. the module was opened before it was walked,
+				// in which case its paths are already watched
+				return nil
+			}
 			return err
 		}
 
@@ -175,7 +280,9 @@ func (lh *logHandler) Initialize(ctx context.Context, params lsp.InitializeParam
 		lh.logger.Printf("Adding %d paths of module for watching (%s)", len(paths), dir)
 		err = w.AddPaths(paths)
 		if err != nil {
-			return err
+			// the walk carries on, as failing to watch e.g. due to
+			// the limit of watches doesn't prevent the module from loading
+			lh.logger.Printf("Unable to watch all paths of module %s: %s", dir, err)
 		}
 
 		return nil
@@ -2,10 +2,59 @@ package handlers
 
 import (
 	"context"
+	"fmt"
 
+	lsctx "github.com/hashicorp/terraform-ls/internal/context"
+	"github.com/hashicorp/terraform-ls/internal/langserver/progress"
 	lsp "github.com/hashicorp/terraform-ls/internal/protocol"
+	"github.com/hashicorp/terraform-ls/internal/terraform/module"
 )
 
-func Initialized(ctx context.Context, params lsp.InitializedParams) error {
+func (lh *logHandler) Initialized(ctx context.Context, params lsp.InitializedParams) error {
+	walker, err := lsctx.ModuleWalker(ctx)
+	if err != nil {
+		return err
+	}
+
+	if !walker.IsWalking() {
+		return nil
+	}
+
+	// The server may only create progress once the client is initialized,
+	// so progress of the walk started during initialize is reported from here
+	go func() {
+		err := reportWalkProgress(ctx, walker)
+		if err != nil {
+			lh.logger.Printf("unable to report progress of module discovery: %s", err)
+		}
+	}()
+
+	return nil
+}
+
+func reportWalkProgress(ctx context.Context, walker *module.Walker) error {
+	ctx, err := initiateProgress(ctx)
+	if err != nil {
+		return err
+	}
+	if _, ok := lsctx.ProgressToken(ctx); !ok {
+		return nil
+	}
+
+	err = progress.Begin(ctx, "Discovering modules")
+	if err != nil {
+		return err
+	}
+
+	rootDir, _ := lsctx.RootDirectory(ctx)
+	walker.SetProgressFunc(func(p module.WalkProgress) {
+		if p.Done {
+			progress.End(ctx, fmt.Sprintf("Found %d modules", p.ModulesFound))
+			return
+		}
+		progress.Report(ctx, fmt.Sprintf("Found %d modules in %d directories (%s)",
+			p.ModulesFound, p.DirsWalked, humanReadablePath(rootDir, p.CurrentDir)))
+	})
+
 	return nil
 }
@@ -0,0 +1,253 @@
+package handlers
+
+import (
+	"context"
+	"log"
+	"path/filepath"
+	"strings"
+
+	"github.com/hashicorp/terraform-ls/internal/filesystem"
+	"github.com/hashicorp/terraform-ls/internal/langserver/diagnostics"
+	ilsp "github.com/hashicorp/terraform-ls/internal/lsp"
+	lsp "github.com/hashicorp/terraform-ls/internal/protocol"
+	"github.com/hashicorp/terraform-ls/internal/terraform/module"
+	"github.com/hashicorp/terraform-ls/internal/uri"
+	"github.com/hashicorp/terraform-ls/internal/watcher"
+)
+
+// moduleDiscovery keeps modules known to the module manager
+// in sync with the workspace after the initial walk, i.e. adds
+// modules which appear, removes those which disappear and reloads
+// configuration changed outside of the editor
+type moduleDiscovery struct {
+	logger  *log.Logger
+	fs      filesystem.DocumentStorage
+	modMgr  module.ModuleManager
+	walker  *module.Walker
+	watcher watcher.Watcher
+	diags   *diagnostics.Notifier
+
+	diagsScheduler *diagnostics.Scheduler
+
+	// notifyCtx is used for publishing diagnostics
+	// as changes are detected outside of any request
+	notifyCtx context.Context
+}
+
+func (md *moduleDiscovery) handleDirEvent(ctx context.Context, event watcher.DirEvent) error {
+	switch event.Kind {
+	case watcher.DirEntryCreated:
+		return md.handleCreated(ctx, event)
+	case watcher.DirEntryRemoved:
+		return md.handleRemoved(ctx, event)
+	case watcher.DirEntryChanged:
+		return md.handleChanged(ctx, event)
+	}
+	return nil
+}
+
+func (md *moduleDiscovery) handleCreated(ctx context.Context, event watcher.DirEvent) error {
+	name := filepath.Base(event.Path)
+	parentDir := filepath.Dir(event.Path)
+
+	if event.IsDir && name != ".terraform" {
+		return md.walker.WalkDir(ctx, event.Path, md.addModule)
+	}
+
+	if md.walker.IsDirIgnored(parentDir) {
+		return nil
+	}
+
+	if event.IsDir {
+		// module got initialized, so caches need to be discovered
+		if md.isKnownModule(parentDir) {
+			return md.reloadModule(ctx, parentDir)
+		}
+		return md.addModule(ctx, parentDir)
+	}
+
+	if !module.IsModuleFile(name) {
+		return nil
+	}
+	if !md.isKnownModule(parentDir) {
+		return md.addModule(ctx, parentDir)
+	}
+
+	// new file of a known module
+	md.modMgr.InvalidateModuleCalls(parentDir)
+	return md.reparseModule(event.Path)
+}
+
+// handleChanged reparses the module of any configuration
+// file which changed on the disk, unless the file is open,
+// in which case the editor's version takes precedence
+func (md *moduleDiscovery) handleChanged(_ context.Context, event watcher.DirEvent) error {
+	if !module.IsModuleFile(filepath.Base(event.Path)) {
+		return nil
+	}
+
+	// module may also be called from other modules
+	md.modMgr.InvalidateModuleCalls(filepath.Dir(event.Path))
+
+	return md.reparseModule(event.Path)
+}
+
+// handleChangeEvent only handles changes of files
+// within watched directories, for modules which
+// are not discovered by walking the workspace
+func (md *moduleDiscovery) handleChangeEvent(ctx context.Context, event watcher.DirEvent) error {
+	if event.Kind != watcher.DirEntryChanged {
+		return nil
+	}
+	return md.handleChanged(ctx, event)
+}
+
+func (md *moduleDiscovery) reparseModule(path string) error {
+	if md.isDocumentOpen(path) {
+		return nil
+	}
+
+	mod, err := md.modMgr.ModuleByPath(filepath.Dir(path))
+	if err != nil {
+		if module.IsModuleNotFound(err) {
+			return nil
+		}
+		return err
+	}
+
+	md.logger.Printf("Reparsing module %s after %s changed on disk", mod.Path(), path)
+	err = mod.ParseFile(filepath.Base(path))
+	if err != nil {
+		return err
+	}
+
+	docURI := lsp.DocumentURI(uri.FromPath(path))
+	md.diagsScheduler.Schedule(md.notifyCtx, docURI, 0, func(ctx context.Context) {
+		publishModuleDiags(ctx, md.modMgr, mod, md.diags)
+	})
+
+	return nil
+}
+
+func (md *moduleDiscovery) handleRemoved(ctx context.Context, event watcher.DirEvent) error {
+	name := filepath.Base(event.Path)
+	parentDir := filepath.Dir(event.Path)
+
+	// modules removed along with the entry may be called from other modules
+	if module.IsModuleFile(name) {
+		md.modMgr.InvalidateModuleCalls(parentDir)
+	} else {
+		md.modMgr.InvalidateModuleCalls(event.Path)
+	}
+
+	if name == ".terraform" {
+		if md.isKnownModule(parentDir) {
+			return md.reloadModule(ctx, parentDir)
+		}
+		return nil
+	}
+
+	if module.IsModuleFile(name) {
+		if !md.isKnownModule(parentDir) {
+			return nil
+		}
+		isModule, err := module.IsModuleDir(parentDir)
+		if err == nil && isModule {
+			md.clearFileDiags(event.Path)
+			return nil
+		}
+		md.removeModule(parentDir)
+		return nil
+	}
+
+	// any directory may have been removed, incl. one containing
+	// modules, which is reported without knowing it was a directory
+	for _, mod := range md.modMgr.ListModules() {
+		if isPathWithin(mod.Path(), event.Path) {
+			md.removeModule(mod.Path())
+		}
+	}
+
+	return nil
+}
+
+func (md *moduleDiscovery) isKnownModule(dir string) bool {
+	_, err := md.modMgr.ModuleByPath(dir)
+	return err == nil
+}
+
+func (md *moduleDiscovery) addModule(ctx context.Context, dir string) error {
+	if md.isKnownModule(dir) {
+		return nil
+	}
+
+	md.logger.Printf("Adding discovered module: %s", dir)
+	mod, err := md.modMgr.AddAndStartLoadingModule(ctx, dir)
+	if err != nil {
+		if module.IsModuleAlreadyAdded(err) {
+			return nil
+		}
+		return err
+	}
+
+	paths := mod.PathsToWatch()
+	md.logger.Printf("Adding %d paths of module for watching (%s)", len(paths), dir)
+	err = md.watcher.AddPaths(paths)
+	if err != nil {
+		// the module is loaded regardless, only changes
+		// to its cache files may go unnoticed
+		md.logger.Printf("Unable to watch all paths of module %s: %s", dir, err)
+	}
+
+	return nil
+}
+
+func (md *moduleDiscovery) reloadModule(ctx context.Context, dir string) error {
+	md.logger.Printf("Reloading module: %s", dir)
+	err := md.modMgr.RemoveModule(dir)
+	if err != nil && !module.IsModuleNotFound(err) {
+		return err
+	}
+	return md.addModule(ctx, dir)
+}
+
+func (md *moduleDiscovery) removeModule(dir string) {
+	err := md.modMgr.RemoveModule(dir)
+	if err != nil {
+		if !module.IsModuleNotFound(err) {
+			md.logger.Printf("Failed to remove module %s: %s", dir, err)
+		}
+		return
+	}
+	md.logger.Printf("Removed module: %s", dir)
+
+	md.diagsScheduler.CancelDir(dir)
+	md.diags.ClearDiagsForDir(md.notifyCtx, dir)
+}
+
+// clearFileDiags clears diagnostics of a file removed from the disk,
+// unless the file is open, in which case they reflect the editor's version
+func (md *moduleDiscovery) clearFileDiags(path string) {
+	if md.isDocumentOpen(path) {
+		return
+	}
+
+	docURI := lsp.DocumentURI(uri.FromPath(path))
+	md.diagsScheduler.Exclude(docURI)
+	md.diags.ClearDiagsForURI(md.notifyCtx, docURI)
+}
+
+func (md *moduleDiscovery) isDocumentOpen(path string) bool {
+	_, err := md.fs.GetDocument(ilsp.FileHandlerFromPath(path))
+	return err == nil
+}
+
+// isPathWithin returns true if path is equal to dir
+// or is located anywhere within dir
+func isPathWithin(path, dir string) bool {
+	path, dir = filepath.Clean(path), filepath.Clean(dir)
+	if path == dir {
+		return true
+	}
+	return strings.HasPrefix(path, dir+string(filepath.Separator))
+}
@@ -0,0 +1,206 @@
+package handlers
+
+import (
+	"context"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/hashicorp/terraform-ls/internal/filesystem"
+	"github.com/hashicorp/terraform-ls/internal/langserver/diagnostics"
+	ilsp "github.com/hashicorp/terraform-ls/internal/lsp"
+	"github.com/hashicorp/terraform-ls/internal/terraform/module"
+	"github.com/hashicorp/terraform-ls/internal/watcher"
+)
+
+func TestModuleDiscovery_handleDirEvent(t *testing.T) {
+	rootDir, err := ioutil.TempDir("", "module-discovery")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(rootDir)
+
+	appDir := filepath.Join(rootDir, "app")
+	dbDir := filepath.Join(rootDir, "db")
+
+	modMgr := module.NewModuleManagerMock(&module.ModuleManagerMockInput{
+		Modules: map[string]*module.ModuleMock{
+			appDir: {TfExecFactory: validTfMockCalls()},
+			dbDir:  {TfExecFactory: validTfMockCalls()},
+		},
+	})(filesystem.NewFilesystem())
+
+	w, _ := watcher.MockWatcher()()
+
+	// diagnostics are not expected to be published without a session
+	sessCtx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	md := &moduleDiscovery{
+		logger:    log.New(ioutil.Discard, "", 0),
+		fs:        filesystem.NewFilesystem(),
+		modMgr:    modMgr,
+		walker:    module.MockWalker(),
+		watcher:   w,
+		diags:     diagnostics.NewNotifier(sessCtx, log.New(ioutil.Discard, "", 0)),
+		notifyCtx: context.Background(),
+
+		diagsScheduler: diagnostics.NewScheduler(sessCtx, log.New(ioutil.Discard, "", 0)),
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// new directory containing module
+	mkdir(t, appDir)
+	writeFile(t, filepath.Join(appDir, "main.tf"))
+	handleDirEvent(t, ctx, md, watcher.DirEvent{
+		Path:  appDir,
+		Kind:  watcher.DirEntryCreated,
+		IsDir: true,
+	})
+	expectModulePaths(t, modMgr, []string{appDir})
+
+	// new empty directory
+	mkdir(t, dbDir)
+	handleDirEvent(t, ctx, md, watcher.DirEvent{
+		Path:  dbDir,
+		Kind:  watcher.DirEntryCreated,
+		IsDir: true,
+	})
+	expectModulePaths(t, modMgr, []string{appDir})
+
+	// new configuration file in the empty directory
+	writeFile(t, filepath.Join(dbDir, "main.tf"))
+	handleDirEvent(t, ctx, md, watcher.DirEvent{
+		Path: filepath.Join(dbDir, "main.tf"),
+		Kind: watcher.DirEntryCreated,
+	})
+	expectModulePaths(t, modMgr, []string{appDir, dbDir})
+
+	// last configuration file removed
+	err = os.Remove(filepath.Join(dbDir, "main.tf"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	handleDirEvent(t, ctx, md, watcher.DirEvent{
+		Path: filepath.Join(dbDir, "main.tf"),
+		Kind: watcher.DirEntryRemoved,
+	})
+	expectModulePaths(t, modMgr, []string{appDir})
+
+	// whole directory removed
+	err = os.RemoveAll(appDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	handleDirEvent(t, ctx, md, watcher.DirEvent{
+		Path: appDir,
+		Kind: watcher.DirEntryRemoved,
+	})
+	expectModulePaths(t, modMgr, []string{})
+}
+
+func TestModuleDiscovery_handleChanged(t *testing.T) {
+	modDir, err := ioutil.TempDir("", "module-reparse")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(modDir)
+
+	mainPath := filepath.Join(modDir, "main.tf")
+	varsPath := filepath.Join(modDir, "variables.tf")
+	writeFileContent(t, mainPath, `variable "first" {}`)
+	writeFileContent(t, varsPath, `variable "second" {}`)
+
+	fs := filesystem.NewFilesystem()
+	modMgr := module.NewModuleManagerMock(&module.ModuleManagerMockInput{
+		Modules: map[string]*module.ModuleMock{
+			modDir: {TfExecFactory: validTfMockCalls()},
+		},
+	})(fs)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	mod, err := modMgr.AddAndStartLoadingModule(ctx, modDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	w, _ := watcher.MockWatcher()()
+	sessCtx, cancelSess := context.WithCancel(context.Background())
+	cancelSess()
+	md := &moduleDiscovery{
+		logger:    log.New(ioutil.Discard, "", 0),
+		fs:        fs,
+		modMgr:    modMgr,
+		watcher:   w,
+		diags:     diagnostics.NewNotifier(sessCtx, log.New(ioutil.Discard, "", 0)),
+		notifyCtx: context.Background(),
+
+		diagsScheduler: diagnostics.NewScheduler(sessCtx, log.New(ioutil.Discard, "", 0)),
+	}
+
+	// file changed outside of the editor
+	writeFileContent(t, mainPath, `variable "first" {`)
+	handleDirEvent(t, ctx, md, watcher.DirEvent{
+		Path: mainPath,
+		Kind: watcher.DirEntryChanged,
+	})
+	if !mod.ParsedDiagnostics()["main.tf"].HasErrors() {
+		t.Fatal("expected changed file to be reparsed")
+	}
+
+	// open file is not replaced with the content on disk
+	err = fs.CreateAndOpenDocument(ilsp.FileHandlerFromPath(varsPath), []byte(`variable "second" {}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	writeFileContent(t, mainPath, `variable "first" {}`)
+	writeFileContent(t, varsPath, `variable "second" {`)
+	handleDirEvent(t, ctx, md, watcher.DirEvent{
+		Path: varsPath,
+		Kind: watcher.DirEntryChanged,
+	})
+	if !mod.ParsedDiagnostics()["main.tf"].HasErrors() {
+		t.Fatal("expected module not to be reparsed after change of an open file")
+	}
+}
+
+func handleDirEvent(t *testing.T, ctx context.Context, md *moduleDiscovery, event watcher.DirEvent) {
+	err := md.handleDirEvent(ctx, event)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func expectModulePaths(t *testing.T, modMgr module.ModuleManager, expectedPaths []string) {
+	paths := make([]string, 0)
+	for _, mod := range modMgr.ListModules() {
+		paths = append(paths, mod.Path())
+	}
+	if diff := cmp.Diff(expectedPaths, paths); diff != "" {
+		t.Fatalf("unexpected modules: %s", diff)
+	}
+}
+
+func mkdir(t *testing.T, dir string) {
+	err := os.Mkdir(dir, 0755)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func writeFile(t *testing.T, path string) {
+	writeFileContent(t, path, "")
+}
+
+func writeFileContent(t *testing.T, path, content string) {
+	err := ioutil.WriteFile(path, []byte(content), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
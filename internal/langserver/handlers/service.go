@@ -15,6 +15,7 @@ import (
 	"github.com/hashicorp/terraform-ls/internal/filesystem"
 	"github.com/hashicorp/terraform-ls/internal/langserver/diagnostics"
 	"github.com/hashicorp/terraform-ls/internal/langserver/session"
+	ilsp "github.com/hashicorp/terraform-ls/internal/lsp"
 	lsp "github.com/hashicorp/terraform-ls/internal/protocol"
 	"github.com/hashicorp/terraform-ls/internal/settings"
 	"github.com/hashicorp/terraform-ls/internal/terraform/module"
@@ -31,10 +32,12 @@ type service struct {
 
 	fs               filesystem.Filesystem
 	watcher          watcher.Watcher
+	dirWatcher       watcher.DirWatcher
 	walker           *module.Walker
 	modMgr           module.ModuleManager
 	newModuleManager module.ModuleManagerFactory
 	newWatcher       watcher.WatcherFactory
+	newDirWatcher    watcher.DirWatcherFactory
 	newWalker        module.WalkerFactory
 }
 
@@ -52,6 +55,7 @@ func NewSession(srvCtx context.Context) session.Session {
 		stopSession:      stopSession,
 		newModuleManager: module.NewModuleManager,
 		newWatcher:       watcher.NewWatcher,
+		newDirWatcher:    watcher.NewDirWatcher,
 		newWalker:        module.NewWalker,
 	}
 }
@@ -104,6 +108,12 @@ func (svc *service) Assigner() (jrpc2.Assigner, error) {
 	if timeout, ok := lsctx.TerraformExecTimeout(svc.srvCtx); ok {
 		svc.modMgr.SetTerraformExecTimeout(timeout)
 	}
+	if paths, ok := lsctx.ProviderSchemaPaths(svc.srvCtx); ok {
+		err := svc.modMgr.LoadProviderSchemas(paths)
+		if err != nil {
+			svc.logger.Printf("failed to load some provider schemas: %s", err)
+		}
+	}
 
 	ww, err := svc.newWatcher()
 	if err != nil {
@@ -111,13 +121,18 @@ func (svc *service) Assigner() (jrpc2.Assigner, error) {
 	}
 	svc.watcher = ww
 	svc.watcher.SetLogger(svc.logger)
-	svc.watcher.AddChangeHook(func(ctx context.Context, file watcher.TrackedFile) error {
+	svc.watcher.AddChangeHook(func(ctx context.Context, file watcher.TrackedFile, kind watcher.ChangeKind) error {
+		if kind == watcher.FileRemoved {
+			// schema is kept until the lock file reappears
+			// or the whole module is reloaded
+			return nil
+		}
 		mod, err := svc.modMgr.ModuleByPath(file.Path())
 		if err != nil {
 			return err
 		}
 		if mod.IsKnownPluginLockFile(file.Path()) {
-			svc.logger.Printf("detected plugin cache change, updating schema ...")
+			svc.logger.Printf("detected plugin cache change (%s), updating schema ...", kind)
 			err := mod.UpdateProviderSchemaCache(ctx, file)
 			if err != nil {
 				svc.logger.Printf(err.Error())
@@ -126,13 +141,16 @@ func (svc *service) Assigner() (jrpc2.Assigner, error) {
 
 		return nil
 	})
-	svc.watcher.AddChangeHook(func(_ context.Context, file watcher.TrackedFile) error {
+	svc.watcher.AddChangeHook(func(_ context.Context, file watcher.TrackedFile, kind watcher.ChangeKind) error {
+		if kind == watcher.FileRemoved {
+			return nil
+		}
 		mod, err := svc.modMgr.ModuleByPath(file.Path())
 		if err != nil {
 			return err
 		}
 		if mod.IsKnownModuleManifestFile(file.Path()) {
-			svc.logger.Printf("detected module manifest change, updating ...")
+			svc.logger.Printf("detected module manifest change (%s), updating ...", kind)
 			err := mod.UpdateModuleManifest(file)
 			if err != nil {
 				svc.logger.Printf(err.Error())
@@ -141,17 +159,54 @@ func (svc *service) Assigner() (jrpc2.Assigner, error) {
 
 		return nil
 	})
+	svc.watcher.AddChangeHook(func(_ context.Context, file watcher.TrackedFile, kind watcher.ChangeKind) error {
+		// .terraform-version may be shared by modules in any subdirectory
+		for _, mod := range svc.modMgr.ListModules() {
+			if !mod.IsKnownTerraformVersionFile(file.Path()) {
+				continue
+			}
+			svc.logger.Printf("detected Terraform version file change (%s), updating core schema of %s ...",
+				kind, mod.Path())
+			err := mod.UpdateCoreSchema()
+			if err != nil {
+				svc.logger.Printf(err.Error())
+			}
+		}
+
+		return nil
+	})
 	err = svc.watcher.Start()
 	if err != nil {
 		return nil, err
 	}
 
+	dw, err := svc.newDirWatcher()
+	if err != nil {
+		return nil, err
+	}
+	svc.dirWatcher = dw
+	svc.dirWatcher.SetLogger(svc.logger)
+	err = svc.dirWatcher.Start()
+	if err != nil {
+		return nil, err
+	}
+
 	modLoader := module.NewModuleLoader(svc.sessCtx, svc.modMgr)
 	diags := diagnostics.NewNotifier(svc.sessCtx, svc.logger)
+	diags.SetDocumentVersionFunc(func(docURI lsp.DocumentURI) (int, bool) {
+		doc, err := svc.fs.GetDocument(ilsp.FileHandlerFromDocumentURI(docURI))
+		if err != nil {
+			return 0, false
+		}
+		return doc.Version(), true
+	})
+	diagsScheduler := diagnostics.NewScheduler(svc.sessCtx, svc.logger)
 
 	rootDir := ""
 	commandPrefix := ""
 	var expFeatures settings.ExperimentalFeatures
+	var validationOpts settings.ValidationOptions
+	var completionOpts settings.CompletionOptions
 
 	m := map[string]rpch.Func{
 		"initialize": func(ctx context.Context, req *jrpc2.Request) (interface{}, error) {
@@ -162,12 +217,17 @@ func (svc *service) Assigner() (jrpc2.Assigner, error) {
 			ctx = lsctx.WithDocumentStorage(ctx, svc.fs)
 			ctx = lsctx.WithClientCapabilitiesSetter(ctx, cc)
 			ctx = lsctx.WithWatcher(ctx, ww)
+			ctx = lsctx.WithDirWatcher(ctx, dw)
+			ctx = lsctx.WithDiagnostics(ctx, diags)
+			ctx = lsctx.WithDiagnosticsScheduler(ctx, diagsScheduler)
 			ctx = lsctx.WithModuleWalker(ctx, svc.walker)
 			ctx = lsctx.WithRootDirectory(ctx, &rootDir)
 			ctx = lsctx.WithCommandPrefix(ctx, &commandPrefix)
 			ctx = lsctx.WithModuleManager(ctx, svc.modMgr)
 			ctx = lsctx.WithModuleLoader(ctx, modLoader)
 			ctx = lsctx.WithExperimentalFeatures(ctx, &expFeatures)
+			ctx = lsctx.WithValidationOptions(ctx, &validationOpts)
+			ctx = lsctx.WithCompletionOptions(ctx, &completionOpts)
 
 			version, ok := lsctx.LanguageServerVersion(svc.srvCtx)
 			if ok {
@@ -181,8 +241,11 @@ func (svc *service) Assigner() (jrpc2.Assigner, error) {
 			if err != nil {
 				return nil, err
 			}
+			ctx = lsctx.WithClientCapabilities(ctx, cc)
+			ctx = lsctx.WithModuleWalker(ctx, svc.walker)
+			ctx = lsctx.WithRootDirectory(ctx, &rootDir)
 
-			return handle(ctx, req, Initialized)
+			return handle(ctx, req, lh.Initialized)
 		},
 		"textDocument/didChange": func(ctx context.Context, req *jrpc2.Request) (interface{}, error) {
 			err := session.CheckInitializationIsConfirmed()
@@ -190,8 +253,11 @@ func (svc *service) Assigner() (jrpc2.Assigner, error) {
 				return nil, err
 			}
 			ctx = lsctx.WithDiagnostics(ctx, diags)
+			ctx = lsctx.WithDiagnosticsScheduler(ctx, diagsScheduler)
 			ctx = lsctx.WithDocumentStorage(ctx, svc.fs)
 			ctx = lsctx.WithModuleFinder(ctx, svc.modMgr)
+			ctx = lsctx.WithModuleManager(ctx, svc.modMgr)
+			ctx = lsctx.WithValidationOptions(ctx, &validationOpts)
 			return handle(ctx, req, TextDocumentDidChange)
 		},
 		"textDocument/didOpen": func(ctx context.Context, req *jrpc2.Request) (interface{}, error) {
@@ -201,12 +267,14 @@ func (svc *service) Assigner() (jrpc2.Assigner, error) {
 			}
 			ctx = lsctx.WithClientCapabilities(ctx, cc)
 			ctx = lsctx.WithDiagnostics(ctx, diags)
+			ctx = lsctx.WithDiagnosticsScheduler(ctx, diagsScheduler)
 			ctx = lsctx.WithDocumentStorage(ctx, svc.fs)
 			ctx = lsctx.WithRootDirectory(ctx, &rootDir)
 			ctx = lsctx.WithModuleManager(ctx, svc.modMgr)
 			ctx = lsctx.WithModuleFinder(ctx, svc.modMgr)
 			ctx = lsctx.WithModuleWalker(ctx, svc.walker)
 			ctx = lsctx.WithWatcher(ctx, ww)
+			ctx = lsctx.WithValidationOptions(ctx, &validationOpts)
 			return handle(ctx, req, lh.TextDocumentDidOpen)
 		},
 		"textDocument/didClose": func(ctx context.Context, req *jrpc2.Request) (interface{}, error) {
@@ -214,7 +282,10 @@ func (svc *service) Assigner() (jrpc2.Assigner, error) {
 			if err != nil {
 				return nil, err
 			}
+			ctx = lsctx.WithDiagnostics(ctx, diags)
+			ctx = lsctx.WithDiagnosticsScheduler(ctx, diagsScheduler)
 			ctx = lsctx.WithDocumentStorage(ctx, svc.fs)
+			ctx = lsctx.WithRootDirectory(ctx, &rootDir)
 			return handle(ctx, req, TextDocumentDidClose)
 		},
 		"textDocument/documentSymbol": func(ctx context.Context, req *jrpc2.Request) (interface{}, error) {
@@ -237,6 +308,7 @@ func (svc *service) Assigner() (jrpc2.Assigner, error) {
 			ctx = lsctx.WithDocumentStorage(ctx, svc.fs)
 			ctx = lsctx.WithClientCapabilities(ctx, cc)
 			ctx = lsctx.WithModuleFinder(ctx, svc.modMgr)
+			ctx = lsctx.WithCompletionOptions(ctx, &completionOpts)
 
 			return handle(ctx, req, lh.TextDocumentComplete)
 		},
@@ -263,6 +335,17 @@ func (svc *service) Assigner() (jrpc2.Assigner, error) {
 
 			return handle(ctx, req, lh.TextDocumentFormatting)
 		},
+		"textDocument/codeAction": func(ctx context.Context, req *jrpc2.Request) (interface{}, error) {
+			err := session.CheckInitializationIsConfirmed()
+			if err != nil {
+				return nil, err
+			}
+
+			ctx = lsctx.WithDocumentStorage(ctx, svc.fs)
+			ctx = lsctx.WithModuleFinder(ctx, svc.modMgr)
+
+			return handle(ctx, req, lh.TextDocumentCodeAction)
+		},
 		"textDocument/semanticTokens/full": func(ctx context.Context, req *jrpc2.Request) (interface{}, error) {
 			err := session.CheckInitializationIsConfirmed()
 			if err != nil {
@@ -287,6 +370,31 @@ func (svc *service) Assigner() (jrpc2.Assigner, error) {
 
 			return handle(ctx, req, lh.TextDocumentDidSave)
 		},
+		"textDocument/diagnostic": func(ctx context.Context, req *jrpc2.Request) (interface{}, error) {
+			err := session.CheckInitializationIsConfirmed()
+			if err != nil {
+				return nil, err
+			}
+
+			ctx = lsctx.WithDiagnostics(ctx, diags)
+			ctx = lsctx.WithDiagnosticsScheduler(ctx, diagsScheduler)
+			ctx = lsctx.WithModuleManager(ctx, svc.modMgr)
+			ctx = lsctx.WithValidationOptions(ctx, &validationOpts)
+
+			return handle(ctx, req, TextDocumentDiagnostic)
+		},
+		"workspace/diagnostic": func(ctx context.Context, req *jrpc2.Request) (interface{}, error) {
+			err := session.CheckInitializationIsConfirmed()
+			if err != nil {
+				return nil, err
+			}
+
+			ctx = lsctx.WithDiagnostics(ctx, diags)
+			ctx = lsctx.WithModuleManager(ctx, svc.modMgr)
+			ctx = lsctx.WithValidationOptions(ctx, &validationOpts)
+
+			return handle(ctx, req, WorkspaceDiagnostic)
+		},
 		"workspace/executeCommand": func(ctx context.Context, req *jrpc2.Request) (interface{}, error) {
 			err := session.CheckInitializationIsConfirmed()
 			if err != nil {
@@ -360,6 +468,16 @@ func (svc *service) shutdown() {
 		}
 	}
 
+	if svc.dirWatcher != nil {
+		svc.logger.Println("stopping directory watcher for session ...")
+		err := svc.dirWatcher.Stop()
+		if err != nil {
+			svc.logger.Println("unable to stop directory watcher for session:", err)
+		} else {
+			svc.logger.Println("directory watcher stopped")
+		}
+	}
+
 	if svc.modMgr != nil {
 		svc.logger.Println("cancelling any module loading ...")
 		svc.modMgr.CancelLoading()
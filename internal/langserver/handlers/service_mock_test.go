@@ -54,6 +54,7 @@ func (ms *mockSession) new(srvCtx context.Context) session.Session {
 		fs:               fs,
 		newModuleManager: module.NewModuleManagerMock(input),
 		newWatcher:       watcher.MockWatcher(),
+		newDirWatcher:    watcher.MockDirWatcher(),
 		newWalker:        module.MockWalker,
 	}
 
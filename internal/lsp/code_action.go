@@ -0,0 +1,29 @@
+package lsp
+
+import (
+	lsp "github.com/hashicorp/terraform-ls/internal/protocol"
+)
+
+// CodeAction mirrors lsp.CodeAction without the "disabled" field,
+// which the generated struct always serializes (as it is not a pointer),
+// making clients treat every returned code action as disabled.
+type CodeAction struct {
+	Title       string             `json:"title"`
+	Kind        lsp.CodeActionKind `json:"kind,omitempty"`
+	Diagnostics []lsp.Diagnostic   `json:"diagnostics,omitempty"`
+	IsPreferred bool               `json:"isPreferred,omitempty"`
+	Edit        lsp.WorkspaceEdit  `json:"edit,omitempty"`
+}
+
+// RangesOverlap returns true if the two ranges share
+// at least one position, including their boundaries
+func RangesOverlap(a, b lsp.Range) bool {
+	return !positionLess(a.End, b.Start) && !positionLess(b.End, a.Start)
+}
+
+func positionLess(a, b lsp.Position) bool {
+	if a.Line != b.Line {
+		return a.Line < b.Line
+	}
+	return a.Character < b.Character
+}
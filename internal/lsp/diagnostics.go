@@ -1,10 +1,40 @@
 package lsp
 
 import (
+	"path/filepath"
+	"strings"
+
 	"github.com/hashicorp/hcl/v2"
+	tfjson "github.com/hashicorp/terraform-json"
 	lsp "github.com/hashicorp/terraform-ls/internal/protocol"
+	"github.com/hashicorp/terraform-ls/internal/uri"
 )
 
+// DiagnosticDetails represents details of a diagnostic
+// which hcl.Diagnostic has no room for
+type DiagnosticDetails struct {
+	// Code is a stable identifier of the kind of diagnostic
+	Code string
+
+	// CodeDescription is a link to documentation related to the code
+	CodeDescription string
+
+	Related []RelatedInformation
+	Tags    []lsp.DiagnosticTag
+}
+
+// RelatedInformation represents a range related to a diagnostic,
+// e.g. the original declaration of a duplicate
+type RelatedInformation struct {
+	// Range.Filename is either absolute or relative
+	// to the directory of the diagnostic's file
+	Range   hcl.Range
+	Message string
+}
+
+// DiagnosticDetailsFunc returns details of the given diagnostic
+type DiagnosticDetailsFunc func(diag *hcl.Diagnostic) DiagnosticDetails
+
 func HCLSeverityToLSP(severity hcl.DiagnosticSeverity) lsp.DiagnosticSeverity {
 	var sev lsp.DiagnosticSeverity
 	switch severity {
@@ -18,7 +48,18 @@ func HCLSeverityToLSP(severity hcl.DiagnosticSeverity) lsp.DiagnosticSeverity {
 	return sev
 }
 
-func HCLDiagsToLSP(hclDiags hcl.Diagnostics, source string) []lsp.Diagnostic {
+// HCLDiagsToLSP converts the given hcl diagnostics to LSP diagnostics
+// and sets the source, as well as any tags on each one of them.
+func HCLDiagsToLSP(hclDiags hcl.Diagnostics, source string, tags ...lsp.DiagnosticTag) []lsp.Diagnostic {
+	return DetailedHCLDiagsToLSP(hclDiags, "", source, func(*hcl.Diagnostic) DiagnosticDetails {
+		return DiagnosticDetails{Tags: tags}
+	})
+}
+
+// DetailedHCLDiagsToLSP converts the given hcl diagnostics of files
+// in dirPath to LSP diagnostics with details returned by detailsFunc,
+// such as codes or related information.
+func DetailedHCLDiagsToLSP(hclDiags hcl.Diagnostics, dirPath, source string, detailsFunc DiagnosticDetailsFunc) []lsp.Diagnostic {
 	diags := []lsp.Diagnostic{}
 
 	for _, hclDiag := range hclDiags {
@@ -30,13 +71,119 @@ func HCLDiagsToLSP(hclDiags hcl.Diagnostics, source string) []lsp.Diagnostic {
 		if hclDiag.Subject != nil {
 			rnge = HCLRangeToLSP(*hclDiag.Subject)
 		}
-		diags = append(diags, lsp.Diagnostic{
+		diag := lsp.Diagnostic{
 			Range:    rnge,
 			Severity: HCLSeverityToLSP(hclDiag.Severity),
 			Source:   source,
 			Message:  msg,
+		}
+		if detailsFunc != nil {
+			setDiagnosticDetails(&diag, dirPath, detailsFunc(hclDiag))
+		}
+		diags = append(diags, diag)
+	}
+	return diags
+}
+
+// TFJSONDiagToLSP converts the given diagnostic, as reported by Terraform
+// for a file in dirPath, to an LSP diagnostic with the given details.
+// Snippet of the diagnostic (i.e. the surrounding block and values
+// of referenced expressions) is mapped into related information.
+func TFJSONDiagToLSP(tfDiag tfjson.Diagnostic, dirPath, source string, details DiagnosticDetails) lsp.Diagnostic {
+	msg := tfDiag.Summary
+	if tfDiag.Detail != "" {
+		msg += ": " + tfDiag.Detail
+	}
+
+	var sev lsp.DiagnosticSeverity
+	switch tfDiag.Severity {
+	case tfjson.DiagnosticSeverityError:
+		sev = lsp.SeverityError
+	case tfjson.DiagnosticSeverityWarning:
+		sev = lsp.SeverityWarning
+	}
+
+	var subject hcl.Range
+	if tfDiag.Range != nil {
+		subject = tfjsonRangeToHCL(*tfDiag.Range)
+	}
+
+	diag := lsp.Diagnostic{
+		Range:    HCLRangeToLSP(subject),
+		Severity: sev,
+		Source:   source,
+		Message:  msg,
+	}
+
+	if snippet := tfDiag.Snippet; snippet != nil && tfDiag.Range != nil {
+		if snippet.Context != nil {
+			details.Related = append(details.Related, RelatedInformation{
+				Range:   snippetRange(subject.Filename, snippet),
+				Message: "in " + *snippet.Context,
+			})
+		}
+		for _, val := range snippet.Values {
+			details.Related = append(details.Related, RelatedInformation{
+				Range:   subject,
+				Message: val.Traversal + " " + val.Statement,
+			})
+		}
+	}
+
+	setDiagnosticDetails(&diag, dirPath, details)
+
+	return diag
+}
+
+func setDiagnosticDetails(diag *lsp.Diagnostic, dirPath string, details DiagnosticDetails) {
+	if details.Code != "" {
+		diag.Code = details.Code
+	}
+	if details.CodeDescription != "" {
+		diag.CodeDescription = &lsp.CodeDescription{
+			Href: lsp.URI(details.CodeDescription),
+		}
+	}
+	diag.Tags = details.Tags
+
+	for _, related := range details.Related {
+		path := related.Range.Filename
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(dirPath, path)
+		}
+		diag.RelatedInformation = append(diag.RelatedInformation, lsp.DiagnosticRelatedInformation{
+			Location: lsp.Location{
+				URI:   lsp.DocumentURI(uri.FromPath(path)),
+				Range: HCLRangeToLSP(related.Range),
+			},
+			Message: related.Message,
 		})
+	}
+}
 
+func tfjsonRangeToHCL(rng tfjson.Range) hcl.Range {
+	return hcl.Range{
+		Filename: rng.Filename,
+		Start:    hcl.Pos(rng.Start),
+		End:      hcl.Pos(rng.End),
+	}
+}
+
+// snippetRange returns range of the code of the given snippet,
+// i.e. of all its lines, starting at snippet.StartLine
+func snippetRange(filename string, snippet *tfjson.DiagnosticSnippet) hcl.Range {
+	lines := strings.Split(snippet.Code, "\n")
+	lastLine := lines[len(lines)-1]
+
+	return hcl.Range{
+		Filename: filename,
+		Start: hcl.Pos{
+			Line:   snippet.StartLine,
+			Column: 1,
+		},
+		End: hcl.Pos{
+			Line:   snippet.StartLine + len(lines) - 1,
+			Column: len(lastLine) + 1,
+		},
 	}
-	return diags
 }
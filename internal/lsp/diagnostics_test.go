@@ -1,9 +1,14 @@
 package lsp
 
 import (
+	"path/filepath"
 	"testing"
 
+	"github.com/google/go-cmp/cmp"
 	"github.com/hashicorp/hcl/v2"
+	tfjson "github.com/hashicorp/terraform-json"
+	lsp "github.com/hashicorp/terraform-ls/internal/protocol"
+	"github.com/hashicorp/terraform-ls/internal/uri"
 )
 
 func TestHCLDiagsToLSP_NeverReturnsNil(t *testing.T) {
@@ -26,3 +31,120 @@ func TestHCLDiagsToLSP_NeverReturnsNil(t *testing.T) {
 		t.Fatal("diags should not be nil")
 	}
 }
+
+func TestDetailedHCLDiagsToLSP(t *testing.T) {
+	subject := hcl.Range{
+		Filename: "main.tf",
+		Start:    hcl.Pos{Line: 3, Column: 1, Byte: 20},
+		End:      hcl.Pos{Line: 3, Column: 18, Byte: 37},
+	}
+	diags := DetailedHCLDiagsToLSP(hcl.Diagnostics{
+		{
+			Severity: hcl.DiagError,
+			Summary:  "Duplicate variable declaration",
+			Subject:  &subject,
+		},
+	}, "/test", "source", func(*hcl.Diagnostic) DiagnosticDetails {
+		return DiagnosticDetails{
+			Code:            "duplicate-variable",
+			CodeDescription: "https://example.com/docs",
+			Related: []RelatedInformation{
+				{
+					Range: hcl.Range{
+						Filename: "variables.tf",
+						Start:    hcl.Pos{Line: 1, Column: 1, Byte: 0},
+						End:      hcl.Pos{Line: 1, Column: 18, Byte: 17},
+					},
+					Message: "Originally declared here",
+				},
+			},
+		}
+	})
+
+	expectedDiags := []lsp.Diagnostic{
+		{
+			Range: lsp.Range{
+				Start: lsp.Position{Line: 2, Character: 0},
+				End:   lsp.Position{Line: 2, Character: 17},
+			},
+			Severity:        lsp.SeverityError,
+			Code:            "duplicate-variable",
+			CodeDescription: &lsp.CodeDescription{Href: "https://example.com/docs"},
+			Source:          "source",
+			Message:         "Duplicate variable declaration",
+			RelatedInformation: []lsp.DiagnosticRelatedInformation{
+				{
+					Location: lsp.Location{
+						URI: lsp.DocumentURI(uri.FromPath(filepath.Join("/test", "variables.tf"))),
+						Range: lsp.Range{
+							Start: lsp.Position{Line: 0, Character: 0},
+							End:   lsp.Position{Line: 0, Character: 17},
+						},
+					},
+					Message: "Originally declared here",
+				},
+			},
+		},
+	}
+	if diff := cmp.Diff(expectedDiags, diags); diff != "" {
+		t.Fatalf("unexpected diagnostics: %s", diff)
+	}
+}
+
+func TestTFJSONDiagToLSP_snippet(t *testing.T) {
+	snippetContext := `resource "aws_instance" "web"`
+	diag := TFJSONDiagToLSP(tfjson.Diagnostic{
+		Severity: tfjson.DiagnosticSeverityError,
+		Summary:  "Invalid value",
+		Detail:   "Expected a number.",
+		Range: &tfjson.Range{
+			Filename: "main.tf",
+			Start:    tfjson.Pos{Line: 3, Column: 11, Byte: 50},
+			End:      tfjson.Pos{Line: 3, Column: 20, Byte: 59},
+		},
+		Snippet: &tfjson.DiagnosticSnippet{
+			Context:   &snippetContext,
+			Code:      "  count = var.count\n  ami   = var.ami",
+			StartLine: 3,
+			Values: []tfjson.DiagnosticExpressionValue{
+				{Traversal: "var.count", Statement: `is "two"`},
+			},
+		},
+	}, "/test", "terraform validate", DiagnosticDetails{})
+
+	mainURI := lsp.DocumentURI(uri.FromPath(filepath.Join("/test", "main.tf")))
+	expectedDiag := lsp.Diagnostic{
+		Range: lsp.Range{
+			Start: lsp.Position{Line: 2, Character: 10},
+			End:   lsp.Position{Line: 2, Character: 19},
+		},
+		Severity: lsp.SeverityError,
+		Source:   "terraform validate",
+		Message:  "Invalid value: Expected a number.",
+		RelatedInformation: []lsp.DiagnosticRelatedInformation{
+			{
+				Location: lsp.Location{
+					URI: mainURI,
+					Range: lsp.Range{
+						Start: lsp.Position{Line: 2, Character: 0},
+						End:   lsp.Position{Line: 3, Character: 17},
+					},
+				},
+				Message: `in resource "aws_instance" "web"`,
+			},
+			{
+				Location: lsp.Location{
+					URI: mainURI,
+					Range: lsp.Range{
+						Start: lsp.Position{Line: 2, Character: 10},
+						End:   lsp.Position{Line: 2, Character: 19},
+					},
+				},
+				Message: `var.count is "two"`,
+			},
+		},
+	}
+	if diff := cmp.Diff(expectedDiag, diag); diff != "" {
+		t.Fatalf("unexpected diagnostic: %s", diff)
+	}
+}
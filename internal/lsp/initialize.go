@@ -0,0 +1,59 @@
+package lsp
+
+import (
+	"encoding/json"
+
+	lsp "github.com/hashicorp/terraform-ls/internal/protocol"
+)
+
+// InitializeParams extends lsp.InitializeParams with client
+// capabilities introduced in LSP 3.17, which lsp.ClientCapabilities
+// has no room for
+type InitializeParams struct {
+	lsp.InitializeParams
+
+	// Diagnostic is nil if the client doesn't support pull diagnostics
+	Diagnostic           *DiagnosticClientCapabilities
+	WorkspaceDiagnostics DiagnosticWorkspaceClientCapabilities
+}
+
+func (p *InitializeParams) UnmarshalJSON(b []byte) error {
+	err := json.Unmarshal(b, &p.InitializeParams)
+	if err != nil {
+		return err
+	}
+
+	var params struct {
+		Capabilities struct {
+			TextDocument struct {
+				Diagnostic *DiagnosticClientCapabilities `json:"diagnostic"`
+			} `json:"textDocument"`
+			Workspace struct {
+				Diagnostics DiagnosticWorkspaceClientCapabilities `json:"diagnostics"`
+			} `json:"workspace"`
+		} `json:"capabilities"`
+	}
+	err = json.Unmarshal(b, &params)
+	if err != nil {
+		return err
+	}
+	p.Diagnostic = params.Capabilities.TextDocument.Diagnostic
+	p.WorkspaceDiagnostics = params.Capabilities.Workspace.Diagnostics
+
+	return nil
+}
+
+// InitializeResult mirrors lsp.InitializeResult
+// with server capabilities introduced in LSP 3.17
+type InitializeResult struct {
+	Capabilities ServerCapabilities `json:"capabilities"`
+	ServerInfo   struct {
+		Name    string `json:"name"`
+		Version string `json:"version,omitempty"`
+	} `json:"serverInfo,omitempty"`
+}
+
+type ServerCapabilities struct {
+	lsp.ServerCapabilities
+	DiagnosticProvider *DiagnosticOptions `json:"diagnosticProvider,omitempty"`
+}
@@ -0,0 +1,110 @@
+package lsp
+
+import (
+	lsp "github.com/hashicorp/terraform-ls/internal/protocol"
+)
+
+// The types below represent pull diagnostics introduced in LSP 3.17,
+// which the generated protocol package (LSP 3.16) doesn't contain yet.
+
+// DiagnosticClientCapabilities represents client capabilities
+// of the textDocument/diagnostic request
+type DiagnosticClientCapabilities struct {
+	DynamicRegistration    bool `json:"dynamicRegistration,omitempty"`
+	RelatedDocumentSupport bool `json:"relatedDocumentSupport,omitempty"`
+}
+
+// DiagnosticWorkspaceClientCapabilities represents
+// workspace specific client capabilities of diagnostics
+type DiagnosticWorkspaceClientCapabilities struct {
+	// RefreshSupport indicates whether the client supports
+	// the workspace/diagnostic/refresh request
+	RefreshSupport bool `json:"refreshSupport,omitempty"`
+}
+
+// DiagnosticOptions represents the server capability
+// of providing pull diagnostics
+type DiagnosticOptions struct {
+	Identifier            string `json:"identifier,omitempty"`
+	InterFileDependencies bool   `json:"interFileDependencies"`
+	WorkspaceDiagnostics  bool   `json:"workspaceDiagnostics"`
+}
+
+type DocumentDiagnosticParams struct {
+	TextDocument     lsp.TextDocumentIdentifier `json:"textDocument"`
+	Identifier       string                     `json:"identifier,omitempty"`
+	PreviousResultID string                     `json:"previousResultId,omitempty"`
+}
+
+type WorkspaceDiagnosticParams struct {
+	Identifier        string             `json:"identifier,omitempty"`
+	PreviousResultIDs []PreviousResultID `json:"previousResultIds"`
+}
+
+// PreviousResultID represents the result ID of diagnostics
+// of a document as last reported to the client
+type PreviousResultID struct {
+	URI   lsp.DocumentURI `json:"uri"`
+	Value string          `json:"value"`
+}
+
+const (
+	DiagnosticReportFull      = "full"
+	DiagnosticReportUnchanged = "unchanged"
+)
+
+// FullDocumentDiagnosticReport represents all diagnostics of a document
+type FullDocumentDiagnosticReport struct {
+	Kind     string           `json:"kind"`
+	ResultID string           `json:"resultId,omitempty"`
+	Items    []lsp.Diagnostic `json:"items"`
+}
+
+// UnchangedDocumentDiagnosticReport tells the client that diagnostics
+// of a document haven't changed since the given result ID
+type UnchangedDocumentDiagnosticReport struct {
+	Kind     string `json:"kind"`
+	ResultID string `json:"resultId"`
+}
+
+func NewFullDocumentDiagnosticReport(resultID string, diags []lsp.Diagnostic) FullDocumentDiagnosticReport {
+	if diags == nil {
+		diags = []lsp.Diagnostic{}
+	}
+	return FullDocumentDiagnosticReport{
+		Kind:     DiagnosticReportFull,
+		ResultID: resultID,
+		Items:    diags,
+	}
+}
+
+func NewUnchangedDocumentDiagnosticReport(resultID string) UnchangedDocumentDiagnosticReport {
+	return UnchangedDocumentDiagnosticReport{
+		Kind:     DiagnosticReportUnchanged,
+		ResultID: resultID,
+	}
+}
+
+// WorkspaceFullDocumentDiagnosticReport is a full report
+// of a document within a workspace report.
+// Version is nil if the document is not open.
+type WorkspaceFullDocumentDiagnosticReport struct {
+	FullDocumentDiagnosticReport
+	URI     lsp.DocumentURI `json:"uri"`
+	Version *int            `json:"version"`
+}
+
+// WorkspaceUnchangedDocumentDiagnosticReport is an unchanged report
+// of a document within a workspace report.
+// Version is nil if the document is not open.
+type WorkspaceUnchangedDocumentDiagnosticReport struct {
+	UnchangedDocumentDiagnosticReport
+	URI     lsp.DocumentURI `json:"uri"`
+	Version *int            `json:"version"`
+}
+
+// WorkspaceDiagnosticReport contains either full or unchanged
+// reports of documents within the workspace
+type WorkspaceDiagnosticReport struct {
+	Items []interface{} `json:"items"`
+}
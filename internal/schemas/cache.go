@@ -0,0 +1,281 @@
+package schemas
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net/url"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/hashicorp/go-version"
+	tfjson "github.com/hashicorp/terraform-json"
+)
+
+var discardLogger = log.New(ioutil.Discard, "", 0)
+
+// ProviderSchemaCache represents a content-addressed cache of provider
+// schemas keyed by provider address, version and platform.
+//
+// Schemas are persisted on disk (if a directory is provided),
+// so that they can be shared between sessions, and identical schemas
+// are shared in memory between all modules using the cache.
+type ProviderSchemaCache struct {
+	dir      string
+	platform string
+	logger   *log.Logger
+
+	// blobs contains schemas keyed by hash of their content
+	blobs map[string]*tfjson.ProviderSchema
+	// index contains hashes of schemas keyed by provider
+	index map[string]string
+	mu    *sync.RWMutex
+
+	keyLocks   map[string]*sync.Mutex
+	keyLocksMu *sync.Mutex
+}
+
+// DefaultProviderSchemaCacheDir returns path to the directory
+// in which provider schemas are cached by default
+func DefaultProviderSchemaCacheDir() (string, error) {
+	cacheDir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(cacheDir, "terraform-ls", "provider-schemas"), nil
+}
+
+// NewProviderSchemaCache creates a cache persisted in the given directory,
+// or a cache which is only kept in memory if dir is empty
+func NewProviderSchemaCache(dir string) *ProviderSchemaCache {
+	return &ProviderSchemaCache{
+		dir:        dir,
+		platform:   runtime.GOOS + "_" + runtime.GOARCH,
+		logger:     discardLogger,
+		blobs:      make(map[string]*tfjson.ProviderSchema, 0),
+		index:      make(map[string]string, 0),
+		mu:         &sync.RWMutex{},
+		keyLocks:   make(map[string]*sync.Mutex, 0),
+		keyLocksMu: &sync.Mutex{},
+	}
+}
+
+func (c *ProviderSchemaCache) SetLogger(logger *log.Logger) {
+	c.logger = logger
+}
+
+// LockProviders acquires a lock for the given set of providers and returns
+// function to release it, which allows callers obtaining schemas
+// of the same providers to wait for the first one to populate the cache
+// instead of obtaining the same schemas in parallel
+func (c *ProviderSchemaCache) LockProviders(versions map[string]*version.Version) func() {
+	keys := make([]string, 0, len(versions))
+	for addr, v := range versions {
+		keys = append(keys, c.key(addr, v))
+	}
+	sort.Strings(keys)
+	setKey := strings.Join(keys, ",")
+
+	c.keyLocksMu.Lock()
+	l, ok := c.keyLocks[setKey]
+	if !ok {
+		l = &sync.Mutex{}
+		c.keyLocks[setKey] = l
+	}
+	c.keyLocksMu.Unlock()
+
+	l.Lock()
+	return l.Unlock
+}
+
+// ProviderSchemas returns cached schemas of all the given providers,
+// or false if schema of any of the providers is not cached
+func (c *ProviderSchemaCache) ProviderSchemas(versions map[string]*version.Version) (*tfjson.ProviderSchemas, bool) {
+	if len(versions) == 0 {
+		return nil, false
+	}
+
+	ps := &tfjson.ProviderSchemas{
+		FormatVersion: tfjson.ProviderSchemasFormatVersion,
+		Schemas:       make(map[string]*tfjson.ProviderSchema, len(versions)),
+	}
+	for addr, v := range versions {
+		if v == nil {
+			return nil, false
+		}
+		schema, ok := c.get(addr, v)
+		if !ok {
+			return nil, false
+		}
+		ps.Schemas[addr] = schema
+	}
+
+	return ps, true
+}
+
+// Put stores schemas of providers whose versions are known and returns
+// the given schemas where schemas identical to any cached ones
+// are replaced with the cached ones, to avoid keeping duplicates in memory
+func (c *ProviderSchemaCache) Put(ps *tfjson.ProviderSchemas, versions map[string]*version.Version) *tfjson.ProviderSchemas {
+	if ps == nil {
+		return nil
+	}
+
+	result := &tfjson.ProviderSchemas{
+		FormatVersion: ps.FormatVersion,
+		Schemas:       make(map[string]*tfjson.ProviderSchema, len(ps.Schemas)),
+	}
+	for addr, schema := range ps.Schemas {
+		result.Schemas[addr] = schema
+
+		v, ok := versions[addr]
+		if !ok || v == nil {
+			continue
+		}
+		shared, err := c.put(addr, v, schema)
+		if err != nil {
+			c.logger.Printf("failed to cache schema of %s %s: %s", addr, v, err)
+			continue
+		}
+		result.Schemas[addr] = shared
+	}
+
+	return result
+}
+
+func (c *ProviderSchemaCache) get(addr string, v *version.Version) (*tfjson.ProviderSchema, bool) {
+	key := c.key(addr, v)
+
+	c.mu.RLock()
+	hash, ok := c.index[key]
+	if ok {
+		schema, ok := c.blobs[hash]
+		c.mu.RUnlock()
+		return schema, ok
+	}
+	c.mu.RUnlock()
+
+	if c.dir == "" {
+		return nil, false
+	}
+
+	rawHash, err := ioutil.ReadFile(c.indexPath(addr, v))
+	if err != nil {
+		return nil, false
+	}
+	hash = strings.TrimSpace(string(rawHash))
+
+	schema, err := c.readBlob(hash)
+	if err != nil {
+		c.logger.Printf("failed to read cached schema of %s %s: %s", addr, v, err)
+		return nil, false
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if shared, ok := c.blobs[hash]; ok {
+		schema = shared
+	}
+	c.blobs[hash] = schema
+	c.index[key] = hash
+
+	return schema, true
+}
+
+func (c *ProviderSchemaCache) put(addr string, v *version.Version, schema *tfjson.ProviderSchema) (*tfjson.ProviderSchema, error) {
+	b, err := json.Marshal(schema)
+	if err != nil {
+		return nil, err
+	}
+	sum := sha256.Sum256(b)
+	hash := hex.EncodeToString(sum[:])
+
+	c.mu.Lock()
+	if shared, ok := c.blobs[hash]; ok {
+		schema = shared
+	}
+	c.blobs[hash] = schema
+	c.index[c.key(addr, v)] = hash
+	c.mu.Unlock()
+
+	if c.dir == "" {
+		return schema, nil
+	}
+
+	blobPath := c.blobPath(hash)
+	if _, err := os.Stat(blobPath); os.IsNotExist(err) {
+		err := writeFileAtomically(blobPath, b)
+		if err != nil {
+			return schema, err
+		}
+	}
+
+	return schema, writeFileAtomically(c.indexPath(addr, v), []byte(hash))
+}
+
+func (c *ProviderSchemaCache) readBlob(hash string) (*tfjson.ProviderSchema, error) {
+	b, err := ioutil.ReadFile(c.blobPath(hash))
+	if err != nil {
+		return nil, err
+	}
+
+	sum := sha256.Sum256(b)
+	if hex.EncodeToString(sum[:]) != hash {
+		return nil, fmt.Errorf("checksum mismatch of %s", hash)
+	}
+
+	schema := &tfjson.ProviderSchema{}
+	err = json.Unmarshal(b, schema)
+	if err != nil {
+		return nil, err
+	}
+	return schema, nil
+}
+
+func (c *ProviderSchemaCache) key(addr string, v *version.Version) string {
+	vString := ""
+	if v != nil {
+		vString = v.String()
+	}
+	return fmt.Sprintf("%s/%s@%s", c.platform, addr, vString)
+}
+
+func (c *ProviderSchemaCache) blobPath(hash string) string {
+	return filepath.Join(c.dir, "blobs", hash+".json")
+}
+
+func (c *ProviderSchemaCache) indexPath(addr string, v *version.Version) string {
+	return filepath.Join(c.dir, "index", c.platform, url.PathEscape(addr), v.String())
+}
+
+// writeFileAtomically writes data into a temporary file first,
+// so that readers (e.g. other sessions) never see a partial file
+func writeFileAtomically(path string, data []byte) error {
+	dir := filepath.Dir(path)
+	err := os.MkdirAll(dir, 0755)
+	if err != nil {
+		return err
+	}
+
+	f, err := ioutil.TempFile(dir, filepath.Base(path)+".tmp")
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(data)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(f.Name())
+		return err
+	}
+
+	return os.Rename(f.Name(), path)
+}
@@ -0,0 +1,77 @@
+package schemas
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/hashicorp/go-version"
+	tfjson "github.com/hashicorp/terraform-json"
+	"github.com/zclconf/go-cty/cty"
+)
+
+func TestProviderSchemaCache(t *testing.T) {
+	dir, err := ioutil.TempDir("", "schema-cache")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	versions := map[string]*version.Version{
+		"registry.terraform.io/hashicorp/aws": version.Must(version.NewVersion("3.20.0")),
+	}
+	ps := &tfjson.ProviderSchemas{
+		FormatVersion: "0.1",
+		Schemas: map[string]*tfjson.ProviderSchema{
+			"registry.terraform.io/hashicorp/aws": testProviderSchema(),
+		},
+	}
+
+	cache := NewProviderSchemaCache(dir)
+	if _, ok := cache.ProviderSchemas(versions); ok {
+		t.Fatal("expected empty cache to miss")
+	}
+	cache.Put(ps, versions)
+
+	// identical schema obtained by another module should be shared
+	otherPs := &tfjson.ProviderSchemas{
+		FormatVersion: "0.1",
+		Schemas: map[string]*tfjson.ProviderSchema{
+			"registry.terraform.io/hashicorp/aws": testProviderSchema(),
+		},
+	}
+	shared := cache.Put(otherPs, versions)
+	if shared.Schemas["registry.terraform.io/hashicorp/aws"] != ps.Schemas["registry.terraform.io/hashicorp/aws"] {
+		t.Fatal("expected identical schemas to be shared")
+	}
+
+	// schemas should persist across sessions
+	newCache := NewProviderSchemaCache(dir)
+	cached, ok := newCache.ProviderSchemas(versions)
+	if !ok {
+		t.Fatal("expected cached schemas to be found on disk")
+	}
+	attr := cached.Schemas["registry.terraform.io/hashicorp/aws"].ConfigSchema.Block.Attributes["region"]
+	if attr == nil || !attr.Required {
+		t.Fatalf("unexpected cached schema: %#v", cached.Schemas)
+	}
+
+	newVersions := map[string]*version.Version{
+		"registry.terraform.io/hashicorp/aws": version.Must(version.NewVersion("3.21.0")),
+	}
+	if _, ok := newCache.ProviderSchemas(newVersions); ok {
+		t.Fatal("expected cache to miss for different version")
+	}
+}
+
+func testProviderSchema() *tfjson.ProviderSchema {
+	return &tfjson.ProviderSchema{
+		ConfigSchema: &tfjson.Schema{
+			Block: &tfjson.SchemaBlock{
+				Attributes: map[string]*tfjson.SchemaAttribute{
+					"region": {AttributeType: cty.String, Required: true},
+				},
+			},
+		},
+	}
+}
@@ -12,6 +12,9 @@ import (
 	"net/http"
 	"os"
 	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
 
 	"github.com/hashicorp/go-version"
 	"github.com/hashicorp/terraform-exec/tfexec"
@@ -26,12 +29,19 @@ const terraformBlock = `terraform {
   {{ range $p := . }}
     {{ $p.Name }} = {
       source = "{{ $p.Source }}"
+      {{- if $p.Version }}
+      version = "{{ $p.Version }}"
+      {{- end }}
     }
   {{ end }}
   }
 }
 `
 
+// majorVersions is the number of latest major versions
+// of each provider to preload schemas for
+const majorVersions = 3
+
 func main() {
 	os.Exit(func() int {
 		if err := gen(); err != nil {
@@ -61,45 +71,103 @@ func gen() error {
 
 	providers = append(providers, partnerProviders...)
 
+	log.Println("fetching provider versions from registry")
+	for i, p := range providers {
+		if p.IsBuiltin() {
+			continue
+		}
+		versions, err := listMajorVersions(p)
+		if err != nil {
+			return err
+		}
+		providers[i].versions = versions
+	}
+
 	log.Println("parsing template")
 	tmpl, err := template.New("providers").Parse(terraformBlock)
 	if err != nil {
 		return err
 	}
 
-	log.Println("creating config file")
-	configFile, err := os.Create("providers.tf")
+	log.Println("ensuring terraform is installed")
+
+	tmpDir, err := ioutil.TempDir("", "tfinstall")
 	if err != nil {
 		return err
 	}
+	defer os.RemoveAll(tmpDir)
 
-	log.Println("executing template")
-	err = tmpl.Execute(configFile, providers)
+	execPath, err := tfinstall.Find(ctx, tfinstall.LookPath(), tfinstall.LatestVersion(tmpDir, false))
 	if err != nil {
 		return err
 	}
 
-	log.Println("ensuring terraform is installed")
-
-	tmpDir, err := ioutil.TempDir("", "tfinstall")
+	log.Println("creating schemas/data dir")
+	err = os.MkdirAll("data", 0755)
 	if err != nil {
 		return err
 	}
-	defer os.RemoveAll(tmpDir)
+	fs := http.Dir("data")
 
-	execPath, err := tfinstall.Find(ctx, tfinstall.LookPath(), tfinstall.LatestVersion(tmpDir, false))
+	// each generation holds the n-th latest major version of providers
+	for n := 0; n < majorVersions; n++ {
+		genProviders := make([]generationProvider, 0)
+		for _, p := range providers {
+			if n == 0 && p.IsBuiltin() {
+				genProviders = append(genProviders, generationProvider{provider: p})
+				continue
+			}
+			if n < len(p.versions) {
+				genProviders = append(genProviders, generationProvider{
+					provider: p,
+					Version:  p.versions[n].String(),
+				})
+			}
+		}
+		if len(genProviders) == 0 {
+			break
+		}
+
+		log.Printf("generating schemas of %d providers (generation %d)", len(genProviders), n)
+		err = genGeneration(ctx, execPath, tmpl, genProviders, filepath.Join("data", strconv.Itoa(n)))
+		if err != nil {
+			return err
+		}
+	}
+
+	log.Println("generating embedded go file")
+	return vfsgen.Generate(fs, vfsgen.Options{
+		Filename:     "schemas_gen.go",
+		PackageName:  "schemas",
+		VariableName: "files",
+	})
+}
+
+func genGeneration(ctx context.Context, execPath string, tmpl *template.Template,
+	providers []generationProvider, dataDir string) error {
+
+	workDir, err := ioutil.TempDir("", "providers")
 	if err != nil {
 		return err
 	}
+	defer os.RemoveAll(workDir)
 
-	log.Println("running terraform init")
+	log.Println("creating config file")
+	configFile, err := os.Create(filepath.Join(workDir, "providers.tf"))
+	if err != nil {
+		return err
+	}
+	defer configFile.Close()
 
-	cwd, err := os.Getwd()
+	log.Println("executing template")
+	err = tmpl.Execute(configFile, providers)
 	if err != nil {
 		return err
 	}
 
-	tf, err := tfexec.NewTerraform(cwd, execPath)
+	log.Println("running terraform init")
+
+	tf, err := tfexec.NewTerraform(workDir, execPath)
 	if err != nil {
 		return err
 	}
@@ -109,12 +177,10 @@ func gen() error {
 		return err
 	}
 
-	log.Println("creating schemas/data dir")
-	err = os.MkdirAll("data", 0755)
+	err = os.MkdirAll(dataDir, 0755)
 	if err != nil {
 		return err
 	}
-	fs := http.Dir("data")
 
 	coreVersion, providerVersions, err := tf.Version(ctx, true)
 	if err != nil {
@@ -122,10 +188,11 @@ func gen() error {
 	}
 
 	log.Println("creating version file")
-	versionFile, err := os.Create(filepath.Join("data", "versions.json"))
+	versionFile, err := os.Create(filepath.Join(dataDir, "versions.json"))
 	if err != nil {
 		return err
 	}
+	defer versionFile.Close()
 	versionOutput := &schemas.RawVersionOutput{
 		CoreVersion: coreVersion.String(),
 		Providers:   stringifyProviderVersions(providerVersions),
@@ -145,23 +212,14 @@ func gen() error {
 	}
 
 	log.Println("creating schemas file")
-	schemasFile, err := os.Create(filepath.Join("data", "schemas.json"))
+	schemasFile, err := os.Create(filepath.Join(dataDir, "schemas.json"))
 	if err != nil {
 		return err
 	}
+	defer schemasFile.Close()
 
 	log.Println("writing schemas to file")
-	err = json.NewEncoder(schemasFile).Encode(ps)
-	if err != nil {
-		return err
-	}
-
-	log.Println("generating embedded go file")
-	return vfsgen.Generate(fs, vfsgen.Options{
-		Filename:     "schemas_gen.go",
-		PackageName:  "schemas",
-		VariableName: "files",
-	})
+	return json.NewEncoder(schemasFile).Encode(ps)
 }
 
 func stringifyProviderVersions(m map[string]*version.Version) map[string]string {
@@ -175,12 +233,28 @@ func stringifyProviderVersions(m map[string]*version.Version) map[string]string
 }
 
 type providerAttributes struct {
-	Name     string `json:"name"`
-	FullName string `json:"full-name"`
+	Name      string `json:"name"`
+	Namespace string `json:"namespace"`
+	FullName  string `json:"full-name"`
 }
 
 type provider struct {
 	Attributes providerAttributes `json:"attributes"`
+
+	// versions represents the latest release
+	// of each major version, newest first
+	versions []*version.Version
+}
+
+// generationProvider represents provider pinned
+// to a particular version within a generation
+type generationProvider struct {
+	provider
+	Version string
+}
+
+func (p provider) IsBuiltin() bool {
+	return p.Attributes.Name == "terraform"
 }
 
 func (p provider) Name() string {
@@ -189,7 +263,7 @@ func (p provider) Name() string {
 
 func (p provider) Source() string {
 	// terraform provider is builtin and has special source
-	if p.Attributes.Name == "terraform" {
+	if p.IsBuiltin() {
 		return "terraform.io/builtin/terraform"
 	}
 	return p.Attributes.FullName
@@ -228,6 +302,65 @@ func listProviders(tier string) ([]provider, error) {
 	return filter(providers), nil
 }
 
+type versionsResponse struct {
+	Versions []struct {
+		Version   string   `json:"version"`
+		Protocols []string `json:"protocols"`
+	} `json:"versions"`
+}
+
+// listMajorVersions returns the latest release of each of the latest
+// major versions of the provider which support protocol version 5
+func listMajorVersions(p provider) ([]*version.Version, error) {
+	resp, err := http.Get(fmt.Sprintf("https://registry.terraform.io/v1/providers/%s/%s/versions",
+		p.Attributes.Namespace, p.Attributes.Name))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var response versionsResponse
+	err = json.NewDecoder(resp.Body).Decode(&response)
+	if err != nil {
+		return nil, err
+	}
+
+	latest := make(map[int64]*version.Version, 0)
+	for _, rv := range response.Versions {
+		if !supportsProtocol5(rv.Protocols) {
+			continue
+		}
+		v, err := version.NewVersion(rv.Version)
+		if err != nil || v.Prerelease() != "" {
+			continue
+		}
+		major := v.Segments64()[0]
+		if l, ok := latest[major]; !ok || v.GreaterThan(l) {
+			latest[major] = v
+		}
+	}
+
+	versions := make([]*version.Version, 0, len(latest))
+	for _, v := range latest {
+		versions = append(versions, v)
+	}
+	sort.Sort(sort.Reverse(version.Collection(versions)))
+
+	if len(versions) > majorVersions {
+		versions = versions[:majorVersions]
+	}
+	return versions, nil
+}
+
+func supportsProtocol5(protocols []string) bool {
+	for _, p := range protocols {
+		if strings.HasPrefix(p, "5.") {
+			return true
+		}
+	}
+	return false
+}
+
 // these providers fail to download
 // Error: Failed to query available provider packages
 
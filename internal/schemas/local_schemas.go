@@ -0,0 +1,295 @@
+package schemas
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/hashicorp/go-multierror"
+	"github.com/hashicorp/go-version"
+	tfjson "github.com/hashicorp/terraform-json"
+)
+
+// VersionsFileName is the name of the file describing versions
+// of providers whose schemas are in the same directory,
+// in the same format as the preloaded versions.json
+const VersionsFileName = "versions.json"
+
+// ProviderSchema represents a schema of a single provider
+type ProviderSchema struct {
+	Address string
+
+	// Version is nil if the version of the provider is unknown
+	Version *version.Version
+
+	Schema *tfjson.ProviderSchema
+}
+
+// LocalProviderSchemas represents provider schemas loaded from JSON files
+// produced by "terraform providers schema -json", keyed by provider
+// address and version.
+//
+// This is useful where modules cannot be initialized, e.g. because
+// providers are only available from private registries.
+type LocalProviderSchemas struct {
+	schemas    map[string]map[string]*ProviderSchema
+	generation uint64
+	mu         *sync.RWMutex
+}
+
+func NewLocalProviderSchemas() *LocalProviderSchemas {
+	return &LocalProviderSchemas{
+		schemas: make(map[string]map[string]*ProviderSchema, 0),
+		mu:      &sync.RWMutex{},
+	}
+}
+
+// Load loads schemas from the given paths, each of which is either
+// a JSON file, or a directory, in which case all JSON files
+// in the directory (except versions.json) are loaded.
+//
+// Versions of providers are read from versions.json in the same
+// directory as the schema file, if it exists.
+//
+// Schemas are added to any previously loaded ones. Paths which
+// cannot be loaded are reported in the returned error,
+// but do not prevent loading the other paths.
+func (s *LocalProviderSchemas) Load(paths []string) error {
+	var errs *multierror.Error
+
+	for _, path := range paths {
+		files, err := schemaFilesAtPath(path)
+		if err != nil {
+			errs = multierror.Append(errs, err)
+			continue
+		}
+
+		for _, file := range files {
+			err := s.loadFile(file)
+			if err != nil {
+				errs = multierror.Append(errs, fmt.Errorf("%s: %w", file, err))
+			}
+		}
+	}
+
+	return errs.ErrorOrNil()
+}
+
+func (s *LocalProviderSchemas) loadFile(path string) error {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	ps := &tfjson.ProviderSchemas{}
+	err = json.Unmarshal(b, ps)
+	if err != nil {
+		return err
+	}
+
+	versions, err := readVersionsFile(filepath.Join(filepath.Dir(path), VersionsFileName))
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for addr, schema := range ps.Schemas {
+		pSchema := &ProviderSchema{
+			Address: addr,
+			Version: versions[addr],
+			Schema:  schema,
+		}
+		s.add(pSchema)
+	}
+
+	return nil
+}
+
+func (s *LocalProviderSchemas) add(ps *ProviderSchema) {
+	if _, ok := s.schemas[ps.Address]; !ok {
+		s.schemas[ps.Address] = make(map[string]*ProviderSchema, 0)
+	}
+
+	key := ""
+	if ps.Version != nil {
+		key = ps.Version.String()
+	}
+	s.schemas[ps.Address][key] = ps
+	s.generation++
+}
+
+// Generation returns a number which changes
+// whenever any schemas are (re)loaded
+func (s *LocalProviderSchemas) Generation() uint64 {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.generation
+}
+
+// IsEmpty returns true if no schemas were loaded
+func (s *LocalProviderSchemas) IsEmpty() bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return len(s.schemas) == 0
+}
+
+// ProviderSchemas returns the newest schema of each provider, along with
+// versions of these providers (where known)
+func (s *LocalProviderSchemas) ProviderSchemas() (*tfjson.ProviderSchemas, VersionOutput) {
+	return s.ProviderSchemasForConstraints(nil)
+}
+
+// ProviderSchemasForConstraints returns schemas along with versions
+// of providers (where known), where the newest version satisfying
+// the given constraints (keyed by provider address) is chosen
+// for each constrained provider, in the same way as for preloaded
+// schemas. The newest version is used for any other providers.
+func (s *LocalProviderSchemas) ProviderSchemasForConstraints(constraints map[string]version.Constraints) (*tfjson.ProviderSchemas, VersionOutput) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	ps := &tfjson.ProviderSchemas{
+		FormatVersion: tfjson.ProviderSchemasFormatVersion,
+		Schemas:       make(map[string]*tfjson.ProviderSchema, len(s.schemas)),
+	}
+	vOut := VersionOutput{
+		Providers: make(map[string]*version.Version, 0),
+	}
+
+	for addr, versions := range s.schemas {
+		sorted := sortedProviderSchemas(versions)
+		selected := sorted[0]
+		if c, ok := constraints[addr]; ok {
+			sortedVersions := make([]*version.Version, len(sorted))
+			for i, schema := range sorted {
+				sortedVersions[i] = schema.Version
+			}
+			if i, ok := selectVersion(sortedVersions, c); ok {
+				selected = sorted[i]
+			}
+		}
+
+		ps.Schemas[addr] = selected.Schema
+		if selected.Version != nil {
+			vOut.Providers[addr] = selected.Version
+		}
+	}
+
+	return ps, vOut
+}
+
+// sortedProviderSchemas returns schemas ordered from the newest version,
+// where schema of unknown version is considered oldest
+func sortedProviderSchemas(versions map[string]*ProviderSchema) []*ProviderSchema {
+	sorted := make([]*ProviderSchema, 0, len(versions))
+	for _, ps := range versions {
+		sorted = append(sorted, ps)
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		vi, vj := sorted[i].Version, sorted[j].Version
+		if vi == nil || vj == nil {
+			return vj == nil && vi != nil
+		}
+		return vi.GreaterThan(vj)
+	})
+	return sorted
+}
+
+// MergeProviderSchemas returns schemas and versions of all providers
+// from both sources, where providers in override take precedence
+func MergeProviderSchemas(base *tfjson.ProviderSchemas, baseVersions VersionOutput,
+	override *tfjson.ProviderSchemas, overrideVersions VersionOutput) (*tfjson.ProviderSchemas, VersionOutput) {
+	if base == nil {
+		return override, overrideVersions
+	}
+
+	ps := &tfjson.ProviderSchemas{
+		FormatVersion: base.FormatVersion,
+		Schemas:       make(map[string]*tfjson.ProviderSchema, len(base.Schemas)),
+	}
+	vOut := VersionOutput{
+		Core:      baseVersions.Core,
+		Providers: make(map[string]*version.Version, len(baseVersions.Providers)),
+	}
+
+	for addr, schema := range base.Schemas {
+		ps.Schemas[addr] = schema
+	}
+	for addr, v := range baseVersions.Providers {
+		vOut.Providers[addr] = v
+	}
+
+	for addr, schema := range override.Schemas {
+		ps.Schemas[addr] = schema
+		delete(vOut.Providers, addr)
+		if v, ok := overrideVersions.Providers[addr]; ok {
+			vOut.Providers[addr] = v
+		}
+	}
+
+	return ps, vOut
+}
+
+func schemaFilesAtPath(path string) ([]string, error) {
+	fi, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if !fi.IsDir() {
+		return []string{path}, nil
+	}
+
+	infos, err := ioutil.ReadDir(path)
+	if err != nil {
+		return nil, err
+	}
+
+	files := make([]string, 0)
+	for _, info := range infos {
+		name := info.Name()
+		if info.IsDir() || !strings.HasSuffix(name, ".json") || name == VersionsFileName {
+			continue
+		}
+		files = append(files, filepath.Join(path, name))
+	}
+	sort.Strings(files)
+
+	return files, nil
+}
+
+// readVersionsFile reads provider versions from the given file,
+// returning no versions if the file does not exist
+func readVersionsFile(path string) (map[string]*version.Version, error) {
+	versions := make(map[string]*version.Version, 0)
+
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return versions, nil
+		}
+		return nil, err
+	}
+
+	output := &RawVersionOutput{}
+	err = json.Unmarshal(b, output)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	for addr, rawVersion := range output.Providers {
+		v, err := version.NewVersion(rawVersion)
+		if err != nil {
+			return nil, fmt.Errorf("%s: invalid version of %s: %w", path, addr, err)
+		}
+		versions[addr] = v
+	}
+
+	return versions, nil
+}
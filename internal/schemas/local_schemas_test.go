@@ -0,0 +1,163 @@
+package schemas
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/hashicorp/go-version"
+	tfjson "github.com/hashicorp/terraform-json"
+)
+
+func TestLocalProviderSchemas_Load(t *testing.T) {
+	dir, err := ioutil.TempDir("", "local-schemas")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	writeFile(t, filepath.Join(dir, "aws.json"), `{
+  "format_version": "0.1",
+  "provider_schemas": {
+    "registry.terraform.io/hashicorp/aws": {
+      "provider": {"version": 0, "block": {"attributes": {"region": {"type": "string", "optional": true}}}}
+    }
+  }
+}`)
+	writeFile(t, filepath.Join(dir, VersionsFileName), `{
+  "providers": {
+    "registry.terraform.io/hashicorp/aws": "3.20.0"
+  }
+}`)
+
+	otherDir := filepath.Join(dir, "other")
+	err = os.Mkdir(otherDir, 0755)
+	if err != nil {
+		t.Fatal(err)
+	}
+	nullPath := filepath.Join(otherDir, "schema.json")
+	writeFile(t, nullPath, `{
+  "format_version": "0.1",
+  "provider_schemas": {
+    "registry.terraform.io/hashicorp/null": {}
+  }
+}`)
+
+	s := NewLocalProviderSchemas()
+	err = s.Load([]string{dir, nullPath, filepath.Join(dir, "missing.json")})
+	if err == nil {
+		t.Fatal("expected error for missing file")
+	}
+
+	ps, vOut := s.ProviderSchemas()
+	if len(ps.Schemas) != 2 {
+		t.Fatalf("expected 2 schemas, given %d", len(ps.Schemas))
+	}
+	if ps.Schemas["registry.terraform.io/hashicorp/aws"].ConfigSchema.Block.Attributes["region"] == nil {
+		t.Fatal("expected region attribute in aws provider schema")
+	}
+
+	expectedVersions := map[string]*version.Version{
+		"registry.terraform.io/hashicorp/aws": version.Must(version.NewVersion("3.20.0")),
+	}
+	if diff := cmp.Diff(expectedVersions, vOut.Providers); diff != "" {
+		t.Fatalf("versions mismatch: %s", diff)
+	}
+}
+
+func TestLocalProviderSchemas_ProviderSchemasForConstraints(t *testing.T) {
+	addr := "registry.terraform.io/hashicorp/aws"
+	s := NewLocalProviderSchemas()
+	for _, v := range []string{"2.70.0", "3.20.0", "2.50.0"} {
+		s.add(&ProviderSchema{
+			Address: addr,
+			Version: version.Must(version.NewVersion(v)),
+			Schema:  &tfjson.ProviderSchema{},
+		})
+	}
+	s.add(&ProviderSchema{
+		Address: addr,
+		Schema:  &tfjson.ProviderSchema{},
+	})
+
+	testCases := []struct {
+		name            string
+		constraint      string
+		expectedVersion string
+	}{
+		{"unconstrained", "", "3.20.0"},
+		{"pinned to older major version", "~> 2.0", "2.70.0"},
+		{"pinned to exact version", "2.50.0", "2.50.0"},
+		{"unknown version of known major version", "2.60.0", "2.70.0"},
+		{"unknown major version", "~> 4.0", "3.20.0"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var constraints map[string]version.Constraints
+			if tc.constraint != "" {
+				c, err := version.NewConstraint(tc.constraint)
+				if err != nil {
+					t.Fatal(err)
+				}
+				constraints = map[string]version.Constraints{addr: c}
+			}
+
+			ps, vOut := s.ProviderSchemasForConstraints(constraints)
+			if len(ps.Schemas) != 1 {
+				t.Fatalf("expected 1 schema, given %d", len(ps.Schemas))
+			}
+			expectedVersion := version.Must(version.NewVersion(tc.expectedVersion))
+			if !expectedVersion.Equal(vOut.Providers[addr]) {
+				t.Fatalf("expected version %s, given %s", expectedVersion, vOut.Providers[addr])
+			}
+		})
+	}
+}
+
+func TestMergeProviderSchemas(t *testing.T) {
+	base := &tfjson.ProviderSchemas{
+		FormatVersion: "0.1",
+		Schemas: map[string]*tfjson.ProviderSchema{
+			"registry.terraform.io/hashicorp/aws":  {},
+			"registry.terraform.io/hashicorp/null": {},
+		},
+	}
+	baseVersions := VersionOutput{
+		Providers: map[string]*version.Version{
+			"registry.terraform.io/hashicorp/aws":  version.Must(version.NewVersion("3.0.0")),
+			"registry.terraform.io/hashicorp/null": version.Must(version.NewVersion("3.0.0")),
+		},
+	}
+	override := &tfjson.ProviderSchemas{
+		FormatVersion: "0.1",
+		Schemas: map[string]*tfjson.ProviderSchema{
+			"registry.terraform.io/hashicorp/aws": {},
+			"example.com/corp/internal":           {},
+		},
+	}
+
+	ps, vOut := MergeProviderSchemas(base, baseVersions, override, VersionOutput{})
+	if len(ps.Schemas) != 3 {
+		t.Fatalf("expected 3 schemas, given %d", len(ps.Schemas))
+	}
+	if ps.Schemas["registry.terraform.io/hashicorp/aws"] != override.Schemas["registry.terraform.io/hashicorp/aws"] {
+		t.Fatal("expected overridden aws schema")
+	}
+
+	expectedVersions := map[string]*version.Version{
+		"registry.terraform.io/hashicorp/null": version.Must(version.NewVersion("3.0.0")),
+	}
+	if diff := cmp.Diff(expectedVersions, vOut.Providers); diff != "" {
+		t.Fatalf("versions mismatch: %s", diff)
+	}
+}
+
+func writeFile(t *testing.T, path, content string) {
+	err := ioutil.WriteFile(path, []byte(content), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
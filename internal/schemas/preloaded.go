@@ -0,0 +1,258 @@
+package schemas
+
+import (
+	"encoding/json"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/hashicorp/go-version"
+	tfjson "github.com/hashicorp/terraform-json"
+)
+
+const (
+	preloadedSchemasFile  = "schemas.json"
+	preloadedVersionsFile = "versions.json"
+)
+
+// preloadedGeneration represents one set of preloaded provider schemas.
+//
+// The embedded data holds several generations in numbered directories,
+// where the first one (0) contains the latest version of each provider
+// and every following one contains the previous major version
+// of those providers which have one.
+type preloadedGeneration struct {
+	versions VersionOutput
+
+	load       func() (*tfjson.ProviderSchemas, error)
+	loadOnce   sync.Once
+	schemas    *tfjson.ProviderSchemas
+	schemasErr error
+}
+
+// providerSchemas lazily decodes schemas of the generation,
+// so that older generations are only decoded when requested
+func (g *preloadedGeneration) providerSchemas() (*tfjson.ProviderSchemas, error) {
+	g.loadOnce.Do(func() {
+		g.schemas, g.schemasErr = g.load()
+	})
+	return g.schemas, g.schemasErr
+}
+
+var (
+	_preloadedGenerations     []*preloadedGeneration
+	_preloadedGenerationsOnce sync.Once
+	_preloadedGenerationsErr  error
+)
+
+func preloadedGenerations() ([]*preloadedGeneration, error) {
+	_preloadedGenerationsOnce.Do(func() {
+		_preloadedGenerations = make([]*preloadedGeneration, 0)
+		for i := 0; ; i++ {
+			dir := strconv.Itoa(i)
+
+			vOut, err := readPreloadedVersions(path.Join(dir, preloadedVersionsFile))
+			if err != nil {
+				if !os.IsNotExist(err) {
+					_preloadedGenerationsErr = err
+				}
+				return
+			}
+
+			schemasPath := path.Join(dir, preloadedSchemasFile)
+			_preloadedGenerations = append(_preloadedGenerations, &preloadedGeneration{
+				versions: vOut,
+				load: func() (*tfjson.ProviderSchemas, error) {
+					return readPreloadedSchemas(schemasPath)
+				},
+			})
+		}
+	})
+
+	return _preloadedGenerations, _preloadedGenerationsErr
+}
+
+func readPreloadedSchemas(name string) (*tfjson.ProviderSchemas, error) {
+	f, err := openPreloadedFile(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	ps := &tfjson.ProviderSchemas{}
+	err = json.NewDecoder(f).Decode(ps)
+	if err != nil {
+		return nil, err
+	}
+
+	return ps, nil
+}
+
+func readPreloadedVersions(name string) (VersionOutput, error) {
+	f, err := openPreloadedFile(name)
+	if err != nil {
+		return VersionOutput{}, err
+	}
+	defer f.Close()
+
+	output := &RawVersionOutput{}
+	err = json.NewDecoder(f).Decode(output)
+	if err != nil {
+		return VersionOutput{}, err
+	}
+
+	coreVersion, err := version.NewVersion(output.CoreVersion)
+	if err != nil {
+		return VersionOutput{}, err
+	}
+
+	pVersions := make(map[string]*version.Version, 0)
+	for addr, versionString := range output.Providers {
+		v, err := version.NewVersion(versionString)
+		if err != nil {
+			return VersionOutput{}, err
+		}
+		pVersions[addr] = v
+	}
+
+	return VersionOutput{
+		Core:      coreVersion,
+		Providers: pVersions,
+	}, nil
+}
+
+// PreloadedCoreVersion returns version of Terraform which the latest
+// preloaded schemas were obtained with, i.e. the newest known release
+func PreloadedCoreVersion() (*version.Version, bool) {
+	gens, err := preloadedGenerations()
+	if err != nil || len(gens) == 0 {
+		return nil, false
+	}
+	return gens[0].versions.Core, gens[0].versions.Core != nil
+}
+
+// PreloadedProviderSchemas returns the latest preloaded version
+// of schema for each provider
+func PreloadedProviderSchemas() (*tfjson.ProviderSchemas, VersionOutput, error) {
+	return PreloadedProviderSchemasForConstraints(nil)
+}
+
+// PreloadedProviderSchemasForConstraints returns preloaded schemas,
+// where the newest preloaded version satisfying the given constraints
+// (keyed by provider address) is chosen for each constrained provider.
+// The latest version is used for any other providers.
+func PreloadedProviderSchemasForConstraints(constraints map[string]version.Constraints) (*tfjson.ProviderSchemas, VersionOutput, error) {
+	gens, err := preloadedGenerations()
+	if err != nil {
+		return nil, VersionOutput{}, err
+	}
+
+	return selectProviderSchemas(gens, constraints)
+}
+
+func selectProviderSchemas(gens []*preloadedGeneration, constraints map[string]version.Constraints) (*tfjson.ProviderSchemas, VersionOutput, error) {
+	if len(gens) == 0 {
+		return nil, VersionOutput{}, nil
+	}
+
+	latest := gens[0]
+	latestPs, err := latest.providerSchemas()
+	if err != nil {
+		return nil, VersionOutput{}, err
+	}
+	if len(constraints) == 0 || len(gens) == 1 {
+		return latestPs, latest.versions, nil
+	}
+
+	ps := &tfjson.ProviderSchemas{
+		FormatVersion: latestPs.FormatVersion,
+		Schemas:       make(map[string]*tfjson.ProviderSchema, len(latestPs.Schemas)),
+	}
+	for addr, schema := range latestPs.Schemas {
+		ps.Schemas[addr] = schema
+	}
+	vOut := VersionOutput{
+		Core:      latest.versions.Core,
+		Providers: make(map[string]*version.Version, len(latest.versions.Providers)),
+	}
+	for addr, v := range latest.versions.Providers {
+		vOut.Providers[addr] = v
+	}
+
+	for addr, c := range constraints {
+		i, ok := selectGeneration(gens, addr, c)
+		if !ok || i == 0 {
+			continue
+		}
+
+		genPs, err := gens[i].providerSchemas()
+		if err != nil {
+			return nil, VersionOutput{}, err
+		}
+		schema, ok := genPs.Schemas[addr]
+		if !ok {
+			continue
+		}
+
+		ps.Schemas[addr] = schema
+		vOut.Providers[addr] = gens[i].versions.Providers[addr]
+	}
+
+	return ps, vOut, nil
+}
+
+// selectGeneration returns index of the generation with the newest version
+// of the provider satisfying the constraints. If there is no such version
+// (e.g. as the constraint pins a version which is not preloaded),
+// the newest version with the same major version is chosen instead.
+func selectGeneration(gens []*preloadedGeneration, addr string, c version.Constraints) (int, bool) {
+	versions := make([]*version.Version, len(gens))
+	for i, gen := range gens {
+		versions[i] = gen.versions.Providers[addr]
+	}
+	return selectVersion(versions, c)
+}
+
+// selectVersion returns index of the first of the given versions
+// (ordered from newest) which satisfies the constraints, or if there
+// is none, the first one with a major version allowed by the constraints.
+// Nil versions (i.e. unknown or missing ones) are never selected.
+func selectVersion(versions []*version.Version, c version.Constraints) (int, bool) {
+	for i, v := range versions {
+		if v != nil && c.Check(v) {
+			return i, true
+		}
+	}
+
+	majors := constraintMajorVersions(c)
+	for i, v := range versions {
+		if v == nil {
+			continue
+		}
+		if _, ok := majors[v.Segments64()[0]]; ok {
+			return i, true
+		}
+	}
+
+	return 0, false
+}
+
+// constraintMajorVersions returns major versions of all versions
+// which the given constraints allow or require
+func constraintMajorVersions(c version.Constraints) map[int64]struct{} {
+	majors := make(map[int64]struct{}, 0)
+	for _, constraint := range c {
+		raw := strings.TrimSpace(constraint.String())
+		if strings.HasPrefix(raw, "<") || strings.HasPrefix(raw, "!") {
+			continue
+		}
+		v, err := version.NewVersion(strings.TrimLeft(raw, "=>~ "))
+		if err != nil {
+			continue
+		}
+		majors[v.Segments64()[0]] = struct{}{}
+	}
+	return majors
+}
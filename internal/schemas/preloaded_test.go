@@ -0,0 +1,132 @@
+package schemas
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/hashicorp/go-version"
+	tfjson "github.com/hashicorp/terraform-json"
+)
+
+func TestSelectProviderSchemas(t *testing.T) {
+	gens := []*preloadedGeneration{
+		testGeneration(map[string]string{
+			"registry.terraform.io/hashicorp/aws":    "3.22.0",
+			"registry.terraform.io/hashicorp/google": "3.51.0",
+		}),
+		testGeneration(map[string]string{
+			"registry.terraform.io/hashicorp/aws":    "2.70.0",
+			"registry.terraform.io/hashicorp/google": "2.20.3",
+		}),
+		testGeneration(map[string]string{
+			"registry.terraform.io/hashicorp/aws": "1.60.0",
+		}),
+	}
+
+	testCases := []struct {
+		name             string
+		constraints      map[string]string
+		expectedVersions map[string]string
+	}{
+		{
+			"no constraints",
+			map[string]string{},
+			map[string]string{
+				"registry.terraform.io/hashicorp/aws":    "3.22.0",
+				"registry.terraform.io/hashicorp/google": "3.51.0",
+			},
+		},
+		{
+			"older major versions",
+			map[string]string{
+				"registry.terraform.io/hashicorp/aws":    "~> 1.0",
+				"registry.terraform.io/hashicorp/google": ">= 2.0, < 3.0",
+			},
+			map[string]string{
+				"registry.terraform.io/hashicorp/aws":    "1.60.0",
+				"registry.terraform.io/hashicorp/google": "2.20.3",
+			},
+		},
+		{
+			"pinned version which is not preloaded",
+			map[string]string{
+				"registry.terraform.io/hashicorp/aws": "2.50.0",
+			},
+			map[string]string{
+				"registry.terraform.io/hashicorp/aws":    "2.70.0",
+				"registry.terraform.io/hashicorp/google": "3.51.0",
+			},
+		},
+		{
+			"unsatisfiable constraint",
+			map[string]string{
+				"registry.terraform.io/hashicorp/google": "< 1.0",
+			},
+			map[string]string{
+				"registry.terraform.io/hashicorp/aws":    "3.22.0",
+				"registry.terraform.io/hashicorp/google": "3.51.0",
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			constraints := make(map[string]version.Constraints, 0)
+			for addr, raw := range tc.constraints {
+				c, err := version.NewConstraint(raw)
+				if err != nil {
+					t.Fatal(err)
+				}
+				constraints[addr] = c
+			}
+
+			ps, vOut, err := selectProviderSchemas(gens, constraints)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			givenVersions := make(map[string]string, 0)
+			for addr, v := range vOut.Providers {
+				givenVersions[addr] = v.String()
+			}
+			if diff := cmp.Diff(tc.expectedVersions, givenVersions); diff != "" {
+				t.Fatalf("versions mismatch: %s", diff)
+			}
+
+			// test schemas carry their version as description
+			givenSchemaVersions := make(map[string]string, 0)
+			for addr, schema := range ps.Schemas {
+				givenSchemaVersions[addr] = schema.ConfigSchema.Block.Description
+			}
+			if diff := cmp.Diff(tc.expectedVersions, givenSchemaVersions); diff != "" {
+				t.Fatalf("schemas mismatch: %s", diff)
+			}
+		})
+	}
+}
+
+func testGeneration(versions map[string]string) *preloadedGeneration {
+	vOut := VersionOutput{
+		Core:      version.Must(version.NewVersion("0.14.3")),
+		Providers: make(map[string]*version.Version, 0),
+	}
+	ps := &tfjson.ProviderSchemas{
+		FormatVersion: "0.1",
+		Schemas:       make(map[string]*tfjson.ProviderSchema, 0),
+	}
+	for addr, v := range versions {
+		vOut.Providers[addr] = version.Must(version.NewVersion(v))
+		ps.Schemas[addr] = &tfjson.ProviderSchema{
+			ConfigSchema: &tfjson.Schema{
+				Block: &tfjson.SchemaBlock{Description: v},
+			},
+		}
+	}
+
+	return &preloadedGeneration{
+		versions: vOut,
+		load: func() (*tfjson.ProviderSchemas, error) {
+			return ps, nil
+		},
+	}
+}
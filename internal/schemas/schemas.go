@@ -2,8 +2,11 @@
 
 package schemas
 
-import tfjson "github.com/hashicorp/terraform-json"
+import (
+	"io"
+	"os"
+)
 
-func PreloadedProviderSchemas() (*tfjson.ProviderSchemas, VersionOutput, error) {
-	return nil, VersionOutput{}, nil
+func openPreloadedFile(name string) (io.ReadCloser, error) {
+	return nil, &os.PathError{Op: "open", Path: name, Err: os.ErrNotExist}
 }
@@ -10,12 +10,38 @@ type ExperimentalFeatures struct {
 	ValidateOnSave bool `mapstructure:"validateOnSave"`
 }
 
+type ValidationOptions struct {
+	// IgnoreUnusedDeclarations describes a list of paths to modules
+	// in which unused declarations should not be reported
+	IgnoreUnusedDeclarations []string `mapstructure:"ignoreUnusedDeclarations"`
+}
+
+type CompletionOptions struct {
+	// IncludeOptionalAttributes describes whether snippets
+	// of whole blocks should contain optional attributes
+	IncludeOptionalAttributes bool `mapstructure:"includeOptionalAttributes"`
+}
+
 type Options struct {
 	// ModulePaths describes a list of absolute paths to modules to load
 	ModulePaths        []string `mapstructure:"rootModulePaths"`
 	ExcludeModulePaths []string `mapstructure:"excludeModulePaths"`
 	CommandPrefix      string   `mapstructure:"commandPrefix"`
 
+	// IgnorePaths describes a list of .gitignore-style patterns
+	// of paths to skip during automatic module discovery
+	IgnorePaths []string `mapstructure:"ignorePaths"`
+
+	// ProviderSchemaPaths describes a list of paths to JSON files
+	// (or directories of such files) with provider schemas
+	ProviderSchemaPaths []string `mapstructure:"providerSchemaPaths"`
+
+	// Validation encapsulates options for validation done by the server itself
+	Validation ValidationOptions `mapstructure:"validation"`
+
+	// Completion encapsulates options for completion
+	Completion CompletionOptions `mapstructure:"completion"`
+
 	// ExperimentalFeatures encapsulates experimental features users can opt into.
 	ExperimentalFeatures ExperimentalFeatures `mapstructure:"experimentalFeatures"`
 
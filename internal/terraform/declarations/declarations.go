@@ -0,0 +1,150 @@
+// Package declarations decodes named objects (variables, locals, resources etc.)
+// and references between them from parsed Terraform configuration files
+package declarations
+
+import (
+	"sort"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// Declaration represents a single named object declared
+// within a module, such as a variable, local value or resource
+type Declaration struct {
+	Name      string
+	DeclRange hcl.Range
+
+	// Block is the block in which the object is declared
+	// (this is the whole locals block in case of local values)
+	Block *hclsyntax.Block
+
+	// Attribute is only set for local values
+	Attribute *hclsyntax.Attribute
+}
+
+// StringAttribute returns value of the attribute of the given name
+// within the declaring block, if it is a static string
+func (d *Declaration) StringAttribute(name string) (string, bool) {
+	if d.Block == nil {
+		return "", false
+	}
+	attr, ok := d.Block.Body.Attributes[name]
+	if !ok {
+		return "", false
+	}
+	val, diags := attr.Expr.Value(nil)
+	if diags.HasErrors() || !val.Type().Equals(cty.String) || !val.IsKnown() || val.IsNull() {
+		return "", false
+	}
+	return val.AsString(), true
+}
+
+// Module represents all objects declared within a single module
+type Module struct {
+	Variables   map[string]*Declaration
+	Locals      map[string]*Declaration
+	Outputs     map[string]*Declaration
+	ModuleCalls map[string]*Declaration
+
+	// Resources and DataSources are keyed by "<type>.<name>"
+	Resources   map[string]*Declaration
+	DataSources map[string]*Declaration
+}
+
+func newModule() *Module {
+	return &Module{
+		Variables:   make(map[string]*Declaration, 0),
+		Locals:      make(map[string]*Declaration, 0),
+		Outputs:     make(map[string]*Declaration, 0),
+		ModuleCalls: make(map[string]*Declaration, 0),
+		Resources:   make(map[string]*Declaration, 0),
+		DataSources: make(map[string]*Declaration, 0),
+	}
+}
+
+// Decode collects all declarations from the given parsed files
+// where key is a filename.
+//
+// Blocks with unexpected number of labels are ignored.
+func Decode(files map[string]*hcl.File) *Module {
+	mod := newModule()
+
+	for _, filename := range SortedFilenames(files) {
+		body, ok := files[filename].Body.(*hclsyntax.Body)
+		if !ok {
+			continue
+		}
+
+		for _, block := range body.Blocks {
+			switch block.Type {
+			case "variable":
+				if len(block.Labels) == 1 {
+					mod.Variables[block.Labels[0]] = blockDeclaration(block.Labels[0], block)
+				}
+			case "output":
+				if len(block.Labels) == 1 {
+					mod.Outputs[block.Labels[0]] = blockDeclaration(block.Labels[0], block)
+				}
+			case "module":
+				if len(block.Labels) == 1 {
+					mod.ModuleCalls[block.Labels[0]] = blockDeclaration(block.Labels[0], block)
+				}
+			case "resource":
+				if len(block.Labels) == 2 {
+					addr := block.Labels[0] + "." + block.Labels[1]
+					mod.Resources[addr] = blockDeclaration(addr, block)
+				}
+			case "data":
+				if len(block.Labels) == 2 {
+					addr := block.Labels[0] + "." + block.Labels[1]
+					mod.DataSources[addr] = blockDeclaration(addr, block)
+				}
+			case "locals":
+				for _, attr := range SortedAttributes(block.Body) {
+					mod.Locals[attr.Name] = &Declaration{
+						Name:      attr.Name,
+						DeclRange: attr.NameRange,
+						Block:     block,
+						Attribute: attr,
+					}
+				}
+			}
+		}
+	}
+
+	return mod
+}
+
+func blockDeclaration(name string, block *hclsyntax.Block) *Declaration {
+	return &Declaration{
+		Name:      name,
+		DeclRange: block.DefRange(),
+		Block:     block,
+	}
+}
+
+// SortedFilenames returns names of the given files in
+// alphabetical order to allow deterministic processing
+func SortedFilenames(files map[string]*hcl.File) []string {
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// SortedAttributes returns attributes of the given body
+// in the order in which they appear in the file
+func SortedAttributes(body *hclsyntax.Body) []*hclsyntax.Attribute {
+	attrs := make([]*hclsyntax.Attribute, 0, len(body.Attributes))
+	for _, attr := range body.Attributes {
+		attrs = append(attrs, attr)
+	}
+	sort.Slice(attrs, func(i, j int) bool {
+		return attrs[i].SrcRange.Start.Byte < attrs[j].SrcRange.Start.Byte
+	})
+	return attrs
+}
@@ -0,0 +1,110 @@
+package declarations
+
+import (
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+)
+
+// Reference represents a traversal found in an expression
+type Reference struct {
+	Traversal hcl.Traversal
+
+	// Block is the top-level block in which the reference was found
+	Block *hclsyntax.Block
+}
+
+// Range returns the source range of the reference
+func (r Reference) Range() hcl.Range {
+	return r.Traversal.SourceRange()
+}
+
+// DecodeReferences collects references from all expressions
+// in the given files where key is a filename.
+//
+// Traversals which are not references to other objects
+// (e.g. provider references, type constraints, lifecycle
+// attribute paths or dynamic block iterators) are omitted.
+func DecodeReferences(files map[string]*hcl.File) []Reference {
+	refs := make([]Reference, 0)
+
+	for _, filename := range SortedFilenames(files) {
+		body, ok := files[filename].Body.(*hclsyntax.Body)
+		if !ok {
+			continue
+		}
+
+		for _, block := range body.Blocks {
+			if block.Type == "terraform" {
+				continue
+			}
+			refs = append(refs, referencesInBody(block, block.Body, map[string]bool{})...)
+		}
+	}
+
+	return refs
+}
+
+func referencesInBody(topBlock *hclsyntax.Block, body *hclsyntax.Body, iterators map[string]bool) []Reference {
+	refs := make([]Reference, 0)
+	isTopLevel := body == topBlock.Body
+
+	for _, attr := range SortedAttributes(body) {
+		if isTopLevel && isNonReferenceAttribute(topBlock.Type, attr.Name) {
+			continue
+		}
+
+		for _, traversal := range hclsyntax.Variables(attr.Expr) {
+			if iterators[traversal.RootName()] {
+				continue
+			}
+			refs = append(refs, Reference{
+				Traversal: traversal,
+				Block:     topBlock,
+			})
+		}
+	}
+
+	for _, block := range body.Blocks {
+		if isTopLevel && block.Type == "lifecycle" {
+			continue
+		}
+
+		blockIterators := iterators
+		if block.Type == "dynamic" && len(block.Labels) == 1 {
+			blockIterators = make(map[string]bool, len(iterators)+1)
+			for name := range iterators {
+				blockIterators[name] = true
+			}
+			blockIterators[dynamicIteratorName(block)] = true
+		}
+
+		refs = append(refs, referencesInBody(topBlock, block.Body, blockIterators)...)
+	}
+
+	return refs
+}
+
+// isNonReferenceAttribute returns true for top-level attributes
+// which contain traversals which do not refer to any declared objects
+func isNonReferenceAttribute(blockType, attrName string) bool {
+	switch blockType {
+	case "resource", "data":
+		return attrName == "provider"
+	case "module":
+		return attrName == "providers"
+	case "variable":
+		return attrName == "type"
+	}
+	return false
+}
+
+func dynamicIteratorName(block *hclsyntax.Block) string {
+	attr, ok := block.Body.Attributes["iterator"]
+	if ok {
+		traversal, diags := hcl.AbsTraversalForExpr(attr.Expr)
+		if !diags.HasErrors() && len(traversal) == 1 {
+			return traversal.RootName()
+		}
+	}
+	return block.Labels[0]
+}
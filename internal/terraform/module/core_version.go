@@ -0,0 +1,196 @@
+package module
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/go-version"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/hashicorp/terraform-ls/internal/filesystem"
+	tfschema "github.com/hashicorp/terraform-schema/schema"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// terraformVersionFile is the name of the file used by version managers
+// such as tfenv to pin Terraform version for a directory tree
+const terraformVersionFile = ".terraform-version"
+
+// newestSatisfyingCoreVersion returns the newest candidate version
+// of Terraform which satisfies all the given constraints, where the newest
+// known release (if any) is considered along with candidates derived
+// from the constraints themselves
+func newestSatisfyingCoreVersion(constraints []version.Constraints, newestKnown *version.Version) (*version.Version, bool) {
+	for _, v := range coreVersionCandidates(constraints, newestKnown) {
+		if satisfiesAll(v, constraints) {
+			return v, true
+		}
+	}
+	return nil, false
+}
+
+// coreVersionCandidates returns versions which the given constraints
+// refer to, along with the first release of their minor version and
+// of the previous minor version (as in "< 0.14" being satisfied by 0.13),
+// ordered from the newest. Only versions which terraform-schema
+// provides a core schema for are returned.
+func coreVersionCandidates(constraints []version.Constraints, newestKnown *version.Version) []*version.Version {
+	candidates := make(map[string]*version.Version, 0)
+	add := func(raw string) {
+		v, err := version.NewVersion(raw)
+		if err != nil {
+			return
+		}
+		if _, err := tfschema.CoreModuleSchemaForVersion(v); err != nil {
+			return
+		}
+		candidates[v.String()] = v
+	}
+
+	if newestKnown != nil {
+		add(newestKnown.String())
+	}
+	for _, c := range constraints {
+		for _, constraint := range c {
+			raw := strings.TrimLeft(strings.TrimSpace(constraint.String()), "=!<>~ ")
+			v, err := version.NewVersion(raw)
+			if err != nil {
+				continue
+			}
+			add(v.String())
+
+			segments := v.Segments64()
+			add(fmt.Sprintf("%d.%d.0", segments[0], segments[1]))
+			if segments[1] > 0 {
+				add(fmt.Sprintf("%d.%d.0", segments[0], segments[1]-1))
+			}
+		}
+	}
+
+	sorted := make([]*version.Version, 0, len(candidates))
+	for _, v := range candidates {
+		sorted = append(sorted, v)
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].GreaterThan(sorted[j])
+	})
+	return sorted
+}
+
+func satisfiesAll(v *version.Version, constraints []version.Constraints) bool {
+	for _, c := range constraints {
+		if !c.Check(v) {
+			return false
+		}
+	}
+	return true
+}
+
+// requiredVersionConstraints returns all valid required_version
+// constraints declared in the given parsed files
+func requiredVersionConstraints(files map[string]*hcl.File) []version.Constraints {
+	constraints := make([]version.Constraints, 0)
+
+	for _, f := range files {
+		body, ok := f.Body.(*hclsyntax.Body)
+		if !ok {
+			continue
+		}
+		for _, block := range body.Blocks {
+			if block.Type != "terraform" {
+				continue
+			}
+			attr, ok := block.Body.Attributes["required_version"]
+			if !ok {
+				continue
+			}
+			val, diags := attr.Expr.Value(nil)
+			if diags.HasErrors() || !val.IsWhollyKnown() || val.IsNull() || val.Type() != cty.String {
+				continue
+			}
+			c, err := version.NewConstraint(val.AsString())
+			if err != nil {
+				continue
+			}
+			constraints = append(constraints, c)
+		}
+	}
+
+	return constraints
+}
+
+// findTerraformVersionFile looks for .terraform-version file
+// in the given directory and all its parent directories
+func findTerraformVersionFile(fs filesystem.Filesystem, dir string) (string, bool) {
+	dir = filepath.Clean(dir)
+	for {
+		path := filepath.Join(dir, terraformVersionFile)
+		if _, err := fs.ReadFile(path); err == nil {
+			return path, true
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", false
+		}
+		dir = parent
+	}
+}
+
+// parseTerraformVersionFile returns the version constraint represented
+// by the content of .terraform-version file.
+//
+// Only exact versions are supported, since other values such as "latest"
+// or "min-required" depend on the version manager and available releases.
+func parseTerraformVersionFile(src []byte) (version.Constraints, error) {
+	raw := strings.TrimSpace(string(src))
+	if raw == "" {
+		return nil, fmt.Errorf("empty %s", terraformVersionFile)
+	}
+	v, err := version.NewVersion(strings.TrimPrefix(raw, "v"))
+	if err != nil {
+		return nil, fmt.Errorf("unsupported %s: %w", terraformVersionFile, err)
+	}
+	return version.NewConstraint("= " + v.String())
+}
+
+// discoverTerraformVersionFile finds .terraform-version file applicable
+// to the module once, such that it can be watched for changes
+// rather than looked up again on every parse
+func (m *module) discoverTerraformVersionFile(dir string) {
+	path, _ := findTerraformVersionFile(m.filesystem, dir)
+
+	m.coreSchemaMu.Lock()
+	defer m.coreSchemaMu.Unlock()
+	m.tfVersionFilePath = path
+}
+
+// versionFileConstraints returns constraints from .terraform-version
+// file applicable to the module (if any)
+func (m *module) versionFileConstraints() []version.Constraints {
+	m.coreSchemaMu.RLock()
+	path := m.tfVersionFilePath
+	m.coreSchemaMu.RUnlock()
+	if path == "" {
+		return nil
+	}
+
+	src, err := m.filesystem.ReadFile(path)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			m.logger.Printf("failed to read %s: %s", path, err)
+		}
+		return nil
+	}
+
+	c, err := parseTerraformVersionFile(src)
+	if err != nil {
+		m.logger.Printf("ignoring %s: %s", path, err)
+		return nil
+	}
+
+	return []version.Constraints{c}
+}
@@ -0,0 +1,134 @@
+package module
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hashicorp/go-version"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/hashicorp/terraform-ls/internal/filesystem"
+)
+
+func TestNewestSatisfyingCoreVersion(t *testing.T) {
+	newestKnown := version.Must(version.NewVersion("0.14.3"))
+
+	testCases := []struct {
+		cfg             string
+		versionFile     string
+		newestKnown     *version.Version
+		expectedVersion string
+	}{
+		{`required_version = ">= 0.12"`, "", newestKnown, "0.14.3"},
+		{`required_version = ">= 0.12"`, "", nil, "0.12.0"},
+		{`required_version = "~> 0.12.0"`, "", newestKnown, "0.12.0"},
+		{`required_version = ">= 0.12, < 0.14"`, "", newestKnown, "0.13.0"},
+		{`required_version = ">= 0.13"`, "0.13.4", newestKnown, "0.13.4"},
+		{`required_version = "~> 0.12.0"`, "v0.12.20", newestKnown, "0.12.20"},
+		{`required_version = ">= 0.14"`, "0.13.4", newestKnown, ""},
+		{`required_version = "0.11.14"`, "", newestKnown, ""},
+	}
+
+	for _, tc := range testCases {
+		t.Run(fmt.Sprintf("%s %s %s", tc.cfg, tc.versionFile, tc.newestKnown), func(t *testing.T) {
+			src := "terraform {\n  " + tc.cfg + "\n}\n"
+			f, diags := hclsyntax.ParseConfig([]byte(src), "main.tf", hcl.InitialPos)
+			if diags.HasErrors() {
+				t.Fatal(diags)
+			}
+
+			constraints := requiredVersionConstraints(map[string]*hcl.File{"main.tf": f})
+			if tc.versionFile != "" {
+				c, err := parseTerraformVersionFile([]byte(tc.versionFile + "\n"))
+				if err != nil {
+					t.Fatal(err)
+				}
+				constraints = append(constraints, c)
+			}
+
+			v, ok := newestSatisfyingCoreVersion(constraints, tc.newestKnown)
+			if tc.expectedVersion == "" {
+				if ok {
+					t.Fatalf("expected no version, given %s", v)
+				}
+				return
+			}
+			if !ok {
+				t.Fatal("expected version")
+			}
+			if !v.Equal(version.Must(version.NewVersion(tc.expectedVersion))) {
+				t.Fatalf("expected version %s, given %s", tc.expectedVersion, v)
+			}
+		})
+	}
+}
+
+func TestParseTerraformVersionFile_unsupported(t *testing.T) {
+	for _, src := range []string{"", "latest", "latest:^0.13", "min-required"} {
+		_, err := parseTerraformVersionFile([]byte(src))
+		if err == nil {
+			t.Fatalf("expected error for %q", src)
+		}
+	}
+}
+
+func TestModule_findCoreSchema_versionFile(t *testing.T) {
+	rootDir, err := ioutil.TempDir("", "tf-version-file")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(rootDir)
+
+	modPath := filepath.Join(rootDir, "module")
+	err = os.Mkdir(modPath, 0755)
+	if err != nil {
+		t.Fatal(err)
+	}
+	versionFilePath := filepath.Join(rootDir, terraformVersionFile)
+	err = ioutil.WriteFile(versionFilePath, []byte("0.13.4\n"), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = ioutil.WriteFile(filepath.Join(modPath, "main.tf"),
+		[]byte("terraform {\n  required_version = \">= 0.14\"\n}\n"), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mod := newModule(filesystem.NewFilesystem(), modPath)
+	mod.logger = testLogger()
+	err = mod.discoverCaches(context.Background(), modPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !mod.IsKnownTerraformVersionFile(versionFilePath) {
+		t.Fatalf("expected %q to be known version file", versionFilePath)
+	}
+	isWatched := false
+	for _, path := range mod.PathsToWatch() {
+		if path == versionFilePath {
+			isWatched = true
+		}
+	}
+	if !isWatched {
+		t.Fatalf("expected %q to be watched", versionFilePath)
+	}
+
+	_, err = mod.findCoreSchema()
+	if err == nil {
+		t.Fatal("expected unsatisfiable constraints to return error")
+	}
+
+	err = ioutil.WriteFile(versionFilePath, []byte("0.14.2\n"), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = mod.findCoreSchema()
+	if err != nil {
+		t.Fatal(err)
+	}
+}
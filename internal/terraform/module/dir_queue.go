@@ -0,0 +1,82 @@
+package module
+
+import (
+	"sync"
+)
+
+// dirQueue represents unbounded queue of directories to be walked,
+// which tracks directories in progress, so that it is considered
+// drained only when it's empty and no directory is in progress
+// (which could still add more directories)
+type dirQueue struct {
+	mu         *sync.Mutex
+	cond       *sync.Cond
+	dirs       []walkedDir
+	inProgress int
+	closed     bool
+}
+
+func newDirQueue() *dirQueue {
+	mu := &sync.Mutex{}
+	return &dirQueue{
+		mu:   mu,
+		cond: sync.NewCond(mu),
+		dirs: make([]walkedDir, 0),
+	}
+}
+
+func (q *dirQueue) Push(dirs ...walkedDir) {
+	if len(dirs) == 0 {
+		return
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.closed {
+		return
+	}
+	q.dirs = append(q.dirs, dirs...)
+	q.cond.Broadcast()
+}
+
+// Pop blocks until there is a directory to walk, or returns false
+// when the queue is drained or closed. Every popped directory
+// must be marked as finished via Done.
+func (q *dirQueue) Pop() (walkedDir, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	for len(q.dirs) == 0 && q.inProgress > 0 && !q.closed {
+		q.cond.Wait()
+	}
+
+	if q.closed || len(q.dirs) == 0 {
+		return walkedDir{}, false
+	}
+
+	// walk depth-first to keep the queue small
+	last := len(q.dirs) - 1
+	dir := q.dirs[last]
+	q.dirs = q.dirs[:last]
+	q.inProgress++
+
+	return dir, true
+}
+
+func (q *dirQueue) Done() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.inProgress--
+	q.cond.Broadcast()
+}
+
+func (q *dirQueue) Close() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.closed = true
+	q.dirs = nil
+	q.cond.Broadcast()
+}
@@ -22,3 +22,19 @@ func IsModuleNotFound(err error) bool {
 	_, ok := err.(*ModuleNotFoundErr)
 	return ok
 }
+
+type ModuleAlreadyAddedErr struct {
+	Dir string
+}
+
+func (e *ModuleAlreadyAddedErr) Error() string {
+	return fmt.Sprintf("module %s was already added", e.Dir)
+}
+
+func IsModuleAlreadyAdded(err error) bool {
+	if err == nil {
+		return false
+	}
+	_, ok := err.(*ModuleAlreadyAddedErr)
+	return ok
+}
@@ -0,0 +1,227 @@
+package module
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+const gitignoreFile = ".gitignore"
+
+// ignorePattern represents a single .gitignore-style pattern
+// applicable to paths within baseDir
+type ignorePattern struct {
+	baseDir string
+	re      *regexp.Regexp
+	negate  bool
+	dirOnly bool
+}
+
+// ignoreRules represents patterns in the order of precedence,
+// i.e. latter patterns override former ones
+type ignoreRules []ignorePattern
+
+// isIgnored returns true if the given absolute path is ignored,
+// either by itself or because any of its parent directories is ignored.
+// As with git, a path within an ignored directory cannot be
+// re-included by a negated pattern.
+func (r ignoreRules) isIgnored(path string, isDir bool) bool {
+	for dir := filepath.Dir(path); dir != filepath.Dir(dir); dir = filepath.Dir(dir) {
+		if r.matches(dir, true) {
+			return true
+		}
+	}
+	return r.matches(path, isDir)
+}
+
+// matches returns true if the last pattern matching
+// the given absolute path is not negated
+func (r ignoreRules) matches(path string, isDir bool) bool {
+	ignored := false
+	for _, p := range r {
+		if p.dirOnly && !isDir {
+			continue
+		}
+		rel, err := filepath.Rel(p.baseDir, path)
+		if err != nil || rel == "." || rel == ".." ||
+			strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			continue
+		}
+		if p.re.MatchString(filepath.ToSlash(rel)) {
+			ignored = !p.negate
+		}
+	}
+	return ignored
+}
+
+// parseIgnorePatterns parses patterns relative to the given directory,
+// skipping blank lines and comments
+func parseIgnorePatterns(baseDir string, lines []string) ignoreRules {
+	rules := make(ignoreRules, 0)
+	for _, line := range lines {
+		p, ok := parseIgnorePattern(baseDir, line)
+		if ok {
+			rules = append(rules, p)
+		}
+	}
+	return rules
+}
+
+// readGitignore reads patterns from .gitignore file
+// in the given directory (if any)
+func readGitignore(dir string) (ignoreRules, error) {
+	f, err := os.Open(filepath.Join(dir, gitignoreFile))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return ignoreRules{}, nil
+		}
+		return nil, err
+	}
+	defer f.Close()
+
+	lines := make([]string, 0)
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return parseIgnorePatterns(dir, lines), nil
+}
+
+func parseIgnorePattern(baseDir, line string) (ignorePattern, bool) {
+	line = trimTrailingSpaces(strings.TrimRight(line, "\r"))
+	if line == "" || strings.HasPrefix(line, "#") {
+		return ignorePattern{}, false
+	}
+
+	p := ignorePattern{baseDir: baseDir}
+	if strings.HasPrefix(line, "!") {
+		p.negate = true
+		line = line[1:]
+	}
+	if strings.HasSuffix(line, "/") {
+		p.dirOnly = true
+		line = strings.TrimRight(line, "/")
+	}
+	if line == "" {
+		return ignorePattern{}, false
+	}
+
+	var expr strings.Builder
+	expr.WriteString("^")
+	// patterns without a slash match at any level below base directory
+	if !strings.Contains(line, "/") {
+		expr.WriteString("(.*/)?")
+	}
+	line = strings.TrimPrefix(line, "/")
+
+	segments := strings.Split(line, "/")
+	for i, segment := range segments {
+		last := i == len(segments)-1
+		if segment == "**" {
+			if last {
+				// trailing "/**" matches everything inside
+				expr.WriteString(".*")
+			} else {
+				// leading "**/" or "/**/" matches zero or more directories
+				expr.WriteString("(.*/)?")
+			}
+			continue
+		}
+		expr.WriteString(globSegmentExpr(segment))
+		if !last {
+			expr.WriteString("/")
+		}
+	}
+	expr.WriteString("$")
+
+	re, err := regexp.Compile(expr.String())
+	if err != nil {
+		return ignorePattern{}, false
+	}
+	p.re = re
+
+	return p, true
+}
+
+// trimTrailingSpaces removes trailing spaces, unless escaped with a backslash
+func trimTrailingSpaces(line string) string {
+	for strings.HasSuffix(line, " ") && !strings.HasSuffix(line, `\ `) {
+		line = line[:len(line)-1]
+	}
+	return line
+}
+
+// globSegmentExpr translates a single path segment of a pattern,
+// where any asterisks (including "**") match within the segment only
+func globSegmentExpr(segment string) string {
+	var expr strings.Builder
+	for i := 0; i < len(segment); i++ {
+		c := segment[i]
+		switch c {
+		case '*':
+			for i+1 < len(segment) && segment[i+1] == '*' {
+				i++
+			}
+			expr.WriteString("[^/]*")
+		case '?':
+			expr.WriteString("[^/]")
+		case '[':
+			class, n, ok := bracketExpr(segment[i:])
+			if !ok {
+				expr.WriteString(`\[`)
+				continue
+			}
+			expr.WriteString(class)
+			i += n - 1
+		case '\\':
+			if i+1 < len(segment) {
+				i++
+				expr.WriteString(regexp.QuoteMeta(string(segment[i])))
+			}
+		default:
+			expr.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	return expr.String()
+}
+
+// bracketExpr translates the bracket expression at the start
+// of the given pattern and returns the number of bytes it spans,
+// or false if the bracket is not closed
+func bracketExpr(pattern string) (string, int, bool) {
+	var class strings.Builder
+	class.WriteString("[")
+
+	i := 1
+	if i < len(pattern) && (pattern[i] == '!' || pattern[i] == '^') {
+		// negated classes never match a slash
+		class.WriteString("^/")
+		i++
+	}
+	for start := i; i < len(pattern); i++ {
+		c := pattern[i]
+		if c == ']' && i > start {
+			class.WriteString("]")
+			return class.String(), i + 1, true
+		}
+		escaped := false
+		if c == '\\' && i+1 < len(pattern) {
+			i++
+			c = pattern[i]
+			escaped = true
+		}
+		switch {
+		case c == '\\', c == '[', c == ']', c == '^', escaped && c == '-':
+			class.WriteString(`\` + string(c))
+		default:
+			class.WriteByte(c)
+		}
+	}
+	return "", 0, false
+}
@@ -0,0 +1,76 @@
+package module
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+)
+
+func TestIgnoreRules_isIgnored(t *testing.T) {
+	baseDir, err := filepath.Abs("base")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		patterns []string
+		path     string
+		isDir    bool
+		expected bool
+	}{
+		{[]string{"examples"}, "examples", true, true},
+		{[]string{"examples"}, "modules/examples", true, true},
+		{[]string{"/examples"}, "modules/examples", true, false},
+		{[]string{"modules/examples"}, "modules/examples", true, true},
+		{[]string{"examples/"}, "examples", false, false},
+		{[]string{"*.tf"}, "modules/main.tf", false, true},
+		{[]string{"*.tf", "!main.tf"}, "main.tf", false, false},
+		{[]string{"**/fixtures"}, "a/b/fixtures", true, true},
+		{[]string{"**/fixtures"}, "fixtures", true, true},
+		{[]string{"a/**/c"}, "a/c", true, true},
+		{[]string{"a/**/c"}, "a/b/x/c", true, true},
+		{[]string{"test-?"}, "test-1", true, true},
+		{[]string{"test-?"}, "test-10", true, false},
+		{[]string{"env-[ab]"}, "env-a", true, true},
+		{[]string{"env-[!ab]"}, "env-a", true, false},
+		{[]string{"# comment", ""}, "# comment", true, false},
+		{[]string{"foo"}, "foobar", true, false},
+		{[]string{"a/**/b"}, "a/b", true, true},
+		{[]string{"a/**/b"}, "a/x/y/b", true, true},
+		{[]string{"a/**/b"}, "x/a/b", true, false},
+		{[]string{"a/**"}, "a/x/y", true, true},
+		{[]string{"a/**"}, "a", true, false},
+		{[]string{"a**b"}, "axyb", true, true},
+		{[]string{"a**b"}, "ax/yb", true, false},
+		{[]string{"a*/b"}, "ax/b", true, true},
+		{[]string{`\!x`}, "!x", true, true},
+		{[]string{`\!x`}, "x", true, false},
+		{[]string{`\#x`}, "#x", true, true},
+		{[]string{"[!a-z]"}, "1", true, true},
+		{[]string{"[!a-z]"}, "b", true, false},
+		{[]string{"x[!a-z]y"}, "x/y", true, false},
+		{[]string{"[]]"}, "]", true, true},
+		{[]string{`[\-]`}, "-", true, true},
+		{[]string{`[\-]`}, ",", true, false},
+		{[]string{"foo  "}, "foo", true, true},
+		{[]string{`foo\ `}, "foo ", true, true},
+		{[]string{`foo\ `}, "foo", true, false},
+		{[]string{"build"}, "build/main.tf", false, true},
+		{[]string{"build/"}, "build/modules/x", true, true},
+		{[]string{"build/", "!build/keep.tf"}, "build/keep.tf", false, true},
+		{[]string{"build/*", "!build/keep"}, "build/keep", true, false},
+		{[]string{"..foo"}, "..foo", true, true},
+	}
+
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("%d-%s", i, tc.path), func(t *testing.T) {
+			rules := parseIgnorePatterns(baseDir, tc.patterns)
+			path := filepath.Join(baseDir, filepath.FromSlash(tc.path))
+			ignored := rules.isIgnored(path, tc.isDir)
+			if ignored != tc.expected {
+				t.Fatalf("expected %q ignored: %t by %q, given: %t",
+					tc.path, tc.expected, tc.patterns, ignored)
+			}
+		})
+	}
+}
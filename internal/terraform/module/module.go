@@ -23,6 +23,7 @@ import (
 	"github.com/hashicorp/terraform-ls/internal/schemas"
 	"github.com/hashicorp/terraform-ls/internal/terraform/discovery"
 	"github.com/hashicorp/terraform-ls/internal/terraform/exec"
+	"github.com/hashicorp/terraform-ls/internal/terraform/plugin"
 	tfschema "github.com/hashicorp/terraform-schema/schema"
 )
 
@@ -64,18 +65,42 @@ type module struct {
 	tfDiscoErr   error
 	tfVersion    *version.Version
 	tfVersionErr error
+	tfVersionMu  *sync.RWMutex
+
+	// provider schemas supplied by the user
+	localSchemas *schemas.LocalProviderSchemas
+
+	// provider schemas shared with other modules
+	schemaCache *schemas.ProviderSchemaCache
+
+	// provider schemas obtained from installed plugins
+	pluginSchemas *plugin.SchemaSource
+
+	// parsed modules called from this or other modules
+	moduleCalls *moduleCallCache
+
+	// modules called from any modules, keyed by called module
+	callIndex *moduleCallIndex
 
 	// core schema
-	coreSchema   *schema.BodySchema
-	coreSchemaMu *sync.RWMutex
+	coreSchema        *schema.BodySchema
+	tfVersionFilePath string
+	coreSchemaMu      *sync.RWMutex
 
 	// decoder
 	isParsed    bool
 	isParsedMu  *sync.RWMutex
 	pFilesMap   map[string]*hcl.File
 	parsedDiags map[string]hcl.Diagnostics
+	parsedSums  map[string]string
 	parserMu    *sync.RWMutex
 	filesystem  filesystem.Filesystem
+
+	// merged schema (without module calls) along with
+	// signature of declarations it was merged for
+	mergedSchema          *schema.BodySchema
+	mergedSchemaSignature string
+	mergedSchemaMu        *sync.Mutex
 }
 
 func newModule(fs filesystem.Filesystem, dir string) *module {
@@ -89,10 +114,14 @@ func newModule(fs filesystem.Filesystem, dir string) *module {
 		pluginMu:         &sync.RWMutex{},
 		providerSchemaMu: &sync.RWMutex{},
 		tfLoadingMu:      &sync.RWMutex{},
+		tfVersionMu:      &sync.RWMutex{},
 		coreSchema:       tfschema.UniversalCoreModuleSchema(),
 		coreSchemaMu:     &sync.RWMutex{},
 		isParsedMu:       &sync.RWMutex{},
 		pFilesMap:        make(map[string]*hcl.File, 0),
+		parsedDiags:      make(map[string]hcl.Diagnostics, 0),
+		parsedSums:       make(map[string]string, 0),
+		mergedSchemaMu:   &sync.Mutex{},
 		providerVersions: make(map[string]*version.Version, 0),
 		parserMu:         &sync.RWMutex{},
 	}
@@ -128,6 +157,8 @@ func (m *module) discoverCaches(ctx context.Context, dir string) error {
 		errs = multierror.Append(errs, err)
 	}
 
+	m.discoverTerraformVersionFile(dir)
+
 	return errs.ErrorOrNil()
 }
 
@@ -251,7 +282,7 @@ func (m *module) load(ctx context.Context) error {
 			m.Path(), err)
 	}
 
-	err = m.UpdateProviderSchemaCache(ctx, m.pluginLockFile)
+	err = m.updateProviderSchemaCache(ctx, m.pluginLockFile)
 	errs = multierror.Append(errs, err)
 
 	m.logger.Printf("loading of module %s finished: %s",
@@ -315,8 +346,10 @@ func (m *module) ExecuteTerraformInit(ctx context.Context) error {
 	return m.tfExec.Init(ctx)
 }
 
-func (m *module) ExecuteTerraformValidate(ctx context.Context) (map[string]hcl.Diagnostics, error) {
-	diagsMap := make(map[string]hcl.Diagnostics)
+// ExecuteTerraformValidate runs terraform validate and returns
+// diagnostics as reported by Terraform, grouped by filename
+func (m *module) ExecuteTerraformValidate(ctx context.Context) (map[string][]tfjson.Diagnostic, error) {
+	diagsMap := make(map[string][]tfjson.Diagnostic)
 
 	if !m.IsTerraformAvailable() {
 		if err := m.discoverTerraformExecutor(ctx); err != nil {
@@ -332,7 +365,7 @@ func (m *module) ExecuteTerraformValidate(ctx context.Context) (map[string]hcl.D
 
 	// an entry for each file should exist, even if there are no diags
 	for filename := range m.parsedFiles() {
-		diagsMap[filename] = make(hcl.Diagnostics, 0)
+		diagsMap[filename] = make([]tfjson.Diagnostic, 0)
 	}
 	// since validation applies to linked modules, create an entry for all
 	// files of linked modules
@@ -366,7 +399,7 @@ func (m *module) ExecuteTerraformValidate(ctx context.Context) (map[string]hcl.D
 			// map entries are relative to the parent module path
 			filename := filepath.Join(mod.Dir, name)
 
-			diagsMap[filename] = make(hcl.Diagnostics, 0)
+			diagsMap[filename] = make([]tfjson.Diagnostic, 0)
 		}
 	}
 
@@ -375,37 +408,15 @@ func (m *module) ExecuteTerraformValidate(ctx context.Context) (map[string]hcl.D
 		return diagsMap, err
 	}
 
-	// tfjson.Diagnostic is a conversion of an internal diag to terraform core,
-	// tfdiags, which is effectively based on hcl.Diagnostic.
-	// This process is really just converting it back to hcl.Diagnotic
-	// since it is the defacto diagnostic type for our codebase currently
-	// https://github.com/hashicorp/terraform/blob/ae025248cc0712bf53c675dc2fe77af4276dd5cc/command/validate.go#L138
+	// diagnostics are kept as reported, incl. snippets
+	// which hcl.Diagnostic has no equivalent for
 	for _, d := range validationDiags {
 		// the diagnostic must be tied to a file to exist in the map
 		if d.Range == nil || d.Range.Filename == "" {
 			continue
 		}
 
-		diags := diagsMap[d.Range.Filename]
-
-		var severity hcl.DiagnosticSeverity
-		if d.Severity == "error" {
-			severity = hcl.DiagError
-		} else if d.Severity == "warning" {
-			severity = hcl.DiagWarning
-		}
-
-		diags = append(diags, &hcl.Diagnostic{
-			Severity: severity,
-			Summary:  d.Summary,
-			Detail:   d.Detail,
-			Subject: &hcl.Range{
-				Filename: d.Range.Filename,
-				Start:    hcl.Pos(d.Range.Start),
-				End:      hcl.Pos(d.Range.End),
-			},
-		})
-		diagsMap[d.Range.Filename] = diags
+		diagsMap[d.Range.Filename] = append(diagsMap[d.Range.Filename], d)
 	}
 
 	return diagsMap, nil
@@ -422,28 +433,91 @@ func (m *module) discoverTerraformVersion(ctx context.Context) error {
 	}
 	m.logger.Printf("Terraform version %s found at %s for %s", version,
 		m.tfExec.GetExecPath(), m.Path())
+	m.tfVersionMu.Lock()
 	m.tfVersion = version
+	m.tfVersionMu.Unlock()
 
+	m.providerSchemaMu.Lock()
 	m.providerVersions = providerVersions
+	m.providerSchemaMu.Unlock()
+
+	m.invalidateMergedSchema()
 
 	return nil
 }
 
-func (m *module) findAndSetCoreSchema() error {
-	if m.tfVersion == nil {
-		return errors.New("unable to find core schema without version")
-	}
+// TerraformVersion returns version of Terraform discovered
+// for the module, or nil if it was not discovered (yet)
+func (m *module) TerraformVersion() *version.Version {
+	m.tfVersionMu.RLock()
+	defer m.tfVersionMu.RUnlock()
+	return m.tfVersion
+}
+
+// ProviderVersions returns versions of installed providers
+// keyed by provider address, as reported by Terraform
+func (m *module) ProviderVersions() map[string]*version.Version {
+	m.providerSchemaMu.RLock()
+	defer m.providerSchemaMu.RUnlock()
+	return m.providerVersions
+}
+
+// UpdateCoreSchema re-evaluates which core schema applies to the module,
+// e.g. after version constraints have changed
+func (m *module) UpdateCoreSchema() error {
+	return m.findAndSetCoreSchema()
+}
 
-	coreSchema, err := tfschema.CoreModuleSchemaForVersion(m.tfVersion)
+func (m *module) findAndSetCoreSchema() error {
+	coreSchema, err := m.findCoreSchema()
 	if err != nil {
-		return err
+		coreSchema = tfschema.UniversalCoreModuleSchema()
 	}
 
 	m.coreSchemaMu.Lock()
 	m.coreSchema = coreSchema
 	m.coreSchemaMu.Unlock()
 
-	return nil
+	m.invalidateMergedSchema()
+
+	return err
+}
+
+// findCoreSchema finds the core schema for the installed version
+// of Terraform, or if that is unknown, for the newest version
+// which satisfies the required_version constraints
+// and .terraform-version file
+func (m *module) findCoreSchema() (*schema.BodySchema, error) {
+	tfVersion := m.TerraformVersion()
+	if tfVersion != nil {
+		return tfschema.CoreModuleSchemaForVersion(tfVersion)
+	}
+
+	files := m.parsedFiles()
+	if !m.IsParsed() {
+		var err error
+		files, _, err = parseModuleFiles(m.filesystem, m.Path(), m.logger)
+		if err != nil {
+			return nil, err
+		}
+	}
+	constraints := requiredVersionConstraints(files)
+
+	constraints = append(constraints, m.versionFileConstraints()...)
+
+	if len(constraints) == 0 {
+		return nil, errors.New("unable to find core schema without version or version constraints")
+	}
+
+	newestKnown, _ := schemas.PreloadedCoreVersion()
+	v, ok := newestSatisfyingCoreVersion(constraints, newestKnown)
+	if !ok {
+		return nil, fmt.Errorf("no known version of Terraform satisfies %q", constraints)
+	}
+	m.logger.Printf("%s: using core schema of version %s satisfying version constraints",
+		m.Path(), v)
+
+	return tfschema.CoreModuleSchemaForVersion(v)
 }
 
 func (m *module) LoadError() error {
@@ -488,6 +562,15 @@ func (m *module) HumanReadablePath(rootDir string) string {
 }
 
 func (m *module) UpdateModuleManifest(lockFile File) error {
+	err := m.updateModuleManifest(lockFile)
+	if err != nil {
+		return err
+	}
+	m.indexModuleCalls()
+	return nil
+}
+
+func (m *module) updateModuleManifest(lockFile File) error {
 	m.moduleMu.Lock()
 	defer m.moduleMu.Unlock()
 
@@ -550,51 +633,180 @@ func (m *module) setIsParsed(parsed bool) {
 	m.isParsed = parsed
 }
 
+// ParseFiles parses all configuration files of the module,
+// reusing results for any files whose content has not changed
+// since they were last parsed
 func (m *module) ParseFiles() error {
+	schemaChanged, err := m.parseFiles()
+	if err != nil {
+		return err
+	}
+	if schemaChanged {
+		m.updateCoreSchemaForParsedFiles()
+	}
+	m.indexModuleCalls()
+	return nil
+}
+
+func (m *module) parseFiles() (bool, error) {
 	m.parserMu.Lock()
 	defer m.parserMu.Unlock()
 
+	names, err := moduleFileNames(m.filesystem, m.Path())
+	if err != nil {
+		return false, err
+	}
+
+	parsed, err := parseFileSet(m.filesystem, m.Path(), names, m.parsedFileSet(), m.logger)
+	if err != nil {
+		return false, err
+	}
+
+	return m.setParsedFiles(parsed), nil
+}
+
+// ParseFile parses a single configuration file of the module,
+// e.g. after it was changed, unless its content is unchanged
+// since it was last parsed
+func (m *module) ParseFile(name string) error {
+	if !m.IsParsed() {
+		return m.ParseFiles()
+	}
+	if !isModuleFile(name) {
+		return nil
+	}
+
+	schemaChanged, err := m.parseSingleFile(name)
+	if err != nil {
+		return err
+	}
+	if schemaChanged {
+		m.updateCoreSchemaForParsedFiles()
+	}
+	m.indexModuleCalls()
+	return nil
+}
+
+func (m *module) parseSingleFile(name string) (bool, error) {
+	m.parserMu.Lock()
+	defer m.parserMu.Unlock()
+
+	prev := m.parsedFileSet()
+	fileParsed, err := parseFileSet(m.filesystem, m.Path(), []string{name}, prev, m.logger)
+	if err != nil {
+		return false, err
+	}
+
+	parsed := newParsedFileSet()
+	for n := range prev.sums {
+		if n != name {
+			parsed.add(n, prev.files[n], prev.diags[n], prev.sums[n])
+		}
+	}
+	if sum, ok := fileParsed.sums[name]; ok {
+		parsed.add(name, fileParsed.files[name], fileParsed.diags[name], sum)
+	}
+
+	return m.setParsedFiles(parsed), nil
+}
+
+// parsedFileSet returns previously parsed files of the module.
+// parserMu is expected to be locked.
+func (m *module) parsedFileSet() *parsedFileSet {
+	return &parsedFileSet{
+		files: m.pFilesMap,
+		diags: m.parsedDiags,
+		sums:  m.parsedSums,
+	}
+}
+
+// setParsedFiles replaces parsed files of the module and returns true
+// if declarations affecting the schema have changed as a result.
+// parserMu is expected to be locked.
+func (m *module) setParsedFiles(parsed *parsedFileSet) bool {
+	oldSignature := schemaSignature(m.pFilesMap)
+	wasParsed := m.IsParsed()
+
+	m.pFilesMap = parsed.files
+	m.parsedDiags = parsed.diags
+	m.parsedSums = parsed.sums
+	m.setIsParsed(true)
+
+	return !wasParsed || schemaSignature(parsed.files) != oldSignature
+}
+
+// indexModuleCalls records modules called from this module,
+// such that this module can be found as their caller
+func (m *module) indexModuleCalls() {
+	if m.callIndex == nil {
+		return
+	}
+	m.callIndex.Update(m.Path(), m.ModuleCallDirs())
+}
+
+func (m *module) updateCoreSchemaForParsedFiles() {
+	// required_version may have changed
+	if m.TerraformVersion() == nil {
+		if err := m.findAndSetCoreSchema(); err != nil {
+			m.logger.Printf("%s: %s - falling back to universal schema",
+				m.Path(), err)
+		}
+	}
+}
+
+func parseModuleFiles(fs filesystem.Filesystem, modPath string, logger *log.Logger) (map[string]*hcl.File, map[string]hcl.Diagnostics, error) {
+	names, err := moduleFileNames(fs, modPath)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	parsed, err := parseFileSet(fs, modPath, names, nil, logger)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return parsed.files, parsed.diags, nil
+}
+
+// ParseVariableFiles parses variable definitions files of the module
+// which Terraform loads automatically, i.e. terraform.tfvars
+// and *.auto.tfvars, keyed by filename.
+// Files which cannot be read or parsed are omitted.
+func (m *module) ParseVariableFiles() map[string]*hcl.File {
 	files := make(map[string]*hcl.File, 0)
-	diags := make(map[string]hcl.Diagnostics, 0)
 
 	infos, err := m.filesystem.ReadDir(m.Path())
 	if err != nil {
-		return fmt.Errorf("failed to read module at %q: %w", m.Path(), err)
+		m.logger.Printf("failed to read module at %q: %s", m.Path(), err)
+		return files
 	}
 
 	for _, info := range infos {
-		if info.IsDir() {
-			// We only care about files
-			continue
-		}
-
 		name := info.Name()
-		if !strings.HasSuffix(name, ".tf") || IsIgnoredFile(name) {
+		if info.IsDir() || !IsAutoloadedVariableFile(name) {
 			continue
 		}
 
-		// TODO: overrides
-
-		fullPath := filepath.Join(m.Path(), name)
-
-		src, err := m.filesystem.ReadFile(fullPath)
+		src, err := m.filesystem.ReadFile(filepath.Join(m.Path(), name))
 		if err != nil {
-			return fmt.Errorf("failed to read %q: %s", name, err)
+			m.logger.Printf("failed to read %q: %s", name, err)
+			continue
 		}
 
-		m.logger.Printf("parsing file %q", name)
-		f, pDiags := hclsyntax.ParseConfig(src, name, hcl.InitialPos)
-		diags[name] = pDiags
-		if f != nil {
-			files[name] = f
+		f, diags := hclsyntax.ParseConfig(src, name, hcl.InitialPos)
+		if diags.HasErrors() {
+			continue
 		}
+		files[name] = f
 	}
 
-	m.pFilesMap = files
-	m.parsedDiags = diags
-	m.setIsParsed(true)
+	return files
+}
 
-	return nil
+// IsAutoloadedVariableFile returns true if the given filename represents
+// a variable definitions file which Terraform loads automatically
+func IsAutoloadedVariableFile(name string) bool {
+	return name == "terraform.tfvars" || strings.HasSuffix(name, ".auto.tfvars")
 }
 
 func (m *module) ParsedDiagnostics() map[string]hcl.Diagnostics {
@@ -603,6 +815,10 @@ func (m *module) ParsedDiagnostics() map[string]hcl.Diagnostics {
 	return m.parsedDiags
 }
 
+func (m *module) ParsedFiles() map[string]*hcl.File {
+	return m.parsedFiles()
+}
+
 func (m *module) parsedFiles() map[string]*hcl.File {
 	m.parserMu.RLock()
 	defer m.parserMu.RUnlock()
@@ -611,9 +827,6 @@ func (m *module) parsedFiles() map[string]*hcl.File {
 }
 
 func (m *module) MergedSchema() (*schema.BodySchema, error) {
-	m.coreSchemaMu.RLock()
-	defer m.coreSchemaMu.RUnlock()
-
 	if !m.IsParsed() {
 		err := m.ParseFiles()
 		if err != nil {
@@ -621,10 +834,52 @@ func (m *module) MergedSchema() (*schema.BodySchema, error) {
 		}
 	}
 
-	ps, vOut, err := schemas.PreloadedProviderSchemas()
+	files := m.parsedFiles()
+
+	// Merging is expensive, so it is only done again
+	// when schemas or declarations affecting them change
+	m.mergedSchemaMu.Lock()
+	defer m.mergedSchemaMu.Unlock()
+
+	signature := schemaSignature(files)
+	if m.localSchemas != nil {
+		signature += fmt.Sprintf("/%d", m.localSchemas.Generation())
+	}
+	if m.mergedSchema != nil && m.mergedSchemaSignature == signature {
+		return m.mergeModuleCallSchemas(m.mergedSchema), nil
+	}
+
+	mergedSchema, err := m.mergeSchemas(files)
+	if err != nil {
+		return mergedSchema, err
+	}
+	m.mergedSchema = mergedSchema
+	m.mergedSchemaSignature = signature
+
+	return m.mergeModuleCallSchemas(mergedSchema), nil
+}
+
+// mergeSchemas merges core schema with provider schemas
+// applicable to the given files of the module
+func (m *module) mergeSchemas(files map[string]*hcl.File) (*schema.BodySchema, error) {
+	m.coreSchemaMu.RLock()
+	defer m.coreSchemaMu.RUnlock()
+
+	// choose preloaded schemas matching declared versions,
+	// since the installed ones are unknown in an uninitialized module
+	var constraints map[string]version.Constraints
+	if !m.IsProviderSchemaLoaded() {
+		constraints = requiredProviderConstraints(files)
+	}
+
+	ps, vOut, err := schemas.PreloadedProviderSchemasForConstraints(constraints)
 	if err != nil {
 		return nil, err
 	}
+	if m.localSchemas != nil && !m.localSchemas.IsEmpty() {
+		localPs, localVOut := m.localSchemas.ProviderSchemasForConstraints(constraints)
+		ps, vOut = schemas.MergeProviderSchemas(ps, vOut, localPs, localVOut)
+	}
 	providerVersions := vOut.Providers
 	tfVersion := vOut.Core
 
@@ -633,7 +888,7 @@ func (m *module) MergedSchema() (*schema.BodySchema, error) {
 		defer m.providerSchemaMu.RUnlock()
 		ps = m.providerSchema
 		providerVersions = m.providerVersions
-		tfVersion = m.tfVersion
+		tfVersion = m.TerraformVersion()
 	}
 
 	if ps == nil {
@@ -643,7 +898,7 @@ func (m *module) MergedSchema() (*schema.BodySchema, error) {
 
 	sm := tfschema.NewSchemaMerger(m.coreSchema)
 	sm.SetCoreVersion(tfVersion)
-	sm.SetParsedFiles(m.parsedFiles())
+	sm.SetParsedFiles(files)
 
 	err = sm.SetProviderVersions(providerVersions)
 	if err != nil {
@@ -715,10 +970,23 @@ func (m *module) IsTerraformAvailable() bool {
 }
 
 func (m *module) UpdateProviderSchemaCache(ctx context.Context, lockFile File) error {
+	// installed providers may have changed along with the lock file,
+	// so versions need to be refreshed to look up the right schemas
+	if m.schemaCache != nil && lockFile != nil && m.IsTerraformAvailable() {
+		err := m.discoverTerraformVersion(ctx)
+		if err != nil {
+			m.logger.Printf("failed to refresh provider versions for %s: %s", m.Path(), err)
+		}
+	}
+
+	return m.updateProviderSchemaCache(ctx, lockFile)
+}
+
+func (m *module) updateProviderSchemaCache(ctx context.Context, lockFile File) error {
 	m.pluginMu.Lock()
 	defer m.pluginMu.Unlock()
 
-	if !m.IsTerraformAvailable() {
+	if m.pluginSchemas == nil && !m.IsTerraformAvailable() {
 		return fmt.Errorf("cannot update provider schema as terraform is unavailable")
 	}
 
@@ -730,16 +998,91 @@ func (m *module) UpdateProviderSchemaCache(ctx context.Context, lockFile File) e
 
 	m.pluginLockFile = lockFile
 
-	schemas, err := m.tfExec.ProviderSchemas(ctx)
+	var providers []plugin.Provider
+	if m.pluginSchemas != nil {
+		providers = plugin.FindProviders(m.Path())
+		m.setInstalledProviderVersions(providers)
+	}
+
+	if m.schemaCache == nil {
+		ps, err := m.obtainProviderSchemas(ctx, providers)
+		if err != nil {
+			return err
+		}
+		m.setProviderSchema(ps)
+		return nil
+	}
+
+	providerVersions := m.ProviderVersions()
+	unlock := m.schemaCache.LockProviders(providerVersions)
+	defer unlock()
+
+	if ps, ok := m.schemaCache.ProviderSchemas(providerVersions); ok {
+		m.logger.Printf("using cached provider schemas for %s", m.Path())
+		m.setProviderSchema(ps)
+		return nil
+	}
+
+	ps, err := m.obtainProviderSchemas(ctx, providers)
 	if err != nil {
 		return err
 	}
+	m.setProviderSchema(m.schemaCache.Put(ps, providerVersions))
+
+	return nil
+}
+
+// obtainProviderSchemas gets schemas from the installed provider plugins
+// directly and only falls back to Terraform if that is not possible
+func (m *module) obtainProviderSchemas(ctx context.Context, providers []plugin.Provider) (*tfjson.ProviderSchemas, error) {
+	if m.pluginSchemas != nil && len(providers) > 0 {
+		ps, err := m.pluginSchemas.ProviderSchemas(ctx, providers)
+		if err == nil {
+			m.logger.Printf("obtained schemas from %d provider plugins for %s",
+				len(providers), m.Path())
+			return ps, nil
+		}
+		m.logger.Printf("failed to obtain schemas from provider plugins for %s: %s",
+			m.Path(), err)
+	}
+
+	if !m.IsTerraformAvailable() {
+		return nil, fmt.Errorf("cannot update provider schema as terraform is unavailable")
+	}
 
+	return m.tfExec.ProviderSchemas(ctx)
+}
+
+// setInstalledProviderVersions records versions of installed providers,
+// which may not be reported by Terraform, e.g. because it is not available
+func (m *module) setInstalledProviderVersions(providers []plugin.Provider) {
+	defer m.invalidateMergedSchema()
+
+	m.providerSchemaMu.Lock()
+	defer m.providerSchemaMu.Unlock()
+
+	versions := make(map[string]*version.Version, len(m.providerVersions))
+	for addr, v := range m.providerVersions {
+		versions[addr] = v
+	}
+	for _, p := range providers {
+		versions[p.Address] = p.Version
+	}
+	m.providerVersions = versions
+}
+
+func (m *module) setProviderSchema(ps *tfjson.ProviderSchemas) {
 	m.providerSchemaMu.Lock()
-	m.providerSchema = schemas
+	m.providerSchema = ps
 	m.providerSchemaMu.Unlock()
 
-	return nil
+	m.invalidateMergedSchema()
+}
+
+func (m *module) invalidateMergedSchema() {
+	m.mergedSchemaMu.Lock()
+	defer m.mergedSchemaMu.Unlock()
+	m.mergedSchema = nil
 }
 
 func (m *module) PathsToWatch() []string {
@@ -756,6 +1099,12 @@ func (m *module) PathsToWatch() []string {
 		files = append(files, m.moduleManifestFile.Path())
 	}
 
+	m.coreSchemaMu.RLock()
+	defer m.coreSchemaMu.RUnlock()
+	if m.tfVersionFilePath != "" {
+		files = append(files, m.tfVersionFilePath)
+	}
+
 	return files
 }
 
@@ -770,6 +1119,19 @@ func (m *module) IsKnownModuleManifestFile(path string) bool {
 	return pathEquals(m.moduleManifestFile.Path(), path)
 }
 
+// IsKnownTerraformVersionFile returns true if the given path represents
+// .terraform-version file which is applicable to the module
+func (m *module) IsKnownTerraformVersionFile(path string) bool {
+	m.coreSchemaMu.RLock()
+	defer m.coreSchemaMu.RUnlock()
+
+	if m.tfVersionFilePath == "" {
+		return false
+	}
+
+	return pathEquals(m.tfVersionFilePath, path)
+}
+
 func (m *module) IsKnownPluginLockFile(path string) bool {
 	m.pluginMu.RLock()
 	defer m.pluginMu.RUnlock()
@@ -0,0 +1,73 @@
+package module
+
+import (
+	"log"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/terraform-ls/internal/filesystem"
+)
+
+// moduleCallCache keeps parsed files of modules called from other modules,
+// such that each file is parsed again only once its checksum changes,
+// rather than on every completion, hover or validation of a caller
+type moduleCallCache struct {
+	fs      filesystem.Filesystem
+	modules map[string]*parsedFileSet
+	mu      *sync.Mutex
+	logger  *log.Logger
+}
+
+func newModuleCallCache(fs filesystem.Filesystem) *moduleCallCache {
+	return &moduleCallCache{
+		fs:      fs,
+		modules: make(map[string]*parsedFileSet, 0),
+		mu:      &sync.Mutex{},
+		logger:  defaultLogger,
+	}
+}
+
+func (c *moduleCallCache) SetLogger(logger *log.Logger) {
+	c.logger = logger
+}
+
+// ParsedFiles returns parsed configuration files of the module
+// in the given directory, keyed by filename. The returned map
+// is never modified by the cache and is safe to be shared.
+func (c *moduleCallCache) ParsedFiles(dir string) (map[string]*hcl.File, error) {
+	dir = filepath.Clean(dir)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	names, err := moduleFileNames(c.fs, dir)
+	if err != nil {
+		delete(c.modules, dir)
+		return nil, err
+	}
+
+	parsed, err := parseFileSet(c.fs, dir, names, c.modules[dir], c.logger)
+	if err != nil {
+		return nil, err
+	}
+	c.modules[dir] = parsed
+
+	return parsed.files, nil
+}
+
+// Invalidate drops parsed files of any module in the given directory
+// or its subdirectories, e.g. after files changed on the disk
+func (c *moduleCallCache) Invalidate(dir string) {
+	dir = filepath.Clean(dir)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for modDir := range c.modules {
+		if pathEquals(modDir, dir) || strings.HasPrefix(modDir, dir+string(filepath.Separator)) {
+			delete(c.modules, modDir)
+		}
+	}
+}
@@ -0,0 +1,74 @@
+package module
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hashicorp/terraform-ls/internal/filesystem"
+)
+
+func TestModuleCallCache_ParsedFiles(t *testing.T) {
+	modPath, err := ioutil.TempDir("", "module-call-cache")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(modPath)
+
+	writeModuleFile(t, modPath, "main.tf", `variable "first" {}`)
+	writeModuleFile(t, modPath, "variables.tf", `variable "second" {}`)
+
+	c := newModuleCallCache(filesystem.NewFilesystem())
+	files, err := c.ParsedFiles(modPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 2 {
+		t.Fatalf("expected 2 parsed files, given %d", len(files))
+	}
+	mainFile := files["main.tf"]
+	varsFile := files["variables.tf"]
+
+	// unchanged files are not parsed again
+	files, err = c.ParsedFiles(modPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if files["main.tf"] != mainFile || files["variables.tf"] != varsFile {
+		t.Fatal("expected unchanged files to be reused")
+	}
+
+	writeModuleFile(t, modPath, "variables.tf", `variable "third" {}`)
+	files, err = c.ParsedFiles(modPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if files["main.tf"] != mainFile {
+		t.Fatal("expected other files to be reused")
+	}
+	if files["variables.tf"] == varsFile {
+		t.Fatal("expected changed file to be reparsed")
+	}
+
+	err = os.Remove(filepath.Join(modPath, "variables.tf"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	files, err = c.ParsedFiles(modPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := files["variables.tf"]; ok {
+		t.Fatal("expected removed file to be forgotten")
+	}
+
+	c.Invalidate(filepath.Dir(modPath))
+	files, err = c.ParsedFiles(modPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if files["main.tf"] == mainFile {
+		t.Fatal("expected files to be reparsed after invalidation")
+	}
+}
@@ -0,0 +1,83 @@
+package module
+
+import (
+	"path/filepath"
+	"sort"
+	"sync"
+)
+
+// moduleCallIndex keeps track of which modules call which other modules,
+// such that callers of a module can be found without decoding
+// every known module each time the module changes
+type moduleCallIndex struct {
+	// callers maps a called module directory to directories of its callers
+	callers map[string]map[string]bool
+	// calls maps a caller directory to directories of modules it calls
+	calls map[string][]string
+	mu    *sync.RWMutex
+}
+
+func newModuleCallIndex() *moduleCallIndex {
+	return &moduleCallIndex{
+		callers: make(map[string]map[string]bool, 0),
+		calls:   make(map[string][]string, 0),
+		mu:      &sync.RWMutex{},
+	}
+}
+
+// Update replaces directories of modules called from the module
+// in callerDir, such as those returned by ModuleCallDirs()
+func (i *moduleCallIndex) Update(callerDir string, callDirs map[string]string) {
+	callerDir = filepath.Clean(callerDir)
+
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	i.remove(callerDir)
+
+	dirs := make([]string, 0, len(callDirs))
+	for _, dir := range callDirs {
+		dir = filepath.Clean(dir)
+		if _, ok := i.callers[dir]; !ok {
+			i.callers[dir] = make(map[string]bool, 0)
+		}
+		i.callers[dir][callerDir] = true
+		dirs = append(dirs, dir)
+	}
+	if len(dirs) > 0 {
+		i.calls[callerDir] = dirs
+	}
+}
+
+// Remove forgets module calls of the module in callerDir,
+// e.g. because the module was removed
+func (i *moduleCallIndex) Remove(callerDir string) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	i.remove(filepath.Clean(callerDir))
+}
+
+func (i *moduleCallIndex) remove(callerDir string) {
+	for _, dir := range i.calls[callerDir] {
+		delete(i.callers[dir], callerDir)
+		if len(i.callers[dir]) == 0 {
+			delete(i.callers, dir)
+		}
+	}
+	delete(i.calls, callerDir)
+}
+
+// Callers returns sorted directories of modules
+// calling the module in the given directory
+func (i *moduleCallIndex) Callers(dir string) []string {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+
+	callers := make([]string, 0)
+	for callerDir := range i.callers[filepath.Clean(dir)] {
+		callers = append(callers, callerDir)
+	}
+	sort.Strings(callers)
+	return callers
+}
@@ -0,0 +1,83 @@
+package module
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/hashicorp/terraform-ls/internal/filesystem"
+)
+
+func TestModuleCallIndex(t *testing.T) {
+	idx := newModuleCallIndex()
+
+	idx.Update("/root", map[string]string{
+		"first":  "/root/modules/first",
+		"second": "/root/modules/second/",
+	})
+	idx.Update("/other", map[string]string{
+		"first": "/root/modules/first",
+	})
+
+	if diff := cmp.Diff([]string{"/other", "/root"}, idx.Callers("/root/modules/first")); diff != "" {
+		t.Fatalf("callers mismatch: %s", diff)
+	}
+	if diff := cmp.Diff([]string{"/root"}, idx.Callers("/root/modules/second")); diff != "" {
+		t.Fatalf("callers mismatch: %s", diff)
+	}
+
+	// calls removed from a module are forgotten
+	idx.Update("/root", map[string]string{
+		"second": "/root/modules/second",
+	})
+	if diff := cmp.Diff([]string{"/other"}, idx.Callers("/root/modules/first")); diff != "" {
+		t.Fatalf("callers mismatch after update: %s", diff)
+	}
+
+	idx.Remove("/other")
+	if diff := cmp.Diff([]string{}, idx.Callers("/root/modules/first")); diff != "" {
+		t.Fatalf("callers mismatch after removal: %s", diff)
+	}
+	if diff := cmp.Diff([]string{"/root"}, idx.Callers("/root/modules/second")); diff != "" {
+		t.Fatalf("callers mismatch after removal: %s", diff)
+	}
+}
+
+func TestModule_ParseFiles_indexesModuleCalls(t *testing.T) {
+	modPath, err := ioutil.TempDir("", "module-call-index")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(modPath)
+
+	writeModuleFile(t, modPath, "main.tf", `module "child" {
+  source = "./child"
+}
+`)
+
+	m := newModule(filesystem.NewFilesystem(), modPath)
+	m.callIndex = newModuleCallIndex()
+	err = m.ParseFiles()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	childPath := filepath.Join(modPath, "child")
+	if diff := cmp.Diff([]string{modPath}, m.callIndex.Callers(childPath)); diff != "" {
+		t.Fatalf("callers mismatch: %s", diff)
+	}
+
+	writeModuleFile(t, modPath, "main.tf", `module "child" {
+  source = "./other"
+}
+`)
+	err = m.ParseFile("main.tf")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff([]string{}, m.callIndex.Callers(childPath)); diff != "" {
+		t.Fatalf("callers mismatch after change: %s", diff)
+	}
+}
@@ -0,0 +1,149 @@
+package module
+
+import (
+	"path/filepath"
+	"strings"
+
+	"github.com/hashicorp/hcl-lang/lang"
+	"github.com/hashicorp/hcl-lang/schema"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/ext/typeexpr"
+	"github.com/hashicorp/terraform-ls/internal/terraform/declarations"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// ModuleCallDirs returns absolute paths to directories of modules
+// called from this module, keyed by name of the module call.
+// Installed modules are found via the module manifest, others
+// only if they are sourced from a local path.
+func (m *module) ModuleCallDirs() map[string]string {
+	dirs := make(map[string]string, 0)
+
+	decls := declarations.Decode(m.parsedFiles())
+	for name, decl := range decls.ModuleCalls {
+		source, ok := decl.StringAttribute("source")
+		if !ok {
+			continue
+		}
+		if strings.HasPrefix(source, "./") || strings.HasPrefix(source, "../") {
+			dirs[name] = filepath.Join(m.Path(), filepath.FromSlash(source))
+		}
+	}
+
+	// module manifest takes precedence as it reflects what was installed
+	for _, record := range m.Modules() {
+		if record.IsRoot() || strings.Contains(record.Key, ".") {
+			// only modules called directly from this module are relevant
+			continue
+		}
+		dirs[record.Key] = filepath.Join(m.Path(), record.Dir)
+	}
+
+	return dirs
+}
+
+// ParseModuleCalls parses files of modules called from this module,
+// keyed by name of the module call. Modules which cannot be found
+// or read (e.g. remote modules which were not installed yet) are omitted.
+func (m *module) ParseModuleCalls() map[string]map[string]*hcl.File {
+	calls := make(map[string]map[string]*hcl.File, 0)
+	for name, dir := range m.ModuleCallDirs() {
+		files, err := m.parseModuleCall(dir)
+		if err != nil {
+			m.logger.Printf("unable to parse module %q: %s", name, err)
+			continue
+		}
+		calls[name] = files
+	}
+	return calls
+}
+
+func (m *module) parseModuleCall(dir string) (map[string]*hcl.File, error) {
+	if m.moduleCalls == nil {
+		files, _, err := parseModuleFiles(m.filesystem, dir, m.logger)
+		return files, err
+	}
+	return m.moduleCalls.ParsedFiles(dir)
+}
+
+// mergeModuleCallSchemas returns a copy of the given schema where the module
+// block schema contains a dependent body for each module call (keyed by source)
+// describing variables of the called module as arguments.
+func (m *module) mergeModuleCallSchemas(bodySchema *schema.BodySchema) *schema.BodySchema {
+	if bodySchema == nil {
+		return nil
+	}
+	modSchema, ok := bodySchema.Blocks["module"]
+	if !ok {
+		return bodySchema
+	}
+
+	children := m.ParseModuleCalls()
+	if len(children) == 0 {
+		return bodySchema
+	}
+
+	mergedModSchema := *modSchema
+	mergedModSchema.DependentBody = make(map[schema.SchemaKey]*schema.BodySchema, 0)
+	for key, depBody := range modSchema.DependentBody {
+		mergedModSchema.DependentBody[key] = depBody
+	}
+
+	decls := declarations.Decode(m.parsedFiles())
+	for name, call := range decls.ModuleCalls {
+		childFiles, ok := children[name]
+		if !ok {
+			continue
+		}
+		source, ok := call.StringAttribute("source")
+		if !ok {
+			continue
+		}
+
+		key := schema.NewSchemaKey(schema.DependencyKeys{
+			Attributes: []schema.AttributeDependent{
+				{
+					Name: "source",
+					Expr: schema.ExpressionValue{Static: cty.StringVal(source)},
+				},
+			},
+		})
+		mergedModSchema.DependentBody[key] = moduleCallBodySchema(declarations.Decode(childFiles))
+	}
+
+	merged := *bodySchema
+	merged.Blocks = make(map[string]*schema.BlockSchema, len(bodySchema.Blocks))
+	for bType, bSchema := range bodySchema.Blocks {
+		merged.Blocks[bType] = bSchema
+	}
+	merged.Blocks["module"] = &mergedModSchema
+
+	return &merged
+}
+
+// moduleCallBodySchema describes variables of the called module
+// as arguments of the module block
+func moduleCallBodySchema(child *declarations.Module) *schema.BodySchema {
+	bodySchema := schema.NewBodySchema()
+	for name, variable := range child.Variables {
+		_, hasDefault := variable.Block.Body.Attributes["default"]
+
+		valueType := cty.DynamicPseudoType
+		if attr, ok := variable.Block.Body.Attributes["type"]; ok {
+			if t, diags := typeexpr.TypeConstraint(attr.Expr); !diags.HasErrors() {
+				valueType = t
+			}
+		}
+
+		aSchema := &schema.AttributeSchema{
+			IsRequired: !hasDefault,
+			IsOptional: hasDefault,
+			ValueType:  valueType,
+		}
+		if description, ok := variable.StringAttribute("description"); ok {
+			aSchema.Description = lang.PlainText(description)
+		}
+		bodySchema.Attributes[name] = aSchema
+	}
+	return bodySchema
+}
@@ -0,0 +1,51 @@
+package module
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/hashicorp/hcl-lang/lang"
+	"github.com/hashicorp/hcl-lang/schema"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/hashicorp/terraform-ls/internal/terraform/declarations"
+	"github.com/zclconf/go-cty/cty"
+)
+
+func TestModuleCallBodySchema(t *testing.T) {
+	src := `variable "required" {
+  type        = list(string)
+  description = "Required variable"
+}
+variable "optional" {
+  default = "foo"
+}
+`
+	f, diags := hclsyntax.ParseConfig([]byte(src), "variables.tf", hcl.InitialPos)
+	if len(diags) > 0 {
+		t.Fatal(diags)
+	}
+	child := declarations.Decode(map[string]*hcl.File{"variables.tf": f})
+
+	expectedSchema := &schema.BodySchema{
+		Attributes: map[string]*schema.AttributeSchema{
+			"required": {
+				Description: lang.PlainText("Required variable"),
+				IsRequired:  true,
+				ValueType:   cty.List(cty.String),
+			},
+			"optional": {
+				IsOptional: true,
+				ValueType:  cty.DynamicPseudoType,
+			},
+		},
+		Blocks: map[string]*schema.BlockSchema{},
+	}
+
+	bodySchema := moduleCallBodySchema(child)
+	if diff := cmp.Diff(expectedSchema, bodySchema, cmp.Comparer(func(x, y cty.Type) bool {
+		return x.Equals(y)
+	})); diff != "" {
+		t.Fatalf("schema mismatch: %s", diff)
+	}
+}
@@ -8,17 +8,21 @@ import (
 	"path/filepath"
 	"runtime"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/gammazero/workerpool"
 	"github.com/hashicorp/hcl-lang/schema"
 	"github.com/hashicorp/terraform-ls/internal/filesystem"
+	"github.com/hashicorp/terraform-ls/internal/schemas"
 	"github.com/hashicorp/terraform-ls/internal/terraform/discovery"
 	"github.com/hashicorp/terraform-ls/internal/terraform/exec"
+	"github.com/hashicorp/terraform-ls/internal/terraform/plugin"
 )
 
 type moduleManager struct {
 	modules    []*module
+	modulesMu  *sync.RWMutex
 	newModule  ModuleFactory
 	filesystem filesystem.Filesystem
 
@@ -34,6 +38,21 @@ type moduleManager struct {
 	tfExecPath    string
 	tfExecTimeout time.Duration
 	tfExecLogPath string
+
+	// provider schemas supplied by the user
+	localSchemas *schemas.LocalProviderSchemas
+
+	// provider schemas shared by all modules
+	schemaCache *schemas.ProviderSchemaCache
+
+	// provider schemas obtained from installed plugins
+	pluginSchemas *plugin.SchemaSource
+
+	// parsed modules called from any modules
+	moduleCalls *moduleCallCache
+
+	// modules called from any modules, keyed by called module
+	callIndex *moduleCallIndex
 }
 
 func NewModuleManager(fs filesystem.Filesystem) ModuleManager {
@@ -48,11 +67,17 @@ func newModuleManager(fs filesystem.Filesystem) *moduleManager {
 
 	mm := &moduleManager{
 		modules:       make([]*module, 0),
+		modulesMu:     &sync.RWMutex{},
 		filesystem:    fs,
 		workerPool:    wp,
 		logger:        defaultLogger,
 		tfDiscoFunc:   d.LookPath,
 		tfNewExecutor: exec.NewExecutor,
+		localSchemas:  schemas.NewLocalProviderSchemas(),
+		schemaCache:   newProviderSchemaCache(),
+		pluginSchemas: plugin.NewSchemaSource(),
+		moduleCalls:   newModuleCallCache(fs),
+		callIndex:     newModuleCallIndex(),
 	}
 	mm.newModule = mm.defaultModuleFactory
 	return mm
@@ -79,6 +104,12 @@ func (mm *moduleManager) defaultModuleFactory(ctx context.Context, dir string) (
 	mod.tfExecTimeout = mm.tfExecTimeout
 	mod.tfExecLogPath = mm.tfExecLogPath
 
+	mod.localSchemas = mm.localSchemas
+	mod.schemaCache = mm.schemaCache
+	mod.pluginSchemas = mm.pluginSchemas
+	mod.moduleCalls = mm.moduleCalls
+	mod.callIndex = mm.callIndex
+
 	return mod, mod.discoverCaches(ctx, dir)
 }
 
@@ -94,8 +125,35 @@ func (mm *moduleManager) SetTerraformExecTimeout(timeout time.Duration) {
 	mm.tfExecTimeout = timeout
 }
 
+// LoadProviderSchemas loads provider schemas from the given JSON files
+// or directories, which are then used by all modules where
+// provider schemas cannot be obtained from Terraform
+func (mm *moduleManager) LoadProviderSchemas(paths []string) error {
+	return mm.localSchemas.Load(paths)
+}
+
 func (mm *moduleManager) SetLogger(logger *log.Logger) {
 	mm.logger = logger
+	mm.schemaCache.SetLogger(logger)
+	mm.pluginSchemas.SetLogger(logger)
+	mm.moduleCalls.SetLogger(logger)
+}
+
+// InvalidateModuleCalls drops any parsed files of modules
+// in the given directory kept for their callers,
+// e.g. after files of those modules changed on the disk
+func (mm *moduleManager) InvalidateModuleCalls(dir string) {
+	mm.moduleCalls.Invalidate(dir)
+}
+
+// newProviderSchemaCache returns cache persisted in the default
+// cache directory, or kept only in memory if that is unavailable
+func newProviderSchemaCache() *schemas.ProviderSchemaCache {
+	dir, err := schemas.DefaultProviderSchemaCacheDir()
+	if err != nil {
+		dir = ""
+	}
+	return schemas.NewProviderSchemaCache(dir)
 }
 
 func (mm *moduleManager) InitAndUpdateModule(ctx context.Context, dir string) (Module, error) {
@@ -113,13 +171,16 @@ func (mm *moduleManager) InitAndUpdateModule(ctx context.Context, dir string) (M
 	return mod, m.UpdateProviderSchemaCache(ctx, m.pluginLockFile)
 }
 
+// AddAndStartLoadingModule adds module in the given directory and starts
+// loading it. If the module was already added, the known module
+// is returned along with *ModuleAlreadyAddedErr.
 func (mm *moduleManager) AddAndStartLoadingModule(ctx context.Context, dir string) (Module, error) {
 	dir = filepath.Clean(dir)
 
 	// TODO: Follow symlinks (requires proper test data)
 
-	if _, ok := mm.moduleByPath(dir); ok {
-		return nil, fmt.Errorf("module %s was already added", dir)
+	if mod, ok := mm.moduleByPath(dir); ok {
+		return mod, &ModuleAlreadyAddedErr{dir}
 	}
 
 	mod, err := mm.newModule(context.Background(), dir)
@@ -127,7 +188,17 @@ func (mm *moduleManager) AddAndStartLoadingModule(ctx context.Context, dir strin
 		return nil, err
 	}
 
+	// the same module may have been added concurrently
+	// while this one was created, so we check again
+	mm.modulesMu.Lock()
+	for _, m := range mm.modules {
+		if pathEquals(m.Path(), dir) {
+			mm.modulesMu.Unlock()
+			return m, &ModuleAlreadyAddedErr{dir}
+		}
+	}
 	mm.modules = append(mm.modules, mod)
+	mm.modulesMu.Unlock()
 
 	if mm.syncLoading {
 		mm.logger.Printf("synchronously loading module %s", dir)
@@ -144,6 +215,40 @@ func (mm *moduleManager) AddAndStartLoadingModule(ctx context.Context, dir strin
 	return mod, nil
 }
 
+// RemoveModule removes module at the given path (e.g. because
+// its directory was deleted), cancelling any loading in progress
+func (mm *moduleManager) RemoveModule(dir string) error {
+	dir = filepath.Clean(dir)
+
+	mm.modulesMu.Lock()
+	defer mm.modulesMu.Unlock()
+
+	for i, mod := range mm.modules {
+		if pathEquals(mod.Path(), dir) {
+			mod.CancelLoading()
+			mm.modules = append(mm.modules[:i], mm.modules[i+1:]...)
+			mm.callIndex.Remove(dir)
+			mm.logger.Printf("removed module %s", dir)
+			return nil
+		}
+	}
+
+	return &ModuleNotFoundErr{dir}
+}
+
+// CallersOfModule returns known modules which call
+// the module in the given directory
+func (mm *moduleManager) CallersOfModule(dir string) Modules {
+	callers := make([]Module, 0)
+	for _, callerDir := range mm.callIndex.Callers(dir) {
+		mod, ok := mm.moduleByPath(callerDir)
+		if ok {
+			callers = append(callers, mod)
+		}
+	}
+	return callers
+}
+
 func (mm *moduleManager) SchemaForPath(path string) (*schema.BodySchema, error) {
 	candidates := mm.ModuleCandidatesByPath(path)
 	for _, mod := range candidates {
@@ -167,6 +272,9 @@ func (mm *moduleManager) SchemaForPath(path string) (*schema.BodySchema, error)
 }
 
 func (mm *moduleManager) moduleByPath(dir string) (*module, bool) {
+	mm.modulesMu.RLock()
+	defer mm.modulesMu.RUnlock()
+
 	for _, mod := range mm.modules {
 		if pathEquals(mod.Path(), dir) {
 			return mod, true
@@ -202,7 +310,7 @@ func (mm *moduleManager) ModuleCandidatesByPath(path string) Modules {
 		}
 	}
 
-	for _, mod := range mm.modules {
+	for _, mod := range mm.listModules() {
 		if mod.ReferencesModulePath(path) {
 			candidates = append(candidates, mod)
 		}
@@ -213,11 +321,21 @@ func (mm *moduleManager) ModuleCandidatesByPath(path string) Modules {
 
 func (mm *moduleManager) ListModules() Modules {
 	modules := make([]Module, 0)
-	for _, mod := range mm.modules {
+	for _, mod := range mm.listModules() {
 		modules = append(modules, mod)
 	}
 	return modules
 }
+
+func (mm *moduleManager) listModules() []*module {
+	mm.modulesMu.RLock()
+	defer mm.modulesMu.RUnlock()
+
+	modules := make([]*module, len(mm.modules))
+	copy(modules, mm.modules)
+	return modules
+}
+
 func (mm *moduleManager) ModuleByPath(path string) (Module, error) {
 	path = filepath.Clean(path)
 
@@ -308,7 +426,7 @@ func (mm *moduleManager) HasTerraformDiscoveryFinished(path string) (bool, error
 }
 
 func (mm *moduleManager) CancelLoading() {
-	for _, mod := range mm.modules {
+	for _, mod := range mm.listModules() {
 		mm.logger.Printf("cancelling loading for %s", mod.Path())
 		mod.CancelLoading()
 		mm.logger.Printf("loading cancelled for %s", mod.Path())
@@ -336,7 +454,7 @@ func trimLockFilePath(filePath string) string {
 
 func (mm *moduleManager) PathsToWatch() []string {
 	paths := make([]string, 0)
-	for _, mod := range mm.modules {
+	for _, mod := range mm.listModules() {
 		ptw := mod.PathsToWatch()
 		if len(ptw) > 0 {
 			paths = append(paths, ptw...)
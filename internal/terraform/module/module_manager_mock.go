@@ -14,6 +14,9 @@ type ModuleMockFactory struct {
 	mmocks map[string]*ModuleMock
 	logger *log.Logger
 	fs     filesystem.Filesystem
+
+	moduleCalls *moduleCallCache
+	callIndex   *moduleCallIndex
 }
 
 func (mmf *ModuleMockFactory) New(ctx context.Context, dir string) (*module, error) {
@@ -24,6 +27,8 @@ func (mmf *ModuleMockFactory) New(ctx context.Context, dir string) (*module, err
 
 	mock := NewModuleMock(mmocks, mmf.fs, dir)
 	mock.SetLogger(mmf.logger)
+	mock.moduleCalls = mmf.moduleCalls
+	mock.callIndex = mmf.callIndex
 	return mock, mock.discoverCaches(ctx, dir)
 }
 
@@ -41,6 +46,9 @@ func NewModuleManagerMock(input *ModuleManagerMockInput) ModuleManagerFactory {
 			mmocks: make(map[string]*ModuleMock, 0),
 			logger: mm.logger,
 			fs:     fs,
+
+			moduleCalls: mm.moduleCalls,
+			callIndex:   mm.callIndex,
 		}
 
 		// mock terraform discovery
@@ -2,12 +2,15 @@ package module
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io/ioutil"
 	"log"
 	"os"
 	"path/filepath"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/hashicorp/go-version"
@@ -478,6 +481,58 @@ func TestSchemaForPath_uninitialized(t *testing.T) {
 	}
 }
 
+func TestModuleManager_AddAndStartLoadingModule_concurrent(t *testing.T) {
+	mm := newModuleManager(filesystem.NewFilesystem())
+	mm.logger = testLogger()
+	mm.newModule = func(ctx context.Context, dir string) (*module, error) {
+		// widen the window between checking for and adding the module
+		time.Sleep(10 * time.Millisecond)
+
+		mod := newModule(mm.filesystem, dir)
+		mod.logger = testLogger()
+		mod.tfDiscoFunc = func() (string, error) {
+			return "", errors.New("terraform not found")
+		}
+		return mod, nil
+	}
+
+	modPath, err := ioutil.TempDir("", "concurrent-module")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(modPath)
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 5)
+	for i := 0; i < cap(errs); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := mm.AddAndStartLoadingModule(context.Background(), modPath)
+			errs <- err
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	added := 0
+	for err := range errs {
+		if err == nil {
+			added++
+			continue
+		}
+		if !IsModuleAlreadyAdded(err) {
+			t.Fatalf("unexpected error: %s", err)
+		}
+	}
+	if added != 1 {
+		t.Fatalf("expected module to be added once, added %d times", added)
+	}
+	if len(mm.ListModules()) != 1 {
+		t.Fatalf("expected 1 module, given %d", len(mm.ListModules()))
+	}
+}
+
 func testModuleManager(t *testing.T) *moduleManager {
 	fs := filesystem.NewFilesystem()
 	mm := newModuleManager(fs)
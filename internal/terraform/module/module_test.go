@@ -0,0 +1,126 @@
+package module
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hashicorp/terraform-ls/internal/filesystem"
+)
+
+func TestModule_ParseFile(t *testing.T) {
+	modPath, err := ioutil.TempDir("", "module-parse")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(modPath)
+
+	writeModuleFile(t, modPath, "main.tf", `variable "first" {}`)
+	writeModuleFile(t, modPath, "variables.tf", `variable "second" {}`)
+
+	m := newModule(filesystem.NewFilesystem(), modPath)
+	err = m.ParseFiles()
+	if err != nil {
+		t.Fatal(err)
+	}
+	mainFile := m.ParsedFiles()["main.tf"]
+	varsFile := m.ParsedFiles()["variables.tf"]
+
+	// unchanged files are not parsed again
+	err = m.ParseFiles()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.ParsedFiles()["main.tf"] != mainFile || m.ParsedFiles()["variables.tf"] != varsFile {
+		t.Fatal("expected unchanged files to be reused")
+	}
+
+	writeModuleFile(t, modPath, "variables.tf", `variable "second" {`)
+	err = m.ParseFile("variables.tf")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.ParsedFiles()["main.tf"] != mainFile {
+		t.Fatal("expected other files to be reused")
+	}
+	if m.ParsedFiles()["variables.tf"] == varsFile {
+		t.Fatal("expected changed file to be reparsed")
+	}
+	if !m.ParsedDiagnostics()["variables.tf"].HasErrors() {
+		t.Fatal("expected diagnostics of changed file to be updated")
+	}
+
+	err = os.Remove(filepath.Join(modPath, "variables.tf"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = m.ParseFile("variables.tf")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := m.ParsedFiles()["variables.tf"]; ok {
+		t.Fatal("expected removed file to be forgotten")
+	}
+	if _, ok := m.ParsedDiagnostics()["variables.tf"]; ok {
+		t.Fatal("expected diagnostics of removed file to be forgotten")
+	}
+}
+
+func TestModule_MergedSchema_cached(t *testing.T) {
+	modPath, err := ioutil.TempDir("", "module-schema")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(modPath)
+
+	writeModuleFile(t, modPath, "main.tf", `variable "first" {}`)
+
+	m := newModule(filesystem.NewFilesystem(), modPath)
+	err = m.ParseFiles()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	first, err := m.MergedSchema()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// change which doesn't affect the schema
+	writeModuleFile(t, modPath, "main.tf", `variable "second" {}`)
+	err = m.ParseFile("main.tf")
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := m.MergedSchema()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first != second {
+		t.Fatal("expected schema not to be merged again")
+	}
+
+	writeModuleFile(t, modPath, "main.tf", `terraform {
+  required_version = "~> 0.12.0"
+}
+`)
+	err = m.ParseFile("main.tf")
+	if err != nil {
+		t.Fatal(err)
+	}
+	third, err := m.MergedSchema()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if second == third {
+		t.Fatal("expected schema to be merged again")
+	}
+}
+
+func writeModuleFile(t *testing.T, modPath, name, content string) {
+	err := ioutil.WriteFile(filepath.Join(modPath, name), []byte(content), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,91 @@
+package module
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/hashicorp/terraform-ls/internal/filesystem"
+)
+
+// parsedFileSet holds parsed configuration files of a module,
+// along with parser diagnostics and checksums of their sources,
+// all keyed by filename
+type parsedFileSet struct {
+	files map[string]*hcl.File
+	diags map[string]hcl.Diagnostics
+	sums  map[string]string
+}
+
+func newParsedFileSet() *parsedFileSet {
+	return &parsedFileSet{
+		files: make(map[string]*hcl.File, 0),
+		diags: make(map[string]hcl.Diagnostics, 0),
+		sums:  make(map[string]string, 0),
+	}
+}
+
+func (s *parsedFileSet) add(name string, f *hcl.File, diags hcl.Diagnostics, sum string) {
+	s.diags[name] = diags
+	s.sums[name] = sum
+	if f != nil {
+		s.files[name] = f
+	}
+}
+
+// moduleFileNames returns names of configuration files
+// of the module in the given directory
+func moduleFileNames(fs filesystem.Filesystem, dir string) ([]string, error) {
+	infos, err := fs.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read module at %q: %w", dir, err)
+	}
+
+	names := make([]string, 0)
+	for _, info := range infos {
+		name := info.Name()
+		if info.IsDir() || !isModuleFile(name) {
+			continue
+		}
+		// TODO: overrides
+		names = append(names, name)
+	}
+	return names, nil
+}
+
+// parseFileSet reads and parses the named files in the given directory.
+// Files whose checksum matches the one in prev (which may be nil)
+// are not parsed again and their previous results are reused instead.
+// Files which don't exist are omitted from the returned set.
+func parseFileSet(fs filesystem.Filesystem, dir string, names []string, prev *parsedFileSet, logger *log.Logger) (*parsedFileSet, error) {
+	if prev == nil {
+		prev = newParsedFileSet()
+	}
+	parsed := newParsedFileSet()
+
+	for _, name := range names {
+		src, err := fs.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return nil, fmt.Errorf("failed to read %q: %s", name, err)
+		}
+
+		sum := fmt.Sprintf("%x", sha256.Sum256(src))
+		if oldSum, ok := prev.sums[name]; ok && oldSum == sum {
+			parsed.add(name, prev.files[name], prev.diags[name], sum)
+			continue
+		}
+
+		logger.Printf("parsing file %q in %s", name, dir)
+		f, diags := hclsyntax.ParseConfig(src, name, hcl.InitialPos)
+		parsed.add(name, f, diags, sum)
+	}
+
+	return parsed, nil
+}
@@ -0,0 +1,57 @@
+package module
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/hashicorp/terraform-ls/internal/filesystem"
+)
+
+func TestParseFileSet(t *testing.T) {
+	modPath, err := ioutil.TempDir("", "parsed-files")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(modPath)
+
+	writeModuleFile(t, modPath, "main.tf", `variable "first" {}`)
+	writeModuleFile(t, modPath, "invalid.tf", `variable "second" {`)
+	writeModuleFile(t, modPath, "README.md", `# module`)
+
+	fs := filesystem.NewFilesystem()
+	names, err := moduleFileNames(fs, modPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff([]string{"invalid.tf", "main.tf"}, names); diff != "" {
+		t.Fatalf("file names mismatch: %s", diff)
+	}
+
+	parsed, err := parseFileSet(fs, modPath, append(names, "missing.tf"), nil, testLogger())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := parsed.sums["missing.tf"]; ok {
+		t.Fatal("expected missing file to be omitted")
+	}
+	if !parsed.diags["invalid.tf"].HasErrors() {
+		t.Fatal("expected parser diagnostics for invalid file")
+	}
+
+	writeModuleFile(t, modPath, "invalid.tf", `variable "second" {}`)
+	reparsed, err := parseFileSet(fs, modPath, names, parsed, testLogger())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reparsed.files["main.tf"] != parsed.files["main.tf"] {
+		t.Fatal("expected unchanged file to be reused")
+	}
+	if reparsed.diags["invalid.tf"].HasErrors() {
+		t.Fatal("expected changed file to be reparsed")
+	}
+	if reparsed.sums["invalid.tf"] == parsed.sums["invalid.tf"] {
+		t.Fatal("expected checksum of changed file to be updated")
+	}
+}
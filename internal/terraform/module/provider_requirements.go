@@ -0,0 +1,142 @@
+package module
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/go-version"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/zclconf/go-cty/cty"
+)
+
+const defaultProviderRegistryHost = "registry.terraform.io"
+
+// requiredProviderConstraints returns version constraints of providers
+// declared in the given parsed files, keyed by provider address.
+//
+// Constraints are read from required_providers (in both 0.12 and 0.13+
+// syntax) and from version attribute of provider blocks.
+func requiredProviderConstraints(files map[string]*hcl.File) map[string]version.Constraints {
+	sources := make(map[string]string, 0)
+	rawConstraints := make(map[string][]string, 0)
+
+	// iterate in a stable order to produce stable constraints
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		body, ok := files[name].Body.(*hclsyntax.Body)
+		if !ok {
+			continue
+		}
+		for _, block := range body.Blocks {
+			switch block.Type {
+			case "terraform":
+				for _, rpBlock := range block.Body.Blocks {
+					if rpBlock.Type != "required_providers" {
+						continue
+					}
+					for localName, attr := range rpBlock.Body.Attributes {
+						source, constraint := parseProviderRequirement(attr)
+						if source != "" {
+							sources[localName] = source
+						}
+						if constraint != "" {
+							rawConstraints[localName] = append(rawConstraints[localName], constraint)
+						}
+					}
+				}
+			case "provider":
+				if len(block.Labels) != 1 {
+					continue
+				}
+				attr, ok := block.Body.Attributes["version"]
+				if !ok {
+					continue
+				}
+				constraint, ok := stringValue(attr.Expr)
+				if ok && constraint != "" {
+					localName := block.Labels[0]
+					rawConstraints[localName] = append(rawConstraints[localName], constraint)
+				}
+			}
+		}
+	}
+
+	constraints := make(map[string]version.Constraints, 0)
+	for localName, raw := range rawConstraints {
+		addr, ok := providerAddress(localName, sources[localName])
+		if !ok {
+			continue
+		}
+		c, err := version.NewConstraint(strings.Join(raw, ","))
+		if err != nil {
+			continue
+		}
+		constraints[addr] = c
+	}
+
+	return constraints
+}
+
+// parseProviderRequirement returns source address and version constraint
+// from a single entry of required_providers block
+func parseProviderRequirement(attr *hclsyntax.Attribute) (string, string) {
+	val, diags := attr.Expr.Value(nil)
+	if diags.HasErrors() || !val.IsWhollyKnown() || val.IsNull() {
+		return "", ""
+	}
+
+	// Terraform 0.12 syntax, e.g. aws = "~> 2.0"
+	if val.Type() == cty.String {
+		return "", val.AsString()
+	}
+
+	if !val.Type().IsObjectType() {
+		return "", ""
+	}
+
+	var source, constraint string
+	if val.Type().HasAttribute("source") {
+		if v := val.GetAttr("source"); !v.IsNull() && v.Type() == cty.String {
+			source = v.AsString()
+		}
+	}
+	if val.Type().HasAttribute("version") {
+		if v := val.GetAttr("version"); !v.IsNull() && v.Type() == cty.String {
+			constraint = v.AsString()
+		}
+	}
+	return source, constraint
+}
+
+func stringValue(expr hcl.Expression) (string, bool) {
+	val, diags := expr.Value(nil)
+	if diags.HasErrors() || !val.IsWhollyKnown() || val.IsNull() || val.Type() != cty.String {
+		return "", false
+	}
+	return val.AsString(), true
+}
+
+// providerAddress returns fully qualified provider address
+// for the given local name and (optional) source address
+func providerAddress(localName, source string) (string, bool) {
+	if source == "" {
+		// implied to be an official provider
+		return strings.Join([]string{defaultProviderRegistryHost, "hashicorp",
+			strings.ToLower(localName)}, "/"), true
+	}
+
+	parts := strings.Split(strings.ToLower(source), "/")
+	switch len(parts) {
+	case 2:
+		return strings.Join(append([]string{defaultProviderRegistryHost}, parts...), "/"), true
+	case 3:
+		return strings.Join(parts, "/"), true
+	}
+	return "", false
+}
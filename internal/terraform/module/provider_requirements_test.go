@@ -0,0 +1,110 @@
+package module
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+)
+
+func TestRequiredProviderConstraints(t *testing.T) {
+	testCases := []struct {
+		name                string
+		cfg                 string
+		expectedConstraints map[string]string
+	}{
+		{
+			"0.13 syntax",
+			`terraform {
+  required_providers {
+    aws = {
+      source  = "hashicorp/aws"
+      version = "~> 2.0"
+    }
+    custom = {
+      source  = "example.com/Corp/Custom"
+      version = ">= 1.0"
+    }
+    random = {
+      source = "hashicorp/random"
+    }
+  }
+}
+`,
+			map[string]string{
+				"registry.terraform.io/hashicorp/aws": "~> 2.0",
+				"example.com/corp/custom":             ">= 1.0",
+			},
+		},
+		{
+			"0.12 syntax",
+			`terraform {
+  required_providers {
+    google = "~> 3.0"
+  }
+}
+`,
+			map[string]string{
+				"registry.terraform.io/hashicorp/google": "~> 3.0",
+			},
+		},
+		{
+			"provider block",
+			`terraform {
+  required_providers {
+    mycloud = {
+      source  = "mycorp/mycloud"
+      version = ">= 1.0"
+    }
+  }
+}
+provider "mycloud" {
+  version = "< 2.0"
+}
+provider "null" {
+  version = "2.1.2"
+}
+`,
+			map[string]string{
+				"registry.terraform.io/mycorp/mycloud": ">= 1.0,< 2.0",
+				"registry.terraform.io/hashicorp/null": "2.1.2",
+			},
+		},
+		{
+			"invalid",
+			`terraform {
+  required_providers {
+    aws = {
+      source  = "a/b/c/d"
+      version = "~> 2.0"
+    }
+    google = {
+      version = "invalid"
+    }
+  }
+}
+`,
+			map[string]string{},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			f, diags := hclsyntax.ParseConfig([]byte(tc.cfg), "main.tf", hcl.InitialPos)
+			if diags.HasErrors() {
+				t.Fatal(diags)
+			}
+
+			constraints := requiredProviderConstraints(map[string]*hcl.File{"main.tf": f})
+			given := make(map[string]string, len(constraints))
+			for addr, c := range constraints {
+				given[addr] = c.String()
+			}
+
+			if diff := cmp.Diff(tc.expectedConstraints, given); diff != "" {
+				t.Fatalf("constraints mismatch: %s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,46 @@
+package module
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"sort"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+)
+
+// schemaSignature returns a checksum of declarations which affect
+// the merged schema of a module, i.e. terraform and provider blocks
+// and providers implied by resources and data sources, such that
+// the schema needs to be merged again only if the signature changes.
+func schemaSignature(files map[string]*hcl.File) string {
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	h := sha256.New()
+	for _, name := range names {
+		f := files[name]
+		body, ok := f.Body.(*hclsyntax.Body)
+		if !ok {
+			continue
+		}
+
+		fmt.Fprintf(h, "%s\n", name)
+		for _, block := range body.Blocks {
+			switch block.Type {
+			case "terraform", "provider":
+				h.Write(block.Range().SliceBytes(f.Bytes))
+			case "resource", "data":
+				fmt.Fprintf(h, "%s %q\n", block.Type, block.Labels)
+				if attr, ok := block.Body.Attributes["provider"]; ok {
+					h.Write(attr.Range().SliceBytes(f.Bytes))
+				}
+			}
+		}
+	}
+
+	return fmt.Sprintf("%x", h.Sum(nil))
+}
@@ -0,0 +1,113 @@
+package module
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+)
+
+func TestSchemaSignature(t *testing.T) {
+	base := `terraform {
+  required_providers {
+    aws = "~> 3.0"
+  }
+}
+provider "aws" {
+  region = "eu-west-1"
+}
+resource "aws_instance" "web" {
+  ami = "ami-123"
+}
+variable "name" {}
+`
+	testCases := []struct {
+		name            string
+		src             string
+		expectedChanged bool
+	}{
+		{
+			"variable added",
+			base + `variable "other" {}`,
+			false,
+		},
+		{
+			"resource attribute changed",
+			`terraform {
+  required_providers {
+    aws = "~> 3.0"
+  }
+}
+provider "aws" {
+  region = "eu-west-1"
+}
+resource "aws_instance" "web" {
+  ami = "ami-456"
+}
+variable "name" {}
+`,
+			false,
+		},
+		{
+			"provider requirement changed",
+			`terraform {
+  required_providers {
+    aws = "~> 2.0"
+  }
+}
+provider "aws" {
+  region = "eu-west-1"
+}
+resource "aws_instance" "web" {
+  ami = "ami-123"
+}
+variable "name" {}
+`,
+			true,
+		},
+		{
+			"resource of another provider added",
+			base + `resource "google_compute_instance" "web" {}`,
+			true,
+		},
+		{
+			"resource provider set",
+			`terraform {
+  required_providers {
+    aws = "~> 3.0"
+  }
+}
+provider "aws" {
+  region = "eu-west-1"
+}
+resource "aws_instance" "web" {
+  provider = aws.west
+  ami      = "ami-123"
+}
+variable "name" {}
+`,
+			true,
+		},
+	}
+
+	baseSignature := schemaSignature(parseTestFiles(t, base))
+
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("%d-%s", i, tc.name), func(t *testing.T) {
+			signature := schemaSignature(parseTestFiles(t, tc.src))
+			changed := signature != baseSignature
+			if changed != tc.expectedChanged {
+				t.Fatalf("expected signature change: %t, given: %t", tc.expectedChanged, changed)
+			}
+		})
+	}
+}
+
+func parseTestFiles(t *testing.T, src string) map[string]*hcl.File {
+	f, diags := hclsyntax.ParseConfig([]byte(src), "main.tf", hcl.InitialPos)
+	if diags.HasErrors() {
+		t.Fatal(diags)
+	}
+	return map[string]*hcl.File{"main.tf": f}
+}
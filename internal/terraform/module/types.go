@@ -5,9 +5,11 @@ import (
 	"log"
 	"time"
 
+	"github.com/hashicorp/go-version"
 	"github.com/hashicorp/hcl-lang/decoder"
 	"github.com/hashicorp/hcl-lang/schema"
 	"github.com/hashicorp/hcl/v2"
+	tfjson "github.com/hashicorp/terraform-json"
 	"github.com/hashicorp/terraform-ls/internal/filesystem"
 	"github.com/hashicorp/terraform-ls/internal/terraform/exec"
 )
@@ -40,8 +42,13 @@ type ModuleManager interface {
 	SetTerraformExecLogPath(logPath string)
 	SetTerraformExecTimeout(timeout time.Duration)
 
+	LoadProviderSchemas(paths []string) error
+
 	InitAndUpdateModule(ctx context.Context, dir string) (Module, error)
 	AddAndStartLoadingModule(ctx context.Context, dir string) (Module, error)
+	RemoveModule(dir string) error
+	InvalidateModuleCalls(dir string)
+	CallersOfModule(dir string) Modules
 	WorkerPoolSize() int
 	WorkerQueueSize() int
 	ListModules() Modules
@@ -68,22 +75,31 @@ type Module interface {
 	LoadingDone() <-chan struct{}
 	IsKnownPluginLockFile(path string) bool
 	IsKnownModuleManifestFile(path string) bool
+	IsKnownTerraformVersionFile(path string) bool
 	PathsToWatch() []string
 	UpdateProviderSchemaCache(ctx context.Context, lockFile File) error
 	IsProviderSchemaLoaded() bool
 	UpdateModuleManifest(manifestFile File) error
+	UpdateCoreSchema() error
 	Decoder() (*decoder.Decoder, error)
 	DecoderWithSchema(*schema.BodySchema) (*decoder.Decoder, error)
 	MergedSchema() (*schema.BodySchema, error)
 	IsParsed() bool
 	ParseFiles() error
+	ParseFile(name string) error
+	ParsedFiles() map[string]*hcl.File
 	ParsedDiagnostics() map[string]hcl.Diagnostics
+	ParseVariableFiles() map[string]*hcl.File
 	TerraformFormatter() (exec.Formatter, error)
 	HasTerraformDiscoveryFinished() bool
 	IsTerraformAvailable() bool
+	TerraformVersion() *version.Version
+	ProviderVersions() map[string]*version.Version
 	ExecuteTerraformInit(ctx context.Context) error
-	ExecuteTerraformValidate(ctx context.Context) (map[string]hcl.Diagnostics, error)
+	ExecuteTerraformValidate(ctx context.Context) (map[string][]tfjson.Diagnostic, error)
 	Modules() []ModuleRecord
+	ModuleCallDirs() map[string]string
+	ParseModuleCalls() map[string]map[string]*hcl.File
 	HumanReadablePath(string) string
 	WasInitialized() (bool, error)
 }
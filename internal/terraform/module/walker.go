@@ -5,41 +5,67 @@ import (
 	"fmt"
 	"io/ioutil"
 	"log"
-	"os"
 	"path/filepath"
+	"runtime"
+	"strings"
 	"sync"
+	"time"
 )
 
 var (
 	discardLogger = log.New(ioutil.Discard, "", 0)
 
 	// skipDirNames represent directory names which would never contain
-	// plugin/module cache, so it's safe to skip them during the walk
+	// modules to be discovered, so it's safe to skip them during the walk
 	skipDirNames = map[string]bool{
 		".git":                true,
 		".idea":               true,
 		".vscode":             true,
 		"terraform.tfstate.d": true,
+		".terragrunt-cache":   true,
 	}
+
+	// progressInterval is the minimum interval between progress reports
+	progressInterval = 200 * time.Millisecond
 )
 
 type Walker struct {
-	logger *log.Logger
-	sync   bool
+	logger      *log.Logger
+	sync        bool
+	concurrency int
 
 	walking    bool
 	walkingMu  *sync.RWMutex
 	cancelFunc context.CancelFunc
 	doneCh     <-chan struct{}
 
+	rootPath           string
 	excludeModulePaths map[string]bool
+	ignorePatterns     []string
+	dirFunc            func(dir string)
+
+	progress     WalkProgress
+	progressFunc WalkProgressFunc
+	progressMu   *sync.Mutex
+}
+
+// WalkProgress represents progress of the walk
+type WalkProgress struct {
+	DirsWalked   int
+	ModulesFound int
+	CurrentDir   string
+	Done         bool
 }
 
+type WalkProgressFunc func(WalkProgress)
+
 func NewWalker() *Walker {
 	return &Walker{
-		logger:    discardLogger,
-		walkingMu: &sync.RWMutex{},
-		doneCh:    make(chan struct{}, 0),
+		logger:      discardLogger,
+		concurrency: 2 * runtime.NumCPU(),
+		walkingMu:   &sync.RWMutex{},
+		doneCh:      make(chan struct{}, 0),
+		progressMu:  &sync.Mutex{},
 	}
 }
 
@@ -54,6 +80,48 @@ func (w *Walker) SetExcludeModulePaths(excludeModulePaths []string) {
 	}
 }
 
+// SetIgnorePatterns sets .gitignore-style patterns (relative
+// to the walked directory) of paths to skip during the walk,
+// in addition to patterns from any .gitignore files
+func (w *Walker) SetIgnorePatterns(patterns []string) {
+	w.ignorePatterns = patterns
+}
+
+// SetDirFunc sets a function to be called for every walked directory,
+// e.g. so that it can be watched for changes. It may be called concurrently.
+func (w *Walker) SetDirFunc(f func(dir string)) {
+	w.dirFunc = f
+}
+
+// SetConcurrency sets the maximum number of directories
+// which are read in parallel during the walk
+func (w *Walker) SetConcurrency(concurrency int) {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	w.concurrency = concurrency
+}
+
+// SetProgressFunc sets a function to be called periodically
+// with progress of the walk in progress, and once more when it finishes.
+// If no walk is in progress, it is called right away with Done set.
+func (w *Walker) SetProgressFunc(f WalkProgressFunc) {
+	w.progressMu.Lock()
+	defer w.progressMu.Unlock()
+
+	if !w.IsWalking() {
+		w.progressFunc = nil
+		if f != nil {
+			p := w.progress
+			p.Done = true
+			f(p)
+		}
+		return
+	}
+
+	w.progressFunc = f
+}
+
 type WalkFunc func(ctx context.Context, rootModulePath string) error
 
 func (w *Walker) Stop() {
@@ -85,7 +153,11 @@ func (w *Walker) StartWalking(ctx context.Context, path string, wf WalkFunc) err
 	ctx, cancelFunc := context.WithCancel(ctx)
 	w.cancelFunc = cancelFunc
 	w.doneCh = ctx.Done()
+
+	w.progressMu.Lock()
+	w.progress = WalkProgress{}
 	w.setWalking(true)
+	w.progressMu.Unlock()
 
 	if w.sync {
 		w.logger.Printf("synchronously walking through %s", path)
@@ -112,50 +184,328 @@ func (w *Walker) IsWalking() bool {
 	return w.walking
 }
 
+// walkedDir represents a directory to be walked along with
+// ignore rules applicable to it
+type walkedDir struct {
+	path  string
+	rules ignoreRules
+}
+
+// walk finds modules, i.e. directories containing *.tf files
+// or the .terraform directory of an initialized module.
+//
+// Directories are read in parallel, but wf is never called concurrently.
 func (w *Walker) walk(ctx context.Context, rootPath string, wf WalkFunc) error {
+	defer w.finishProgress()
 	defer w.Stop()
 
-	err := filepath.Walk(rootPath, func(path string, info os.FileInfo, err error) error {
-		select {
-		case <-w.doneCh:
-			w.logger.Printf("cancelling walk of %s...", rootPath)
-			return fmt.Errorf("walk cancelled")
-		default:
+	absRoot, err := filepath.Abs(rootPath)
+	if err != nil {
+		return err
+	}
+	w.rootPath = absRoot
+
+	stopReporting := w.startReportingProgress()
+	defer stopReporting()
+
+	err = w.walkTree(ctx, walkedDir{
+		path:  absRoot,
+		rules: parseIgnorePatterns(absRoot, w.ignorePatterns),
+	}, wf)
+	if err != nil && ctx.Err() != nil {
+		w.logger.Printf("cancelling walk of %s...", rootPath)
+	}
+
+	w.logger.Printf("walking of %s finished", rootPath)
+	return err
+}
+
+// WalkDir synchronously walks the given directory, such as one created
+// within the previously walked directory after the walk has finished.
+// Modules are found the same way as during the walk, e.g. following
+// any applicable ignore patterns.
+func (w *Walker) WalkDir(ctx context.Context, dir string, wf WalkFunc) error {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return err
+	}
+
+	rules := w.rulesForDir(dir)
+	if w.isDirIgnored(dir, rules) {
+		w.logger.Printf("ignoring %s", dir)
+		return nil
+	}
+
+	return w.walkTree(ctx, walkedDir{
+		path:  dir,
+		rules: rules,
+	}, wf)
+}
+
+// IsDirIgnored returns true if the given directory would not be
+// walked, e.g. because it is excluded or matches an ignore pattern
+func (w *Walker) IsDirIgnored(dir string) bool {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return true
+	}
+	return w.isDirIgnored(dir, w.rulesForDir(dir))
+}
+
+func (w *Walker) isDirIgnored(dir string, rules ignoreRules) bool {
+	if isSkippableDir(filepath.Base(dir)) {
+		return true
+	}
+	if _, ok := w.excludeModulePaths[dir]; ok {
+		return true
+	}
+	return rules.isIgnored(dir, true)
+}
+
+// rulesForDir returns ignore rules applicable to the given directory,
+// i.e. configured patterns and .gitignore files of its parent directories
+// within the root of the walk
+func (w *Walker) rulesForDir(dir string) ignoreRules {
+	root := w.rootPath
+	rel, err := filepath.Rel(root, dir)
+	if root == "" || err != nil || strings.HasPrefix(rel, "..") || rel == "." {
+		return parseIgnorePatterns(dir, w.ignorePatterns)
+	}
+
+	rules := parseIgnorePatterns(root, w.ignorePatterns)
+
+	parentDir := root
+	parts := strings.Split(filepath.Dir(rel), string(filepath.Separator))
+	for i := 0; ; i++ {
+		gitignoreRules, err := readGitignore(parentDir)
+		if err == nil {
+			rules = append(rules, gitignoreRules...)
+		}
+		if i >= len(parts) || parts[i] == "." {
+			break
 		}
+		parentDir = filepath.Join(parentDir, parts[i])
+	}
 
-		if err != nil {
-			w.logger.Printf("unable to access %s: %s", path, err.Error())
-			return nil
+	return rules
+}
+
+// walkTree walks the given directory and all its subdirectories
+func (w *Walker) walkTree(ctx context.Context, root walkedDir, wf WalkFunc) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	queue := newDirQueue()
+	queue.Push(root)
+
+	go func() {
+		<-ctx.Done()
+		queue.Close()
+	}()
+
+	var (
+		// wfMu guards walkErr and ensures wf is not called concurrently
+		wfMu     sync.Mutex
+		walkErr  error
+		workerWg sync.WaitGroup
+	)
+	callWalkFunc := func(dir string) {
+		wfMu.Lock()
+		defer wfMu.Unlock()
+
+		// other workers may still find modules after the walk failed
+		if walkErr != nil || ctx.Err() != nil {
+			return
 		}
 
-		dir, err := filepath.Abs(filepath.Dir(path))
+		err := wf(ctx, dir)
 		if err != nil {
-			return err
+			walkErr = err
+			queue.Close()
 		}
+	}
 
-		if _, ok := w.excludeModulePaths[dir]; ok {
-			return filepath.SkipDir
-		}
+	for i := 0; i < w.concurrency; i++ {
+		workerWg.Add(1)
+		go func() {
+			defer workerWg.Done()
+			for {
+				dir, ok := queue.Pop()
+				if !ok {
+					return
+				}
+
+				isModule, subDirs := w.readDir(dir)
+				if isModule {
+					w.logger.Printf("found module %s", dir.path)
+					w.moduleFound()
+					callWalkFunc(dir.path)
+				}
+
+				queue.Push(subDirs...)
+				queue.Done()
+			}
+		}()
+	}
+	workerWg.Wait()
+
+	if walkErr == nil && ctx.Err() != nil {
+		walkErr = fmt.Errorf("walk cancelled")
+	}
+
+	return walkErr
+}
+
+// readDir returns whether the given directory is a module
+// along with any subdirectories to be walked
+func (w *Walker) readDir(dir walkedDir) (bool, []walkedDir) {
+	w.dirWalked(dir.path)
+
+	infos, err := ioutil.ReadDir(dir.path)
+	if err != nil {
+		w.logger.Printf("unable to access %s: %s", dir.path, err.Error())
+		return false, nil
+	}
 
-		if info.Name() == ".terraform" {
-			w.logger.Printf("found module %s", dir)
-			return wf(ctx, dir)
+	if w.dirFunc != nil {
+		w.dirFunc(dir.path)
+	}
+
+	gitignoreRules, err := readGitignore(dir.path)
+	if err != nil {
+		w.logger.Printf("unable to read %s in %s: %s", gitignoreFile, dir.path, err)
+	}
+	rules := dir.rules
+	if len(gitignoreRules) > 0 {
+		rules = make(ignoreRules, 0, len(dir.rules)+len(gitignoreRules))
+		rules = append(rules, dir.rules...)
+		rules = append(rules, gitignoreRules...)
+	}
+
+	isModule := false
+	subDirs := make([]walkedDir, 0)
+	for _, info := range infos {
+		path := filepath.Join(dir.path, info.Name())
+
+		if info.Name() == ".terraform" && info.IsDir() {
+			// Vendored modules in .terraform/modules are never walked
+			// as these are already known from the module manifest
+			isModule = true
+			continue
 		}
 
 		if !info.IsDir() {
-			// All files are skipped, we only care about dirs
-			return nil
+			if isModuleFile(info.Name()) && !rules.isIgnored(path, false) {
+				isModule = true
+			}
+			continue
 		}
 
 		if isSkippableDir(info.Name()) {
 			w.logger.Printf("skipping %s", path)
-			return filepath.SkipDir
+			continue
+		}
+		if _, ok := w.excludeModulePaths[path]; ok {
+			w.logger.Printf("excluding %s", path)
+			continue
+		}
+		if rules.isIgnored(path, true) {
+			w.logger.Printf("ignoring %s", path)
+			continue
 		}
 
-		return nil
-	})
-	w.logger.Printf("walking of %s finished", rootPath)
-	return err
+		subDirs = append(subDirs, walkedDir{
+			path:  path,
+			rules: rules,
+		})
+	}
+
+	return isModule, subDirs
+}
+
+func (w *Walker) dirWalked(path string) {
+	w.progressMu.Lock()
+	defer w.progressMu.Unlock()
+	w.progress.DirsWalked++
+	w.progress.CurrentDir = path
+}
+
+func (w *Walker) moduleFound() {
+	w.progressMu.Lock()
+	defer w.progressMu.Unlock()
+	w.progress.ModulesFound++
+}
+
+// startReportingProgress periodically reports progress
+// until the returned function is called
+func (w *Walker) startReportingProgress() func() {
+	ticker := time.NewTicker(progressInterval)
+	stopCh := make(chan struct{})
+
+	go func() {
+		var lastProgress WalkProgress
+		for {
+			select {
+			case <-stopCh:
+				return
+			case <-ticker.C:
+			}
+
+			w.progressMu.Lock()
+			if w.progressFunc != nil && w.progress != lastProgress {
+				lastProgress = w.progress
+				w.progressFunc(w.progress)
+			}
+			w.progressMu.Unlock()
+		}
+	}()
+
+	return func() {
+		ticker.Stop()
+		close(stopCh)
+	}
+}
+
+func (w *Walker) finishProgress() {
+	w.progressMu.Lock()
+	defer w.progressMu.Unlock()
+
+	if w.progressFunc != nil {
+		p := w.progress
+		p.Done = true
+		w.progressFunc(p)
+		w.progressFunc = nil
+	}
+}
+
+// IsModuleDir returns true if the given directory would be found
+// as a module by the walker (not considering any ignore patterns)
+func IsModuleDir(dir string) (bool, error) {
+	infos, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return false, err
+	}
+
+	for _, info := range infos {
+		if info.Name() == ".terraform" && info.IsDir() {
+			return true, nil
+		}
+		if !info.IsDir() && isModuleFile(info.Name()) {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// IsModuleFile returns true if the given filename represents
+// configuration file which makes a directory a module
+func IsModuleFile(name string) bool {
+	return isModuleFile(name)
+}
+
+func isModuleFile(name string) bool {
+	return strings.HasSuffix(name, ".tf") && !IsIgnoredFile(name)
 }
 
 func isSkippableDir(dirName string) bool {
@@ -0,0 +1,168 @@
+package module
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestWalker_walk(t *testing.T) {
+	root, err := ioutil.TempDir("", "walker")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(root)
+	root, err = filepath.EvalSymlinks(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	files := map[string]string{
+		"main.tf":    "",
+		".gitignore": "/generated/\n",
+		"initialized/.terraform/modules/vendored/a.tf": "",
+		"initialized/main.tf":                          "",
+		"uninitialized/variables.tf":                   "",
+		"uninitialized/nested/outputs.tf":              "",
+		"no-config/README.md":                          "",
+		"only-backup/main.tf~":                         "",
+		"generated/main.tf":                            "",
+		"examples/basic/main.tf":                       "",
+		"live/.terragrunt-cache/abc/main.tf":           "",
+	}
+	for path, content := range files {
+		fullPath := filepath.Join(root, filepath.FromSlash(path))
+		err := os.MkdirAll(filepath.Dir(fullPath), 0755)
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = ioutil.WriteFile(fullPath, []byte(content), 0644)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	w := MockWalker()
+	w.SetLogger(testLogger())
+	w.SetIgnorePatterns([]string{"examples/"})
+
+	modules := make([]string, 0)
+	err = w.StartWalking(context.Background(), root, func(ctx context.Context, dir string) error {
+		modules = append(modules, dir)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sort.Strings(modules)
+
+	expectedModules := []string{
+		root,
+		filepath.Join(root, "initialized"),
+		filepath.Join(root, "uninitialized"),
+		filepath.Join(root, "uninitialized", "nested"),
+	}
+	if diff := cmp.Diff(expectedModules, modules); diff != "" {
+		t.Fatalf("modules don't match: %s", diff)
+	}
+}
+
+func TestWalker_progress(t *testing.T) {
+	root, err := ioutil.TempDir("", "walker")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(root)
+
+	for _, dir := range []string{"first", "second", filepath.Join("second", "nested"), "third"} {
+		err := os.MkdirAll(filepath.Join(root, dir), 0755)
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = ioutil.WriteFile(filepath.Join(root, dir, "main.tf"), []byte{}, 0644)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	w := MockWalker()
+	w.SetLogger(testLogger())
+	w.SetConcurrency(3)
+
+	var lastProgress WalkProgress
+	err = w.StartWalking(context.Background(), root, func(ctx context.Context, dir string) error {
+		// attach while walking, the same way as server does after initialization
+		w.SetProgressFunc(func(p WalkProgress) {
+			lastProgress = p
+		})
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !lastProgress.Done {
+		t.Fatal("expected final progress to be reported")
+	}
+	if lastProgress.DirsWalked != 5 {
+		t.Fatalf("expected 5 directories walked, given %d", lastProgress.DirsWalked)
+	}
+	if lastProgress.ModulesFound != 4 {
+		t.Fatalf("expected 4 modules found, given %d", lastProgress.ModulesFound)
+	}
+
+	// once finished, progress is reported right away
+	var progress WalkProgress
+	w.SetProgressFunc(func(p WalkProgress) {
+		progress = p
+	})
+	if diff := cmp.Diff(lastProgress, progress); diff != "" {
+		t.Fatalf("progress mismatch: %s", diff)
+	}
+}
+
+func TestWalker_walkFuncError(t *testing.T) {
+	root, err := ioutil.TempDir("", "walker")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(root)
+
+	for i := 0; i < 10; i++ {
+		dir := filepath.Join(root, fmt.Sprintf("mod-%d", i))
+		err := os.MkdirAll(dir, 0755)
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = ioutil.WriteFile(filepath.Join(dir, "main.tf"), []byte{}, 0644)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	w := MockWalker()
+	w.SetLogger(testLogger())
+
+	calls := 0
+	expectedErr := errors.New("failed")
+	err = w.StartWalking(context.Background(), root, func(ctx context.Context, dir string) error {
+		calls++
+		return expectedErr
+	})
+	if err != expectedErr {
+		t.Fatalf("expected error %q, given %v", expectedErr, err)
+	}
+	if calls != 1 {
+		t.Fatalf("expected walk to stop after first error, %d calls made", calls)
+	}
+	if w.IsWalking() {
+		t.Fatal("expected walker to stop")
+	}
+}
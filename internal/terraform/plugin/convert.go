@@ -0,0 +1,138 @@
+package plugin
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	tfjson "github.com/hashicorp/terraform-json"
+	ctyjson "github.com/zclconf/go-cty/cty/json"
+)
+
+// toProviderSchema converts the schema obtained from a provider
+// into the shape of "terraform providers schema -json" output
+func toProviderSchema(resp *getProviderSchemaResponse) (*tfjson.ProviderSchema, error) {
+	var errs []string
+	for _, diag := range resp.Diagnostics {
+		if diag.Severity == diagnosticSeverityError {
+			errs = append(errs, strings.TrimSpace(diag.Summary+": "+diag.Detail))
+		}
+	}
+	if len(errs) > 0 {
+		return nil, errors.New(strings.Join(errs, "; "))
+	}
+
+	ps := &tfjson.ProviderSchema{
+		ResourceSchemas:   make(map[string]*tfjson.Schema, len(resp.ResourceSchemas)),
+		DataSourceSchemas: make(map[string]*tfjson.Schema, len(resp.DataSourceSchemas)),
+	}
+
+	var err error
+	ps.ConfigSchema, err = toSchema(resp.Provider)
+	if err != nil {
+		return nil, fmt.Errorf("provider: %w", err)
+	}
+	for name, s := range resp.ResourceSchemas {
+		ps.ResourceSchemas[name], err = toSchema(s)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+	}
+	for name, s := range resp.DataSourceSchemas {
+		ps.DataSourceSchemas[name], err = toSchema(s)
+		if err != nil {
+			return nil, fmt.Errorf("data.%s: %w", name, err)
+		}
+	}
+
+	return ps, nil
+}
+
+func toSchema(s *schema) (*tfjson.Schema, error) {
+	if s == nil {
+		return nil, nil
+	}
+
+	b, err := toSchemaBlock(s.Block)
+	if err != nil {
+		return nil, err
+	}
+
+	return &tfjson.Schema{
+		Version: uint64(s.Version),
+		Block:   b,
+	}, nil
+}
+
+func toSchemaBlock(b *block) (*tfjson.SchemaBlock, error) {
+	if b == nil {
+		return nil, nil
+	}
+
+	sb := &tfjson.SchemaBlock{
+		Description:     b.Description,
+		DescriptionKind: toDescriptionKind(b.DescriptionKind),
+		Deprecated:      b.Deprecated,
+	}
+
+	if len(b.Attributes) > 0 {
+		sb.Attributes = make(map[string]*tfjson.SchemaAttribute, len(b.Attributes))
+	}
+	for _, attr := range b.Attributes {
+		ty, err := ctyjson.UnmarshalType(attr.Type)
+		if err != nil {
+			return nil, fmt.Errorf("%s: invalid type: %w", attr.Name, err)
+		}
+		sb.Attributes[attr.Name] = &tfjson.SchemaAttribute{
+			AttributeType:   ty,
+			Description:     attr.Description,
+			DescriptionKind: toDescriptionKind(attr.DescriptionKind),
+			Deprecated:      attr.Deprecated,
+			Required:        attr.Required,
+			Optional:        attr.Optional,
+			Computed:        attr.Computed,
+			Sensitive:       attr.Sensitive,
+		}
+	}
+
+	if len(b.BlockTypes) > 0 {
+		sb.NestedBlocks = make(map[string]*tfjson.SchemaBlockType, len(b.BlockTypes))
+	}
+	for _, nb := range b.BlockTypes {
+		nested, err := toSchemaBlock(nb.Block)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", nb.TypeName, err)
+		}
+		sb.NestedBlocks[nb.TypeName] = &tfjson.SchemaBlockType{
+			NestingMode: toNestingMode(nb.Nesting),
+			Block:       nested,
+			MinItems:    uint64(nb.MinItems),
+			MaxItems:    uint64(nb.MaxItems),
+		}
+	}
+
+	return sb, nil
+}
+
+func toDescriptionKind(kind int32) tfjson.SchemaDescriptionKind {
+	if kind == descriptionKindMarkdown {
+		return tfjson.SchemaDescriptionKindMarkdown
+	}
+	return tfjson.SchemaDescriptionKindPlain
+}
+
+func toNestingMode(nesting int32) tfjson.SchemaNestingMode {
+	switch nesting {
+	case nestingSingle:
+		return tfjson.SchemaNestingModeSingle
+	case nestingList:
+		return tfjson.SchemaNestingModeList
+	case nestingSet:
+		return tfjson.SchemaNestingModeSet
+	case nestingMap:
+		return tfjson.SchemaNestingModeMap
+	case nestingGroup:
+		return tfjson.SchemaNestingMode("group")
+	}
+	return ""
+}
@@ -0,0 +1,170 @@
+package plugin
+
+import (
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/google/go-cmp/cmp"
+	tfjson "github.com/hashicorp/terraform-json"
+	"github.com/zclconf/go-cty/cty"
+)
+
+func TestToProviderSchema(t *testing.T) {
+	resp := &getProviderSchemaResponse{
+		Provider: &schema{
+			Block: &block{
+				Attributes: []*attribute{
+					{
+						Name:            "region",
+						Type:            []byte(`"string"`),
+						Description:     "AWS _region_",
+						DescriptionKind: descriptionKindMarkdown,
+						Optional:        true,
+					},
+				},
+			},
+		},
+		ResourceSchemas: map[string]*schema{
+			"aws_instance": {
+				Version: 1,
+				Block: &block{
+					Attributes: []*attribute{
+						{
+							Name:     "tags",
+							Type:     []byte(`["map","string"]`),
+							Optional: true,
+							Computed: true,
+						},
+					},
+					BlockTypes: []*nestedBlock{
+						{
+							TypeName: "ebs_block_device",
+							Nesting:  nestingSet,
+							MinItems: 1,
+							Block: &block{
+								Attributes: []*attribute{
+									{
+										Name:      "device_name",
+										Type:      []byte(`"string"`),
+										Required:  true,
+										Sensitive: true,
+									},
+								},
+								Deprecated: true,
+							},
+						},
+					},
+				},
+			},
+		},
+		DataSourceSchemas: map[string]*schema{
+			"aws_ami": {
+				Block: &block{
+					Description: "AMI",
+				},
+			},
+		},
+	}
+
+	// ensure messages survive the wire format
+	b, err := proto.Marshal(resp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	decoded := &getProviderSchemaResponse{}
+	err = proto.Unmarshal(b, decoded)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ps, err := toProviderSchema(decoded)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expectedSchema := &tfjson.ProviderSchema{
+		ConfigSchema: &tfjson.Schema{
+			Block: &tfjson.SchemaBlock{
+				Attributes: map[string]*tfjson.SchemaAttribute{
+					"region": {
+						AttributeType:   cty.String,
+						Description:     "AWS _region_",
+						DescriptionKind: tfjson.SchemaDescriptionKindMarkdown,
+						Optional:        true,
+					},
+				},
+				DescriptionKind: tfjson.SchemaDescriptionKindPlain,
+			},
+		},
+		ResourceSchemas: map[string]*tfjson.Schema{
+			"aws_instance": {
+				Version: 1,
+				Block: &tfjson.SchemaBlock{
+					Attributes: map[string]*tfjson.SchemaAttribute{
+						"tags": {
+							AttributeType:   cty.Map(cty.String),
+							DescriptionKind: tfjson.SchemaDescriptionKindPlain,
+							Optional:        true,
+							Computed:        true,
+						},
+					},
+					NestedBlocks: map[string]*tfjson.SchemaBlockType{
+						"ebs_block_device": {
+							NestingMode: tfjson.SchemaNestingModeSet,
+							MinItems:    1,
+							Block: &tfjson.SchemaBlock{
+								Attributes: map[string]*tfjson.SchemaAttribute{
+									"device_name": {
+										AttributeType:   cty.String,
+										DescriptionKind: tfjson.SchemaDescriptionKindPlain,
+										Required:        true,
+										Sensitive:       true,
+									},
+								},
+								DescriptionKind: tfjson.SchemaDescriptionKindPlain,
+								Deprecated:      true,
+							},
+						},
+					},
+					DescriptionKind: tfjson.SchemaDescriptionKindPlain,
+				},
+			},
+		},
+		DataSourceSchemas: map[string]*tfjson.Schema{
+			"aws_ami": {
+				Block: &tfjson.SchemaBlock{
+					Description:     "AMI",
+					DescriptionKind: tfjson.SchemaDescriptionKindPlain,
+				},
+			},
+		},
+	}
+
+	opts := cmp.Comparer(func(x, y cty.Type) bool {
+		return x.Equals(y)
+	})
+	if diff := cmp.Diff(expectedSchema, ps, opts); diff != "" {
+		t.Fatalf("schema mismatch: %s", diff)
+	}
+}
+
+func TestToProviderSchema_diagnostics(t *testing.T) {
+	resp := &getProviderSchemaResponse{
+		Diagnostics: []*diagnostic{
+			{
+				Severity: diagnosticSeverityError,
+				Summary:  "Failed",
+				Detail:   "something went wrong",
+			},
+		},
+	}
+
+	_, err := toProviderSchema(resp)
+	if err == nil {
+		t.Fatal("expected error")
+	}
+	expectedErr := "Failed: something went wrong"
+	if err.Error() != expectedErr {
+		t.Fatalf("error mismatch: %q, expected %q", err.Error(), expectedErr)
+	}
+}
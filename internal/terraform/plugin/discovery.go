@@ -0,0 +1,165 @@
+package plugin
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/hashicorp/go-version"
+)
+
+const binaryPrefix = "terraform-provider-"
+
+// Provider represents a provider plugin installed in a module
+type Provider struct {
+	// Address is the provider address as used in schemas
+	// obtained from Terraform, e.g. registry.terraform.io/hashicorp/aws
+	// or just "aws" for plugins installed by Terraform 0.12
+	Address string
+	Version *version.Version
+	Path    string
+}
+
+// FindProviders returns provider plugins installed
+// in the data directory (.terraform) of the given module
+func FindProviders(modPath string) []Provider {
+	dataDir := filepath.Join(modPath, ".terraform")
+	platform := runtime.GOOS + "_" + runtime.GOARCH
+
+	providers := make([]Provider, 0)
+	seen := make(map[string]bool, 0)
+	add := func(p Provider) {
+		if seen[p.Address] {
+			return
+		}
+		seen[p.Address] = true
+		providers = append(providers, p)
+	}
+
+	// Terraform >= 0.14
+	for _, p := range findHierarchicalProviders(filepath.Join(dataDir, "providers"), platform) {
+		add(p)
+	}
+	// Terraform 0.13
+	for _, p := range findHierarchicalProviders(filepath.Join(dataDir, "plugins"), platform) {
+		add(p)
+	}
+	// Terraform 0.12
+	for _, p := range findLegacyProviders(filepath.Join(dataDir, "plugins", platform)) {
+		add(p)
+	}
+
+	return providers
+}
+
+// findHierarchicalProviders finds providers installed in
+// the <host>/<namespace>/<type>/<version>/<os_arch> hierarchy
+func findHierarchicalProviders(dir, platform string) []Provider {
+	providers := make([]Provider, 0)
+
+	pattern := filepath.Join(dir, "*", "*", "*", "*", platform)
+	pkgDirs, err := filepath.Glob(pattern)
+	if err != nil {
+		return providers
+	}
+
+	for _, pkgDir := range pkgDirs {
+		rel, err := filepath.Rel(dir, pkgDir)
+		if err != nil {
+			continue
+		}
+		parts := strings.Split(filepath.ToSlash(rel), "/")
+		host, namespace, typeName, rawVersion := parts[0], parts[1], parts[2], parts[3]
+
+		v, err := version.NewVersion(rawVersion)
+		if err != nil {
+			continue
+		}
+
+		path, ok := findBinary(pkgDir, typeName)
+		if !ok {
+			continue
+		}
+
+		providers = append(providers, Provider{
+			Address: strings.Join([]string{host, namespace, typeName}, "/"),
+			Version: v,
+			Path:    path,
+		})
+	}
+
+	return providers
+}
+
+// findLegacyProviders finds providers installed by Terraform 0.12,
+// i.e. binaries named terraform-provider-<type>_v<version>_x<protocol>
+func findLegacyProviders(dir string) []Provider {
+	providers := make([]Provider, 0)
+
+	infos, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return providers
+	}
+
+	for _, info := range infos {
+		name := info.Name()
+		if !strings.HasPrefix(name, binaryPrefix) {
+			continue
+		}
+		name = strings.TrimSuffix(name, ".exe")
+		parts := strings.SplitN(strings.TrimPrefix(name, binaryPrefix), "_", 3)
+		if len(parts) < 2 || !strings.HasPrefix(parts[1], "v") {
+			continue
+		}
+
+		v, err := version.NewVersion(strings.TrimPrefix(parts[1], "v"))
+		if err != nil {
+			continue
+		}
+
+		path := filepath.Join(dir, info.Name())
+		if !isExecutable(path) {
+			continue
+		}
+
+		providers = append(providers, Provider{
+			Address: parts[0],
+			Version: v,
+			Path:    path,
+		})
+	}
+
+	return providers
+}
+
+func findBinary(dir, typeName string) (string, bool) {
+	infos, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return "", false
+	}
+	for _, info := range infos {
+		if !strings.HasPrefix(info.Name(), binaryPrefix+typeName) {
+			continue
+		}
+		path := filepath.Join(dir, info.Name())
+		if isExecutable(path) {
+			return path, true
+		}
+	}
+	return "", false
+}
+
+func isExecutable(path string) bool {
+	// Stat follows symlinks, which Terraform uses
+	// for providers from local filesystem mirrors
+	fi, err := os.Stat(path)
+	if err != nil || fi.IsDir() {
+		return false
+	}
+	if runtime.GOOS == "windows" {
+		return strings.HasSuffix(path, ".exe")
+	}
+	return fi.Mode()&0111 != 0
+}
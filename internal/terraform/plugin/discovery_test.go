@@ -0,0 +1,86 @@
+package plugin
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/hashicorp/go-version"
+)
+
+func TestFindProviders(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("test binaries are not executable on windows")
+	}
+
+	modPath, err := ioutil.TempDir("", "plugins")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(modPath)
+	platform := runtime.GOOS + "_" + runtime.GOARCH
+
+	// Terraform 0.14
+	createBinary(t, filepath.Join(modPath, ".terraform", "providers",
+		"registry.terraform.io", "hashicorp", "aws", "3.21.0", platform,
+		"terraform-provider-aws_v3.21.0_x5"))
+	// Terraform 0.13
+	createBinary(t, filepath.Join(modPath, ".terraform", "plugins",
+		"registry.terraform.io", "hashicorp", "random", "3.0.0", platform,
+		"terraform-provider-random_v3.0.0_x5"))
+	// Terraform 0.12
+	createBinary(t, filepath.Join(modPath, ".terraform", "plugins", platform,
+		"terraform-provider-null_v2.1.2_x4"))
+	// lock file and other platforms are ignored
+	createBinary(t, filepath.Join(modPath, ".terraform", "plugins", platform,
+		"lock.json"))
+	createBinary(t, filepath.Join(modPath, ".terraform", "providers",
+		"registry.terraform.io", "hashicorp", "google", "3.51.0", "plan9_386",
+		"terraform-provider-google_v3.51.0_x5"))
+
+	providers := FindProviders(modPath)
+
+	expectedProviders := []Provider{
+		{
+			Address: "registry.terraform.io/hashicorp/aws",
+			Version: version.Must(version.NewVersion("3.21.0")),
+			Path: filepath.Join(modPath, ".terraform", "providers",
+				"registry.terraform.io", "hashicorp", "aws", "3.21.0", platform,
+				"terraform-provider-aws_v3.21.0_x5"),
+		},
+		{
+			Address: "registry.terraform.io/hashicorp/random",
+			Version: version.Must(version.NewVersion("3.0.0")),
+			Path: filepath.Join(modPath, ".terraform", "plugins",
+				"registry.terraform.io", "hashicorp", "random", "3.0.0", platform,
+				"terraform-provider-random_v3.0.0_x5"),
+		},
+		{
+			Address: "null",
+			Version: version.Must(version.NewVersion("2.1.2")),
+			Path: filepath.Join(modPath, ".terraform", "plugins", platform,
+				"terraform-provider-null_v2.1.2_x4"),
+		},
+	}
+
+	opts := cmp.Comparer(func(x, y *version.Version) bool {
+		return x.Equal(y)
+	})
+	if diff := cmp.Diff(expectedProviders, providers, opts); diff != "" {
+		t.Fatalf("providers mismatch: %s", diff)
+	}
+}
+
+func createBinary(t *testing.T, path string) {
+	err := os.MkdirAll(filepath.Dir(path), 0755)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = ioutil.WriteFile(path, []byte{}, 0755)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,4 @@
+// Package plugin obtains provider schemas directly from installed
+// provider plugins over the go-plugin gRPC protocol (tfplugin5),
+// i.e. without the need for Terraform itself
+package plugin
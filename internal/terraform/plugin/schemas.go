@@ -0,0 +1,125 @@
+package plugin
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os/exec"
+	"time"
+
+	"github.com/hashicorp/go-hclog"
+	goplugin "github.com/hashicorp/go-plugin"
+	tfjson "github.com/hashicorp/terraform-json"
+	"google.golang.org/grpc"
+)
+
+const getSchemaMethod = "/tfplugin5.Provider/GetSchema"
+
+// handshake matches the handshake of Terraform
+// and providers using plugin protocol version 5
+var handshake = goplugin.HandshakeConfig{
+	ProtocolVersion:  5,
+	MagicCookieKey:   "TF_PLUGIN_MAGIC_COOKIE",
+	MagicCookieValue: "d602bf8f470bc67ca7faa0386276bbdd4330efa8d6",
+}
+
+var defaultTimeout = 30 * time.Second
+
+// SchemaSource obtains schemas by launching provider plugins directly
+type SchemaSource struct {
+	logger  *log.Logger
+	timeout time.Duration
+}
+
+func NewSchemaSource() *SchemaSource {
+	return &SchemaSource{
+		logger:  log.New(ioutil.Discard, "", 0),
+		timeout: defaultTimeout,
+	}
+}
+
+func (s *SchemaSource) SetLogger(logger *log.Logger) {
+	s.logger = logger
+}
+
+func (s *SchemaSource) SetTimeout(timeout time.Duration) {
+	s.timeout = timeout
+}
+
+// ProviderSchemas launches each of the given providers and returns
+// their schemas in the same shape as "terraform providers schema -json"
+func (s *SchemaSource) ProviderSchemas(ctx context.Context, providers []Provider) (*tfjson.ProviderSchemas, error) {
+	if len(providers) == 0 {
+		return nil, errors.New("no provider plugins found")
+	}
+
+	ps := &tfjson.ProviderSchemas{
+		FormatVersion: "0.1",
+		Schemas:       make(map[string]*tfjson.ProviderSchema, len(providers)),
+	}
+	for _, p := range providers {
+		schema, err := s.providerSchema(ctx, p)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", p.Address, err)
+		}
+		ps.Schemas[p.Address] = schema
+	}
+
+	return ps, nil
+}
+
+func (s *SchemaSource) providerSchema(ctx context.Context, p Provider) (*tfjson.ProviderSchema, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+
+	client := goplugin.NewClient(&goplugin.ClientConfig{
+		HandshakeConfig: handshake,
+		Plugins: map[string]goplugin.Plugin{
+			"provider": &grpcProviderPlugin{},
+		},
+		Cmd:              exec.CommandContext(ctx, p.Path),
+		AllowedProtocols: []goplugin.Protocol{goplugin.ProtocolGRPC},
+		AutoMTLS:         true,
+		StartTimeout:     s.timeout,
+		Logger: hclog.New(&hclog.LoggerOptions{
+			Name:   "plugin",
+			Output: ioutil.Discard,
+		}),
+	})
+	defer client.Kill()
+
+	s.logger.Printf("launching provider plugin %s", p.Path)
+	rpcClient, err := client.Client()
+	if err != nil {
+		return nil, err
+	}
+	raw, err := rpcClient.Dispense("provider")
+	if err != nil {
+		return nil, err
+	}
+	conn := raw.(*grpc.ClientConn)
+
+	resp := &getProviderSchemaResponse{}
+	err = conn.Invoke(ctx, getSchemaMethod, &getProviderSchemaRequest{}, resp)
+	if err != nil {
+		return nil, err
+	}
+
+	return toProviderSchema(resp)
+}
+
+// grpcProviderPlugin is the client side of the provider plugin
+// which merely exposes the underlying gRPC connection
+type grpcProviderPlugin struct {
+	goplugin.NetRPCUnsupportedPlugin
+}
+
+func (p *grpcProviderPlugin) GRPCServer(*goplugin.GRPCBroker, *grpc.Server) error {
+	return errors.New("provider plugin server is not implemented")
+}
+
+func (p *grpcProviderPlugin) GRPCClient(_ context.Context, _ *goplugin.GRPCBroker, conn *grpc.ClientConn) (interface{}, error) {
+	return conn, nil
+}
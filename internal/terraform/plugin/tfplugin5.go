@@ -0,0 +1,98 @@
+package plugin
+
+import (
+	"github.com/golang/protobuf/proto"
+)
+
+// The following types represent the subset of messages of the tfplugin5
+// protocol needed to obtain provider schemas. Only fields which are used
+// are declared, any other fields are skipped when decoding.
+
+const (
+	nestingSingle = 1
+	nestingList   = 2
+	nestingSet    = 3
+	nestingMap    = 4
+	nestingGroup  = 5
+
+	descriptionKindMarkdown = 1
+
+	diagnosticSeverityError = 1
+)
+
+type getProviderSchemaRequest struct{}
+
+func (m *getProviderSchemaRequest) Reset()         { *m = getProviderSchemaRequest{} }
+func (m *getProviderSchemaRequest) String() string { return proto.CompactTextString(m) }
+func (*getProviderSchemaRequest) ProtoMessage()    {}
+
+type getProviderSchemaResponse struct {
+	Provider          *schema            `protobuf:"bytes,1,opt,name=provider,proto3"`
+	ResourceSchemas   map[string]*schema `protobuf:"bytes,2,rep,name=resource_schemas,proto3" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+	DataSourceSchemas map[string]*schema `protobuf:"bytes,3,rep,name=data_source_schemas,proto3" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+	Diagnostics       []*diagnostic      `protobuf:"bytes,4,rep,name=diagnostics,proto3"`
+}
+
+func (m *getProviderSchemaResponse) Reset()         { *m = getProviderSchemaResponse{} }
+func (m *getProviderSchemaResponse) String() string { return proto.CompactTextString(m) }
+func (*getProviderSchemaResponse) ProtoMessage()    {}
+
+type schema struct {
+	Version int64  `protobuf:"varint,1,opt,name=version,proto3"`
+	Block   *block `protobuf:"bytes,2,opt,name=block,proto3"`
+}
+
+func (m *schema) Reset()         { *m = schema{} }
+func (m *schema) String() string { return proto.CompactTextString(m) }
+func (*schema) ProtoMessage()    {}
+
+type block struct {
+	Version         int64          `protobuf:"varint,1,opt,name=version,proto3"`
+	Attributes      []*attribute   `protobuf:"bytes,2,rep,name=attributes,proto3"`
+	BlockTypes      []*nestedBlock `protobuf:"bytes,3,rep,name=block_types,proto3"`
+	Description     string         `protobuf:"bytes,4,opt,name=description,proto3"`
+	DescriptionKind int32          `protobuf:"varint,5,opt,name=description_kind,proto3"`
+	Deprecated      bool           `protobuf:"varint,6,opt,name=deprecated,proto3"`
+}
+
+func (m *block) Reset()         { *m = block{} }
+func (m *block) String() string { return proto.CompactTextString(m) }
+func (*block) ProtoMessage()    {}
+
+type attribute struct {
+	Name            string `protobuf:"bytes,1,opt,name=name,proto3"`
+	Type            []byte `protobuf:"bytes,2,opt,name=type,proto3"`
+	Description     string `protobuf:"bytes,3,opt,name=description,proto3"`
+	Required        bool   `protobuf:"varint,4,opt,name=required,proto3"`
+	Optional        bool   `protobuf:"varint,5,opt,name=optional,proto3"`
+	Computed        bool   `protobuf:"varint,6,opt,name=computed,proto3"`
+	Sensitive       bool   `protobuf:"varint,7,opt,name=sensitive,proto3"`
+	DescriptionKind int32  `protobuf:"varint,8,opt,name=description_kind,proto3"`
+	Deprecated      bool   `protobuf:"varint,9,opt,name=deprecated,proto3"`
+}
+
+func (m *attribute) Reset()         { *m = attribute{} }
+func (m *attribute) String() string { return proto.CompactTextString(m) }
+func (*attribute) ProtoMessage()    {}
+
+type nestedBlock struct {
+	TypeName string `protobuf:"bytes,1,opt,name=type_name,proto3"`
+	Block    *block `protobuf:"bytes,2,opt,name=block,proto3"`
+	Nesting  int32  `protobuf:"varint,3,opt,name=nesting,proto3"`
+	MinItems int64  `protobuf:"varint,4,opt,name=min_items,proto3"`
+	MaxItems int64  `protobuf:"varint,5,opt,name=max_items,proto3"`
+}
+
+func (m *nestedBlock) Reset()         { *m = nestedBlock{} }
+func (m *nestedBlock) String() string { return proto.CompactTextString(m) }
+func (*nestedBlock) ProtoMessage()    {}
+
+type diagnostic struct {
+	Severity int32  `protobuf:"varint,1,opt,name=severity,proto3"`
+	Summary  string `protobuf:"bytes,2,opt,name=summary,proto3"`
+	Detail   string `protobuf:"bytes,3,opt,name=detail,proto3"`
+}
+
+func (m *diagnostic) Reset()         { *m = diagnostic{} }
+func (m *diagnostic) String() string { return proto.CompactTextString(m) }
+func (*diagnostic) ProtoMessage()    {}
@@ -0,0 +1,151 @@
+package plugin
+
+import (
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/google/go-cmp/cmp"
+)
+
+// The messages below are encoded by hand with field numbers
+// from tfplugin5.proto, such that any field declared
+// with a wrong number or wire type fails to decode.
+
+func varintField(num int, v uint64) []byte {
+	return append(proto.EncodeVarint(uint64(num)<<3), proto.EncodeVarint(v)...)
+}
+
+func bytesField(num int, payload ...[]byte) []byte {
+	var b []byte
+	for _, p := range payload {
+		b = append(b, p...)
+	}
+	field := proto.EncodeVarint(uint64(num)<<3 | 2)
+	field = append(field, proto.EncodeVarint(uint64(len(b)))...)
+	return append(field, b...)
+}
+
+func stringField(num int, s string) []byte {
+	return bytesField(num, []byte(s))
+}
+
+func TestGetProviderSchemaResponse_wireFormat(t *testing.T) {
+	attr := bytesField(2, // Schema.Block.attributes
+		stringField(1, "ami"),        // name
+		stringField(2, `"string"`),   // type
+		stringField(3, "AMI to use"), // description
+		varintField(4, 1),            // required
+		varintField(6, 1),            // computed
+		varintField(7, 1),            // sensitive
+		varintField(8, 1),            // description_kind
+		varintField(9, 1),            // deprecated
+	)
+	nested := bytesField(3, // Schema.Block.block_types
+		stringField(1, "disk"),                // type_name
+		bytesField(2, stringField(4, "Disk")), // block
+		varintField(3, nestingList),           // nesting
+		varintField(4, 1),                     // min_items
+		varintField(5, 2),                     // max_items
+	)
+	instanceSchema := bytesField(2, // resource_schemas
+		stringField(1, "test_instance"), // key
+		bytesField(2, // value (Schema)
+			varintField(1, 3), // version
+			bytesField(2, // block
+				varintField(1, 3), // version
+				attr,
+				nested,
+				stringField(4, "An instance"), // description
+				varintField(5, 1),             // description_kind
+				varintField(6, 1),             // deprecated
+			),
+		),
+	)
+
+	var msg []byte
+	msg = append(msg, bytesField(1, bytesField(2, stringField(4, "Provider")))...) // provider
+	msg = append(msg, instanceSchema...)
+	msg = append(msg, bytesField(3, // data_source_schemas
+		stringField(1, "test_image"),
+		bytesField(2, bytesField(2, stringField(4, "An image"))),
+	)...)
+	msg = append(msg, bytesField(4, // diagnostics
+		varintField(1, diagnosticSeverityError), // severity
+		stringField(2, "Summary"),               // summary
+		stringField(3, "Detail"),                // detail
+	)...)
+
+	expected := &getProviderSchemaResponse{
+		Provider: &schema{
+			Block: &block{Description: "Provider"},
+		},
+		ResourceSchemas: map[string]*schema{
+			"test_instance": {
+				Version: 3,
+				Block: &block{
+					Version: 3,
+					Attributes: []*attribute{
+						{
+							Name:            "ami",
+							Type:            []byte(`"string"`),
+							Description:     "AMI to use",
+							Required:        true,
+							Computed:        true,
+							Sensitive:       true,
+							DescriptionKind: descriptionKindMarkdown,
+							Deprecated:      true,
+						},
+					},
+					BlockTypes: []*nestedBlock{
+						{
+							TypeName: "disk",
+							Block:    &block{Description: "Disk"},
+							Nesting:  nestingList,
+							MinItems: 1,
+							MaxItems: 2,
+						},
+					},
+					Description:     "An instance",
+					DescriptionKind: descriptionKindMarkdown,
+					Deprecated:      true,
+				},
+			},
+		},
+		DataSourceSchemas: map[string]*schema{
+			"test_image": {
+				Block: &block{Description: "An image"},
+			},
+		},
+		Diagnostics: []*diagnostic{
+			{
+				Severity: diagnosticSeverityError,
+				Summary:  "Summary",
+				Detail:   "Detail",
+			},
+		},
+	}
+
+	decoded := &getProviderSchemaResponse{}
+	err := proto.Unmarshal(msg, decoded)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(expected, decoded); diff != "" {
+		t.Fatalf("decoded message mismatch: %s", diff)
+	}
+
+	// encoding the decoded message must produce
+	// a message which decodes the same way
+	b, err := proto.Marshal(decoded)
+	if err != nil {
+		t.Fatal(err)
+	}
+	roundTripped := &getProviderSchemaResponse{}
+	err = proto.Unmarshal(b, roundTripped)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(expected, roundTripped); diff != "" {
+		t.Fatalf("round-tripped message mismatch: %s", diff)
+	}
+}
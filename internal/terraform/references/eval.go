@@ -0,0 +1,257 @@
+package references
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/ext/typeexpr"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	"github.com/hashicorp/terraform-ls/internal/terraform/declarations"
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/convert"
+)
+
+const (
+	unknownValueText   = "(known after apply)"
+	sensitiveValueText = "(sensitive)"
+)
+
+// evalContext returns context for evaluation of constant expressions
+// within the module, i.e. expressions which only refer to input variables
+// and local values (and built-in functions).
+//
+// Variables are set to values from variable definitions files, or their
+// defaults, and are unknown otherwise. Local values are unknown unless
+// all values they refer to are known. All other objects (resources,
+// module outputs etc.) are unknown.
+func (m *Module) evalContext(decls *declarations.Module) *hcl.EvalContext {
+	ctx := &hcl.EvalContext{
+		Variables: map[string]cty.Value{
+			"var":   cty.ObjectVal(m.variableValues(decls)),
+			"local": cty.EmptyObjectVal,
+		},
+		Functions: functions(),
+	}
+	for _, name := range []string{"module", "data", "path", "terraform", "self", "count", "each"} {
+		ctx.Variables[name] = cty.DynamicVal
+	}
+	for addr := range decls.Resources {
+		ctx.Variables[strings.SplitN(addr, ".", 2)[0]] = cty.DynamicVal
+	}
+
+	locals := make(map[string]cty.Value, len(decls.Locals))
+	for name := range decls.Locals {
+		locals[name] = cty.DynamicVal
+	}
+
+	// local values may refer to each other, so we keep
+	// evaluating them until there is no more progress
+	for progress := true; progress; {
+		progress = false
+		ctx.Variables["local"] = cty.ObjectVal(locals)
+
+		for name, decl := range decls.Locals {
+			if locals[name].IsWhollyKnown() {
+				continue
+			}
+			val, diags := decl.Attribute.Expr.Value(ctx)
+			if diags.HasErrors() || !val.IsWhollyKnown() {
+				continue
+			}
+			locals[name] = val
+			progress = true
+		}
+	}
+	ctx.Variables["local"] = cty.ObjectVal(locals)
+
+	return ctx
+}
+
+// variableValues returns values of all declared variables, where values
+// from variable definitions files take precedence over defaults
+func (m *Module) variableValues(decls *declarations.Module) map[string]cty.Value {
+	defined := m.definedVariableValues()
+
+	values := make(map[string]cty.Value, len(decls.Variables))
+	for name, decl := range decls.Variables {
+		ty := cty.DynamicPseudoType
+		if attr, ok := decl.Block.Body.Attributes["type"]; ok {
+			if t, diags := typeexpr.TypeConstraint(attr.Expr); !diags.HasErrors() {
+				ty = t
+			}
+		}
+
+		val, ok := defined[name]
+		if !ok {
+			val = cty.UnknownVal(ty)
+			if attr, ok := decl.Block.Body.Attributes["default"]; ok {
+				if v, diags := attr.Expr.Value(nil); !diags.HasErrors() {
+					val = v
+				}
+			}
+		}
+
+		if converted, err := convert.Convert(val, ty); err == nil {
+			val = converted
+		}
+		values[name] = val
+	}
+
+	return values
+}
+
+// definedVariableValues returns values assigned to variables
+// in variable definitions files, which are processed in the same
+// order as Terraform does, i.e. terraform.tfvars first and then
+// *.auto.tfvars in lexical order, with later files taking precedence
+func (m *Module) definedVariableValues() map[string]cty.Value {
+	filenames := make([]string, 0, len(m.VariableFiles))
+	for filename := range m.VariableFiles {
+		filenames = append(filenames, filename)
+	}
+	sort.Slice(filenames, func(i, j int) bool {
+		if filenames[i] == "terraform.tfvars" {
+			return filenames[j] != "terraform.tfvars"
+		}
+		if filenames[j] == "terraform.tfvars" {
+			return false
+		}
+		return filenames[i] < filenames[j]
+	})
+
+	values := make(map[string]cty.Value, 0)
+	for _, filename := range filenames {
+		attrs, diags := m.VariableFiles[filename].Body.JustAttributes()
+		if diags.HasErrors() {
+			continue
+		}
+		for name, attr := range attrs {
+			val, diags := attr.Expr.Value(nil)
+			if diags.HasErrors() {
+				continue
+			}
+			values[name] = val
+		}
+	}
+
+	return values
+}
+
+// referenceValueText returns the value of the given reference formatted
+// for display, or false if the referenced object has no value
+// (such as a whole resource or a module call)
+func referenceValueText(ctx *hcl.EvalContext, decls *declarations.Module, ref declarations.Reference) (string, bool) {
+	steps := attrSteps(ref.Traversal)
+
+	switch steps[0] {
+	case "var":
+		if decl, ok := decls.Variables[steps[1]]; ok && isSensitive(decl) {
+			return sensitiveValueText, true
+		}
+		return evaluate(ctx, decls, &hclsyntax.ScopeTraversalExpr{Traversal: ref.Traversal})
+	case "local":
+		return evaluate(ctx, decls, &hclsyntax.ScopeTraversalExpr{Traversal: ref.Traversal})
+	case "module", "self":
+		return unknownValueText, len(steps) > 2 || steps[0] == "self"
+	case "data":
+		return unknownValueText, len(steps) > 3
+	case "count", "each", "path", "terraform":
+		return "", false
+	}
+
+	return unknownValueText, len(steps) > 2
+}
+
+func isSensitive(decl *declarations.Declaration) bool {
+	attr, ok := decl.Block.Body.Attributes["sensitive"]
+	if !ok {
+		return false
+	}
+	val, diags := attr.Expr.Value(nil)
+	return !diags.HasErrors() && val.Type() == cty.Bool && val.IsKnown() && val.True()
+}
+
+// evaluate returns the value of the given traversal or expression
+// formatted for display, or a placeholder if the value is not known.
+//
+// The returned bool is false if the expression cannot be evaluated,
+// e.g. because it calls a function which is not supported.
+func evaluate(ctx *hcl.EvalContext, decls *declarations.Module, expr hcl.Expression) (string, bool) {
+	if !isEvaluable(ctx, decls, expr, make(map[string]bool, 0)) {
+		return "", false
+	}
+	val, _ := expr.Value(ctx)
+	if !val.IsWhollyKnown() {
+		return unknownValueText, true
+	}
+	return formatValue(val), true
+}
+
+// isEvaluable reports whether the given expression and all local values
+// it refers to can be evaluated without errors, since local values which
+// cannot be evaluated are unknown within the context
+func isEvaluable(ctx *hcl.EvalContext, decls *declarations.Module, expr hcl.Expression, visited map[string]bool) bool {
+	_, diags := expr.Value(ctx)
+	if diags.HasErrors() {
+		return false
+	}
+
+	for _, traversal := range expr.Variables() {
+		if traversal.RootName() != "local" || len(traversal) < 2 {
+			continue
+		}
+		step, ok := traversal[1].(hcl.TraverseAttr)
+		if !ok || visited[step.Name] {
+			continue
+		}
+		visited[step.Name] = true
+
+		decl, ok := decls.Locals[step.Name]
+		if !ok {
+			continue
+		}
+		if !isEvaluable(ctx, decls, decl.Attribute.Expr, visited) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func formatValue(val cty.Value) string {
+	return codeText(strings.TrimSpace(string(hclwrite.Format(hclwrite.TokensForValue(val).Bytes()))))
+}
+
+// codeText formats the given source code as inline Markdown code,
+// or as a code block starting on a new line if it spans lines
+func codeText(src string) string {
+	if strings.Contains(src, "\n") {
+		return "\n```hcl\n" + src + "\n```"
+	}
+	return "`" + src + "`"
+}
+
+// functionCallAtPos returns the function call expression whose name is at the given position in the file of the given filename
+func functionCallAtPos(files map[string]*hcl.File, filename string, pos hcl.Pos) (*hclsyntax.FunctionCallExpr, bool) {
+	f, ok := files[filename]
+	if !ok {
+		return nil, false
+	}
+	body, ok := f.Body.(*hclsyntax.Body)
+	if !ok {
+		return nil, false
+	}
+
+	var call *hclsyntax.FunctionCallExpr
+	hclsyntax.VisitAll(body, func(node hclsyntax.Node) hcl.Diagnostics {
+		expr, ok := node.(*hclsyntax.FunctionCallExpr)
+		if ok && call == nil && expr.NameRange.ContainsOffset(pos.Byte) {
+			call = expr
+		}
+		return nil
+	})
+
+	return call, call != nil
+}
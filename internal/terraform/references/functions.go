@@ -0,0 +1,468 @@
+package references
+
+import (
+	"bytes"
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"math/big"
+	"net"
+	"unicode/utf8"
+
+	"github.com/hashicorp/hcl/v2/ext/tryfunc"
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/convert"
+	"github.com/zclconf/go-cty/cty/function"
+	"github.com/zclconf/go-cty/cty/function/stdlib"
+	"gopkg.in/yaml.v3"
+)
+
+// functions represents a subset of Terraform's built-in functions
+// which can be used to evaluate constant expressions, i.e. functions
+// which don't depend on the filesystem or any other external state
+func functions() map[string]function.Function {
+	return map[string]function.Function{
+		"abs":             stdlib.AbsoluteFunc,
+		"base64decode":    base64DecodeFunc,
+		"base64encode":    base64EncodeFunc,
+		"can":             tryfunc.CanFunc,
+		"ceil":            stdlib.CeilFunc,
+		"chomp":           stdlib.ChompFunc,
+		"chunklist":       stdlib.ChunklistFunc,
+		"cidrhost":        cidrHostFunc,
+		"cidrnetmask":     cidrNetmaskFunc,
+		"cidrsubnet":      cidrSubnetFunc,
+		"cidrsubnets":     cidrSubnetsFunc,
+		"coalesce":        stdlib.CoalesceFunc,
+		"coalescelist":    stdlib.CoalesceListFunc,
+		"compact":         stdlib.CompactFunc,
+		"concat":          stdlib.ConcatFunc,
+		"contains":        stdlib.ContainsFunc,
+		"csvdecode":       stdlib.CSVDecodeFunc,
+		"distinct":        stdlib.DistinctFunc,
+		"element":         stdlib.ElementFunc,
+		"flatten":         stdlib.FlattenFunc,
+		"floor":           stdlib.FloorFunc,
+		"format":          stdlib.FormatFunc,
+		"formatdate":      stdlib.FormatDateFunc,
+		"formatlist":      stdlib.FormatListFunc,
+		"indent":          stdlib.IndentFunc,
+		"index":           indexFunc,
+		"join":            stdlib.JoinFunc,
+		"jsondecode":      stdlib.JSONDecodeFunc,
+		"jsonencode":      stdlib.JSONEncodeFunc,
+		"keys":            stdlib.KeysFunc,
+		"length":          stdlib.LengthFunc,
+		"log":             stdlib.LogFunc,
+		"lookup":          stdlib.LookupFunc,
+		"lower":           stdlib.LowerFunc,
+		"max":             stdlib.MaxFunc,
+		"md5":             md5Func,
+		"merge":           stdlib.MergeFunc,
+		"min":             stdlib.MinFunc,
+		"parseint":        stdlib.ParseIntFunc,
+		"pow":             stdlib.PowFunc,
+		"range":           stdlib.RangeFunc,
+		"regex":           stdlib.RegexFunc,
+		"regexall":        stdlib.RegexAllFunc,
+		"replace":         replaceFunc,
+		"reverse":         stdlib.ReverseListFunc,
+		"setintersection": stdlib.SetIntersectionFunc,
+		"setproduct":      stdlib.SetProductFunc,
+		"setsubtract":     stdlib.SetSubtractFunc,
+		"setunion":        stdlib.SetUnionFunc,
+		"sha256":          sha256Func,
+		"signum":          stdlib.SignumFunc,
+		"slice":           stdlib.SliceFunc,
+		"sort":            stdlib.SortFunc,
+		"split":           stdlib.SplitFunc,
+		"strrev":          stdlib.ReverseFunc,
+		"substr":          stdlib.SubstrFunc,
+		"sum":             sumFunc,
+		"title":           stdlib.TitleFunc,
+		"tobool":          stdlib.MakeToFunc(cty.Bool),
+		"tolist":          stdlib.MakeToFunc(cty.List(cty.DynamicPseudoType)),
+		"tomap":           stdlib.MakeToFunc(cty.Map(cty.DynamicPseudoType)),
+		"tonumber":        stdlib.MakeToFunc(cty.Number),
+		"toset":           stdlib.MakeToFunc(cty.Set(cty.DynamicPseudoType)),
+		"tostring":        stdlib.MakeToFunc(cty.String),
+		"trim":            stdlib.TrimFunc,
+		"trimprefix":      stdlib.TrimPrefixFunc,
+		"trimspace":       stdlib.TrimSpaceFunc,
+		"trimsuffix":      stdlib.TrimSuffixFunc,
+		"try":             tryfunc.TryFunc,
+		"upper":           stdlib.UpperFunc,
+		"values":          stdlib.ValuesFunc,
+		"yamlencode":      yamlEncodeFunc,
+		"zipmap":          stdlib.ZipmapFunc,
+	}
+}
+
+var cidrHostFunc = function.New(&function.Spec{
+	Params: []function.Parameter{
+		{Name: "prefix", Type: cty.String},
+		{Name: "hostnum", Type: cty.Number},
+	},
+	Type: function.StaticReturnType(cty.String),
+	Impl: func(args []cty.Value, retType cty.Type) (cty.Value, error) {
+		network, err := parseCIDR(args[0].AsString())
+		if err != nil {
+			return cty.UnknownVal(cty.String), err
+		}
+		hostNum, acc := args[1].AsBigFloat().Int(nil)
+		if acc != big.Exact {
+			return cty.UnknownVal(cty.String), function.NewArgErrorf(1, "hostnum must be a whole number")
+		}
+
+		ones, bits := network.Mask.Size()
+		size := new(big.Int).Lsh(big.NewInt(1), uint(bits-ones))
+		if hostNum.Sign() < 0 {
+			hostNum.Add(hostNum, size)
+		}
+		if hostNum.Sign() < 0 || hostNum.Cmp(size) >= 0 {
+			return cty.UnknownVal(cty.String), function.NewArgErrorf(1,
+				"prefix %s has only %s host addresses", args[0].AsString(), size)
+		}
+
+		return cty.StringVal(addToIP(network.IP, hostNum).String()), nil
+	},
+})
+
+var cidrNetmaskFunc = function.New(&function.Spec{
+	Params: []function.Parameter{
+		{Name: "prefix", Type: cty.String},
+	},
+	Type: function.StaticReturnType(cty.String),
+	Impl: func(args []cty.Value, retType cty.Type) (cty.Value, error) {
+		network, err := parseCIDR(args[0].AsString())
+		if err != nil {
+			return cty.UnknownVal(cty.String), err
+		}
+		if len(network.IP) != net.IPv4len {
+			return cty.UnknownVal(cty.String), function.NewArgErrorf(0,
+				"only IPv4 prefixes have a netmask")
+		}
+		return cty.StringVal(net.IP(network.Mask).String()), nil
+	},
+})
+
+var cidrSubnetFunc = function.New(&function.Spec{
+	Params: []function.Parameter{
+		{Name: "prefix", Type: cty.String},
+		{Name: "newbits", Type: cty.Number},
+		{Name: "netnum", Type: cty.Number},
+	},
+	Type: function.StaticReturnType(cty.String),
+	Impl: func(args []cty.Value, retType cty.Type) (cty.Value, error) {
+		network, err := parseCIDR(args[0].AsString())
+		if err != nil {
+			return cty.UnknownVal(cty.String), err
+		}
+		newBits, acc := args[1].AsBigFloat().Int64()
+		if acc != big.Exact || newBits < 0 {
+			return cty.UnknownVal(cty.String), function.NewArgErrorf(1, "newbits must be a non-negative whole number")
+		}
+		netNum, acc := args[2].AsBigFloat().Int(nil)
+		if acc != big.Exact || netNum.Sign() < 0 {
+			return cty.UnknownVal(cty.String), function.NewArgErrorf(2, "netnum must be a non-negative whole number")
+		}
+
+		ones, bits := network.Mask.Size()
+		newOnes := ones + int(newBits)
+		if newOnes > bits {
+			return cty.UnknownVal(cty.String), function.NewArgErrorf(1,
+				"insufficient address space to extend prefix of %d by %d", ones, newBits)
+		}
+		if netNum.Cmp(new(big.Int).Lsh(big.NewInt(1), uint(newBits))) >= 0 {
+			return cty.UnknownVal(cty.String), function.NewArgErrorf(2,
+				"prefix extension of %d does not accommodate a subnet numbered %s", newBits, netNum)
+		}
+
+		offset := new(big.Int).Lsh(netNum, uint(bits-newOnes))
+		subnet := &net.IPNet{
+			IP:   addToIP(network.IP, offset),
+			Mask: net.CIDRMask(newOnes, bits),
+		}
+		return cty.StringVal(subnet.String()), nil
+	},
+})
+
+var cidrSubnetsFunc = function.New(&function.Spec{
+	Params: []function.Parameter{
+		{Name: "prefix", Type: cty.String},
+	},
+	VarParam: &function.Parameter{Name: "newbits", Type: cty.Number},
+	Type:     function.StaticReturnType(cty.List(cty.String)),
+	Impl: func(args []cty.Value, retType cty.Type) (cty.Value, error) {
+		network, err := parseCIDR(args[0].AsString())
+		if err != nil {
+			return cty.UnknownVal(retType), err
+		}
+		if len(args) == 1 {
+			return cty.ListValEmpty(cty.String), nil
+		}
+
+		ones, bits := network.Mask.Size()
+		available := new(big.Int).Lsh(big.NewInt(1), uint(bits-ones))
+
+		// subnets are allocated consecutively, each aligned to its own size
+		next := new(big.Int)
+		subnets := make([]cty.Value, 0, len(args)-1)
+		for i, arg := range args[1:] {
+			newBits, acc := arg.AsBigFloat().Int64()
+			if acc != big.Exact || newBits < 1 {
+				return cty.UnknownVal(retType), function.NewArgErrorf(i+1,
+					"must extend prefix by at least one bit")
+			}
+			newOnes := ones + int(newBits)
+			if newOnes > bits {
+				return cty.UnknownVal(retType), function.NewArgErrorf(i+1,
+					"insufficient address space to extend prefix of %d by %d", ones, newBits)
+			}
+
+			size := new(big.Int).Lsh(big.NewInt(1), uint(bits-newOnes))
+			remainder := new(big.Int).Mod(next, size)
+			if remainder.Sign() > 0 {
+				next.Add(next, new(big.Int).Sub(size, remainder))
+			}
+			if new(big.Int).Add(next, size).Cmp(available) > 0 {
+				return cty.UnknownVal(retType), function.NewArgErrorf(i+1,
+					"not enough remaining address space for a subnet with a prefix of %d bits", newOnes)
+			}
+
+			subnet := &net.IPNet{
+				IP:   addToIP(network.IP, next),
+				Mask: net.CIDRMask(newOnes, bits),
+			}
+			subnets = append(subnets, cty.StringVal(subnet.String()))
+			next.Add(next, size)
+		}
+
+		return cty.ListVal(subnets), nil
+	},
+})
+
+func parseCIDR(prefix string) (*net.IPNet, error) {
+	_, network, err := net.ParseCIDR(prefix)
+	if err != nil {
+		return nil, function.NewArgErrorf(0, "invalid CIDR expression: %s", err)
+	}
+	return network, nil
+}
+
+// addToIP returns the IP address offset by the given number
+func addToIP(ip net.IP, offset *big.Int) net.IP {
+	sum := new(big.Int).Add(new(big.Int).SetBytes(ip), offset)
+	raw := sum.Bytes()
+
+	result := make(net.IP, len(ip))
+	copy(result[len(result)-len(raw):], raw)
+	return result
+}
+
+var base64EncodeFunc = function.New(&function.Spec{
+	Params: []function.Parameter{
+		{Name: "str", Type: cty.String},
+	},
+	Type: function.StaticReturnType(cty.String),
+	Impl: func(args []cty.Value, retType cty.Type) (cty.Value, error) {
+		return cty.StringVal(base64.StdEncoding.EncodeToString([]byte(args[0].AsString()))), nil
+	},
+})
+
+var base64DecodeFunc = function.New(&function.Spec{
+	Params: []function.Parameter{
+		{Name: "str", Type: cty.String},
+	},
+	Type: function.StaticReturnType(cty.String),
+	Impl: func(args []cty.Value, retType cty.Type) (cty.Value, error) {
+		decoded, err := base64.StdEncoding.DecodeString(args[0].AsString())
+		if err != nil {
+			return cty.UnknownVal(cty.String), function.NewArgErrorf(0, "failed to decode base64 data: %s", err)
+		}
+		if !utf8.Valid(decoded) {
+			return cty.UnknownVal(cty.String), function.NewArgErrorf(0,
+				"the result of decoding the provided string is not valid UTF-8")
+		}
+		return cty.StringVal(string(decoded)), nil
+	},
+})
+
+var md5Func = function.New(&function.Spec{
+	Params: []function.Parameter{
+		{Name: "str", Type: cty.String},
+	},
+	Type: function.StaticReturnType(cty.String),
+	Impl: func(args []cty.Value, retType cty.Type) (cty.Value, error) {
+		sum := md5.Sum([]byte(args[0].AsString()))
+		return cty.StringVal(hex.EncodeToString(sum[:])), nil
+	},
+})
+
+var sha256Func = function.New(&function.Spec{
+	Params: []function.Parameter{
+		{Name: "str", Type: cty.String},
+	},
+	Type: function.StaticReturnType(cty.String),
+	Impl: func(args []cty.Value, retType cty.Type) (cty.Value, error) {
+		sum := sha256.Sum256([]byte(args[0].AsString()))
+		return cty.StringVal(hex.EncodeToString(sum[:])), nil
+	},
+})
+
+// replaceFunc replaces all occurrences of the given substring,
+// which is treated as a regular expression if wrapped in slashes
+var replaceFunc = function.New(&function.Spec{
+	Params: []function.Parameter{
+		{Name: "str", Type: cty.String},
+		{Name: "substr", Type: cty.String},
+		{Name: "replace", Type: cty.String},
+	},
+	Type: function.StaticReturnType(cty.String),
+	Impl: func(args []cty.Value, retType cty.Type) (cty.Value, error) {
+		substr := args[1].AsString()
+		if len(substr) > 1 && substr[0] == '/' && substr[len(substr)-1] == '/' {
+			pattern := cty.StringVal(substr[1 : len(substr)-1])
+			return stdlib.RegexReplaceFunc.Call([]cty.Value{args[0], pattern, args[2]})
+		}
+		return stdlib.ReplaceFunc.Call(args)
+	},
+})
+
+var indexFunc = function.New(&function.Spec{
+	Params: []function.Parameter{
+		{Name: "list", Type: cty.DynamicPseudoType},
+		{Name: "value", Type: cty.DynamicPseudoType},
+	},
+	Type: function.StaticReturnType(cty.Number),
+	Impl: func(args []cty.Value, retType cty.Type) (cty.Value, error) {
+		ty := args[0].Type()
+		if !(ty.IsListType() || ty.IsTupleType()) {
+			return cty.UnknownVal(cty.Number), function.NewArgErrorf(0, "argument must be a list or tuple")
+		}
+		if !args[0].IsWhollyKnown() {
+			return cty.UnknownVal(cty.Number), nil
+		}
+
+		for it := args[0].ElementIterator(); it.Next(); {
+			i, v := it.Element()
+			eq, err := stdlib.Equal(v, args[1])
+			if err != nil {
+				return cty.UnknownVal(cty.Number), err
+			}
+			if !eq.IsKnown() {
+				return cty.UnknownVal(cty.Number), nil
+			}
+			if eq.True() {
+				return i, nil
+			}
+		}
+		return cty.UnknownVal(cty.Number), function.NewArgErrorf(1, "item not found")
+	},
+})
+
+var sumFunc = function.New(&function.Spec{
+	Params: []function.Parameter{
+		{Name: "list", Type: cty.DynamicPseudoType},
+	},
+	Type: function.StaticReturnType(cty.Number),
+	Impl: func(args []cty.Value, retType cty.Type) (cty.Value, error) {
+		ty := args[0].Type()
+		if !(ty.IsListType() || ty.IsSetType() || ty.IsTupleType()) {
+			return cty.UnknownVal(cty.Number), function.NewArgErrorf(0, "argument must be a list, set or tuple")
+		}
+		if !args[0].IsWhollyKnown() {
+			return cty.UnknownVal(cty.Number), nil
+		}
+		if args[0].LengthInt() == 0 {
+			return cty.UnknownVal(cty.Number), function.NewArgErrorf(0, "cannot sum an empty list")
+		}
+
+		sum := cty.Zero
+		for it := args[0].ElementIterator(); it.Next(); {
+			_, v := it.Element()
+			if v.IsNull() {
+				return cty.UnknownVal(cty.Number), function.NewArgErrorf(0, "argument must contain only numbers")
+			}
+			num, err := convert.Convert(v, cty.Number)
+			if err != nil {
+				return cty.UnknownVal(cty.Number), function.NewArgErrorf(0, "argument must contain only numbers")
+			}
+			sum = sum.Add(num)
+		}
+		return sum, nil
+	},
+})
+
+// yamlEncodeFunc encodes values the same way as Terraform does,
+// i.e. with double-quoted strings and keys in lexical order
+var yamlEncodeFunc = function.New(&function.Spec{
+	Params: []function.Parameter{
+		{Name: "value", Type: cty.DynamicPseudoType, AllowNull: true},
+	},
+	Type: function.StaticReturnType(cty.String),
+	Impl: func(args []cty.Value, retType cty.Type) (cty.Value, error) {
+		if !args[0].IsWhollyKnown() {
+			return cty.UnknownVal(cty.String), nil
+		}
+
+		var buf bytes.Buffer
+		enc := yaml.NewEncoder(&buf)
+		enc.SetIndent(2)
+		err := enc.Encode(yamlNode(args[0]))
+		if err != nil {
+			return cty.UnknownVal(cty.String), err
+		}
+		err = enc.Close()
+		if err != nil {
+			return cty.UnknownVal(cty.String), err
+		}
+		return cty.StringVal(buf.String()), nil
+	},
+})
+
+func yamlNode(val cty.Value) *yaml.Node {
+	if val.IsNull() {
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!null", Value: "null"}
+	}
+
+	ty := val.Type()
+	switch {
+	case ty == cty.String:
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: val.AsString(), Style: yaml.DoubleQuotedStyle}
+	case ty == cty.Number:
+		bf := val.AsBigFloat()
+		tag := "!!float"
+		if bf.IsInt() {
+			tag = "!!int"
+		}
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: tag, Value: bf.Text('f', -1)}
+	case ty == cty.Bool:
+		value := "false"
+		if val.True() {
+			value = "true"
+		}
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!bool", Value: value}
+	case ty.IsListType() || ty.IsSetType() || ty.IsTupleType():
+		node := &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq"}
+		for it := val.ElementIterator(); it.Next(); {
+			_, v := it.Element()
+			node.Content = append(node.Content, yamlNode(v))
+		}
+		if len(node.Content) == 0 {
+			node.Style = yaml.FlowStyle
+		}
+		return node
+	default:
+		node := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+		for it := val.ElementIterator(); it.Next(); {
+			k, v := it.Element()
+			node.Content = append(node.Content, yamlNode(k), yamlNode(v))
+		}
+		if len(node.Content) == 0 {
+			node.Style = yaml.FlowStyle
+		}
+		return node
+	}
+}
@@ -0,0 +1,240 @@
+package references
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/hcl-lang/lang"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/terraform-ls/internal/terraform/declarations"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// HoverAtPos returns hover data describing the object referenced
+// at the given position of the file of the given filename.
+//
+// Values of references and function calls are evaluated where
+// they only depend on variables and local values, which are known,
+// and omitted where they cannot be evaluated.
+//
+// The returned bool reports whether there is a reference
+// to a known object, or a function call at the given position.
+func (m *Module) HoverAtPos(filename string, pos hcl.Pos) (*lang.HoverData, bool) {
+	decls := declarations.Decode(m.Files)
+
+	ref, ok := referenceAtPos(m.Files, filename, pos)
+	if !ok {
+		call, ok := functionCallAtPos(m.Files, filename, pos)
+		if !ok {
+			return nil, false
+		}
+		content := fmt.Sprintf("**%s(...)** _function_", call.Name)
+		if value, ok := evaluate(m.evalContext(decls), decls, call); ok {
+			content += "\n\nValue: " + value
+		}
+		return &lang.HoverData{
+			Content: lang.Markdown(content),
+			Range:   call.Range(),
+		}, true
+	}
+
+	content, ok := m.hoverContent(decls, ref)
+	if !ok {
+		return nil, false
+	}
+	if value, ok := referenceValueText(m.evalContext(decls), decls, ref); ok {
+		content += "\n\nValue: " + value
+	}
+
+	return &lang.HoverData{
+		Content: lang.Markdown(content),
+		Range:   ref.Range(),
+	}, true
+}
+
+func (m *Module) hoverContent(decls *declarations.Module, ref declarations.Reference) (string, bool) {
+	steps := attrSteps(ref.Traversal)
+	if len(steps) < 2 {
+		return "", false
+	}
+	rootName := steps[0]
+
+	switch rootName {
+	case "var":
+		decl, ok := decls.Variables[steps[1]]
+		if !ok {
+			return "", false
+		}
+		return hoverText("var."+steps[1], variableType(decl), description(decl)) +
+			m.variableDetails(decl), true
+	case "local":
+		decl, ok := decls.Locals[steps[1]]
+		if !ok {
+			return "", false
+		}
+		return hoverText("local."+steps[1], localType(decl), lang.MarkupContent{}) +
+			"\n\nExpression: " + m.exprSource(decl.Attribute.Expr), true
+	case "module":
+		decl, ok := decls.ModuleCalls[steps[1]]
+		if !ok {
+			return "", false
+		}
+		if len(steps) < 3 {
+			text := hoverText("module."+steps[1], "module", lang.MarkupContent{})
+			if source, ok := decl.StringAttribute("source"); ok {
+				text += fmt.Sprintf("\n\nsource: `%s`", source)
+			}
+			return text, true
+		}
+		childFiles, ok := m.Children[steps[1]]
+		if !ok {
+			return "", false
+		}
+		output, ok := declarations.Decode(childFiles).Outputs[steps[2]]
+		if !ok {
+			return "", false
+		}
+		return hoverText(strings.Join(steps[:3], "."), "output", description(output)), true
+	case "data":
+		if len(steps) < 3 {
+			return "", false
+		}
+		if _, ok := decls.DataSources[steps[1]+"."+steps[2]]; !ok {
+			return "", false
+		}
+		if len(steps) > 3 {
+			return m.attributeHoverText("data", steps[1], steps[:4])
+		}
+		return hoverText(strings.Join(steps[:3], "."), "data source", lang.MarkupContent{}), true
+	case "count":
+		if steps[1] == "index" {
+			return hoverText("count.index", "number", lang.PlainText(
+				"The distinct index number (starting with 0) corresponding to this instance.")), true
+		}
+	case "each":
+		switch steps[1] {
+		case "key":
+			return hoverText("each.key", "string", lang.PlainText(
+				"The map key (or set member) corresponding to this instance.")), true
+		case "value":
+			return hoverText("each.value", "any", lang.PlainText(
+				"The map value corresponding to this instance.")), true
+		}
+	case "path":
+		return hoverText("path."+steps[1], "string", lang.MarkupContent{}), true
+	case "terraform":
+		return hoverText("terraform."+steps[1], "string", lang.MarkupContent{}), true
+	case "self":
+		if ref.Block == nil || len(ref.Block.Labels) == 0 {
+			return "", false
+		}
+		return m.attributeHoverText("resource", ref.Block.Labels[0], []string{"self", "", steps[1]})
+	default:
+		if _, ok := decls.Resources[rootName+"."+steps[1]]; !ok {
+			return "", false
+		}
+		if len(steps) > 2 {
+			return m.attributeHoverText("resource", rootName, steps[:3])
+		}
+		return hoverText(rootName+"."+steps[1], "resource", lang.MarkupContent{}), true
+	}
+
+	return "", false
+}
+
+// attributeHoverText describes an attribute of a resource or data source,
+// where the last of the given steps is the attribute name
+func (m *Module) attributeHoverText(blockType, typeName string, steps []string) (string, bool) {
+	attrName := steps[len(steps)-1]
+	for _, it := range m.attributeItems(blockType, typeName) {
+		if it.name != attrName {
+			continue
+		}
+		label := strings.Join(steps, ".")
+		if steps[0] == "self" {
+			label = "self." + attrName
+		}
+		detail := it.detail
+		if it.isComputed {
+			detail += ", computed"
+		}
+		return hoverText(label, detail, it.description), true
+	}
+	return "", false
+}
+
+// variableDetails describes the default value
+// and validation rules of the given variable
+func (m *Module) variableDetails(decl *declarations.Declaration) string {
+	var text string
+	body := decl.Block.Body
+
+	if attr, ok := body.Attributes["default"]; ok {
+		text += "\n\nDefault: " + m.exprSource(attr.Expr)
+	}
+
+	for _, block := range body.Blocks {
+		if block.Type != "validation" {
+			continue
+		}
+		cond, ok := block.Body.Attributes["condition"]
+		if !ok {
+			continue
+		}
+		text += "\n\nValidation: " + m.exprSource(cond.Expr)
+		if attr, ok := block.Body.Attributes["error_message"]; ok {
+			val, diags := attr.Expr.Value(nil)
+			if !diags.HasErrors() && val.Type() == cty.String && val.IsKnown() && !val.IsNull() {
+				text += "\n\n" + val.AsString()
+			}
+		}
+	}
+
+	return text
+}
+
+// exprSource returns source code of the given expression
+// formatted for display
+func (m *Module) exprSource(expr hcl.Expression) string {
+	rng := expr.Range()
+	f, ok := m.Files[rng.Filename]
+	if !ok {
+		return ""
+	}
+	return codeText(string(rng.SliceBytes(f.Bytes)))
+}
+
+func hoverText(label, detail string, description lang.MarkupContent) string {
+	text := fmt.Sprintf("**%s** _%s_", label, detail)
+	if description.Value != "" {
+		text += "\n\n" + description.Value
+	}
+	return text
+}
+
+// referenceAtPos returns reference (traversal) found
+// at the given position in the file of the given filename
+func referenceAtPos(files map[string]*hcl.File, filename string, pos hcl.Pos) (declarations.Reference, bool) {
+	for _, ref := range declarations.DecodeReferences(files) {
+		rng := ref.Range()
+		if rng.Filename == filename && rng.ContainsOffset(pos.Byte) {
+			return ref, true
+		}
+	}
+	return declarations.Reference{}, false
+}
+
+// attrSteps returns names of the root and all attribute steps
+// of the traversal, ignoring any index steps
+func attrSteps(traversal hcl.Traversal) []string {
+	steps := make([]string, 0, len(traversal))
+	for _, step := range traversal {
+		switch s := step.(type) {
+		case hcl.TraverseRoot:
+			steps = append(steps, s.Name)
+		case hcl.TraverseAttr:
+			steps = append(steps, s.Name)
+		}
+	}
+	return steps
+}
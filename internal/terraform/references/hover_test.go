@@ -0,0 +1,364 @@
+package references
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/hashicorp/hcl-lang/lang"
+	"github.com/hashicorp/hcl/v2"
+)
+
+func TestHoverAtPos(t *testing.T) {
+	cfg := `variable "list" {
+  type        = list(string)
+  description = "List of names"
+}
+locals {
+  name = "foo"
+}
+module "child" {
+  source = "./child"
+}
+resource "test_instance" "one" {
+  count = length(var.list)
+  ami   = local.name
+  tags  = { index = count.index }
+}
+output "first" {
+  value = module.child.first
+}
+output "ami" {
+  value = test_instance.one[0].ami
+}
+output "id" {
+  value = test_instance.one[0].id
+}
+`
+	childCfg := `output "first" {
+  value       = "foo"
+  description = "First output"
+}
+`
+	testCases := []struct {
+		reference       string
+		expectedContent string
+	}{
+		{"var.list", "**var.list** _list(string)_\n\nList of names\n\nValue: (known after apply)"},
+		{"local.name", "**local.name** _string_\n\nExpression: `\"foo\"`\n\nValue: `\"foo\"`"},
+		{"count.index", "**count.index** _number_\n\n" +
+			"The distinct index number (starting with 0) corresponding to this instance."},
+		{"module.child.first", "**module.child.first** _output_\n\nFirst output\n\nValue: (known after apply)"},
+		{"test_instance.one[0].ami", "**test_instance.one.ami** _string_\n\nValue: (known after apply)"},
+		{"test_instance.one[0].id", "**test_instance.one.id** _string, computed_\n\nValue: (known after apply)"},
+	}
+
+	mod := &Module{
+		Files: map[string]*hcl.File{
+			"main.tf": parseFile(t, "main.tf", cfg),
+		},
+		Children: map[string]map[string]*hcl.File{
+			"child": {
+				"main.tf": parseFile(t, "main.tf", childCfg),
+			},
+		},
+		Schema: testSchema,
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.reference, func(t *testing.T) {
+			idx := strings.Index(cfg, tc.reference)
+			start := posAtByte(cfg, idx)
+			end := posAtByte(cfg, idx+len(tc.reference))
+
+			data, ok := mod.HoverAtPos("main.tf", start)
+			if !ok {
+				t.Fatal("expected hover data")
+			}
+
+			expectedData := &lang.HoverData{
+				Content: lang.Markdown(tc.expectedContent),
+				Range: hcl.Range{
+					Filename: "main.tf",
+					Start:    start,
+					End:      end,
+				},
+			}
+			if diff := cmp.Diff(expectedData, data); diff != "" {
+				t.Fatalf("hover data mismatch: %s", diff)
+			}
+		})
+	}
+}
+
+func TestHoverAtPos_values(t *testing.T) {
+	cfg := `variable "cidr" {
+  default = "10.0.0.0/16"
+}
+variable "env" {
+  default = "dev"
+}
+variable "password" {
+  sensitive = true
+}
+variable "zones" {
+  type = list(string)
+}
+locals {
+  name_prefix = "${local.project}-${var.env}"
+  project     = upper("app")
+}
+output "subnet" {
+  value = cidrsubnet(var.cidr, 8, 1)
+}
+output "prefix" {
+  value = local.name_prefix
+}
+output "zones" {
+  value = var.zones
+}
+output "password" {
+  value = var.password
+}
+resource "test_instance" "one" {}
+output "unknown" {
+  value = format("%s-%s", local.name_prefix, test_instance.one.id)
+}
+locals {
+  unsupported = file("${path.module}/foo.txt")
+  derived     = "${local.unsupported}-foo"
+}
+output "unsupported" {
+  value = local.derived
+}
+output "invalid" {
+  value = index(var.zones, "c")
+}
+output "functions" {
+  value = [
+    replace(var.env, "/o+/", "0"),
+    cidrsubnets(var.cidr, 4, 8),
+    base64decode(base64encode(var.env)),
+    md5(var.env),
+    index(var.zones, "b"),
+    sum([1, 2.5]),
+    try(local.missing, "fallback"),
+    can(var.zones[5]),
+    yamlencode({ env = var.env, zones = var.zones }),
+  ]
+}
+`
+	tfvars := `env   = "prod"
+zones = ["a", "b"]
+`
+	testCases := []struct {
+		name            string
+		offset          int
+		expectedContent string
+	}{
+		{
+			"function call",
+			strings.Index(cfg, "cidrsubnet"),
+			"**cidrsubnet(...)** _function_\n\nValue: `\"10.0.1.0/24\"`",
+		},
+		{
+			"local value referring to variables and locals",
+			strings.Index(cfg, "local.name_prefix\n"),
+			"**local.name_prefix** _any_\n\nExpression: `\"${local.project}-${var.env}\"`\n\nValue: `\"APP-prod\"`",
+		},
+		{
+			"variable from tfvars",
+			strings.Index(cfg, "var.zones"),
+			"**var.zones** _list(string)_\n\nValue: `[\"a\", \"b\"]`",
+		},
+		{
+			"sensitive variable",
+			strings.Index(cfg, "var.password"),
+			"**var.password** _any_\n\nValue: (sensitive)",
+		},
+		{
+			"unknown function call",
+			strings.Index(cfg, "format"),
+			"**format(...)** _function_\n\nValue: (known after apply)",
+		},
+		{
+			"unsupported function call",
+			strings.Index(cfg, "file("),
+			"**file(...)** _function_",
+		},
+		{
+			"local value referring to erroneous local value",
+			strings.Index(cfg, "local.derived\n"),
+			"**local.derived** _any_\n\nExpression: `\"${local.unsupported}-foo\"`",
+		},
+		{
+			"function call with invalid arguments",
+			strings.Index(cfg, "index(var.zones, \"c\")"),
+			"**index(...)** _function_",
+		},
+		{
+			"replace function with regular expression",
+			strings.Index(cfg, "replace"),
+			"**replace(...)** _function_\n\nValue: `\"pr0d\"`",
+		},
+		{
+			"cidrsubnets function",
+			strings.Index(cfg, "cidrsubnets"),
+			"**cidrsubnets(...)** _function_\n\nValue: `[\"10.0.0.0/20\", \"10.0.16.0/24\"]`",
+		},
+		{
+			"base64 functions",
+			strings.Index(cfg, "base64decode"),
+			"**base64decode(...)** _function_\n\nValue: `\"prod\"`",
+		},
+		{
+			"md5 function",
+			strings.Index(cfg, "md5"),
+			"**md5(...)** _function_\n\nValue: `\"d6e4a9b6646c62fc48baa6dd6150d1f7\"`",
+		},
+		{
+			"index function",
+			strings.Index(cfg, "index(var.zones, \"b\")"),
+			"**index(...)** _function_\n\nValue: `1`",
+		},
+		{
+			"sum function",
+			strings.Index(cfg, "sum("),
+			"**sum(...)** _function_\n\nValue: `3.5`",
+		},
+		{
+			"try function",
+			strings.Index(cfg, "try("),
+			"**try(...)** _function_\n\nValue: `\"fallback\"`",
+		},
+		{
+			"can function",
+			strings.Index(cfg, "can("),
+			"**can(...)** _function_\n\nValue: `false`",
+		},
+		{
+			"yamlencode function",
+			strings.Index(cfg, "yamlencode"),
+			"**yamlencode(...)** _function_\n\nValue: `\"\\\"env\\\": \\\"prod\\\"\\n\\\"zones\\\":\\n- \\\"a\\\"\\n- \\\"b\\\"\\n\"`",
+		},
+	}
+
+	mod := &Module{
+		Files: map[string]*hcl.File{
+			"main.tf": parseFile(t, "main.tf", cfg),
+		},
+		VariableFiles: map[string]*hcl.File{
+			"terraform.tfvars": parseFile(t, "terraform.tfvars", tfvars),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			data, ok := mod.HoverAtPos("main.tf", posAtByte(cfg, tc.offset))
+			if !ok {
+				t.Fatal("expected hover data")
+			}
+			if diff := cmp.Diff(lang.Markdown(tc.expectedContent), data.Content); diff != "" {
+				t.Fatalf("hover content mismatch: %s", diff)
+			}
+		})
+	}
+}
+
+func TestHoverAtPos_declarationDetails(t *testing.T) {
+	cfg := `variable "env" {
+  type    = string
+  default = "dev"
+
+  validation {
+    condition     = contains(["dev", "prod"], var.env)
+    error_message = "Environment must be dev or prod."
+  }
+}
+variable "tags" {
+  default = {
+    env = "dev"
+  }
+}
+locals {
+  tags = merge(var.tags, {
+    name = "foo"
+  })
+}
+output "env" {
+  value = var.env
+}
+output "tags" {
+  value = local.tags
+}
+output "default_tags" {
+  value = var.tags
+}
+`
+	testCases := []struct {
+		name            string
+		offset          int
+		expectedContent string
+	}{
+		{
+			"variable with default and validation",
+			strings.Index(cfg, "var.env\n"),
+			"**var.env** _string_\n\n" +
+				"Default: `\"dev\"`\n\n" +
+				"Validation: `contains([\"dev\", \"prod\"], var.env)`\n\n" +
+				"Environment must be dev or prod.\n\n" +
+				"Value: `\"dev\"`",
+		},
+		{
+			"variable with multi-line default",
+			strings.Index(cfg, "var.tags\n"),
+			"**var.tags** _any_\n\n" +
+				"Default: \n```hcl\n{\n    env = \"dev\"\n  }\n```\n\n" +
+				"Value: \n```hcl\n{\n  env = \"dev\"\n}\n```",
+		},
+		{
+			"local with multi-line expression",
+			strings.Index(cfg, "local.tags"),
+			"**local.tags** _any_\n\n" +
+				"Expression: \n```hcl\nmerge(var.tags, {\n    name = \"foo\"\n  })\n```\n\n" +
+				"Value: \n```hcl\n{\n  env  = \"dev\"\n  name = \"foo\"\n}\n```",
+		},
+	}
+
+	mod := &Module{
+		Files: map[string]*hcl.File{
+			"main.tf": parseFile(t, "main.tf", cfg),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			data, ok := mod.HoverAtPos("main.tf", posAtByte(cfg, tc.offset))
+			if !ok {
+				t.Fatal("expected hover data")
+			}
+			if diff := cmp.Diff(lang.Markdown(tc.expectedContent), data.Content); diff != "" {
+				t.Fatalf("hover content mismatch: %s", diff)
+			}
+		})
+	}
+}
+
+func TestHoverAtPos_noReference(t *testing.T) {
+	cfg := `output "out" {
+  value = var.undeclared
+}
+`
+	mod := &Module{
+		Files: map[string]*hcl.File{
+			"main.tf": parseFile(t, "main.tf", cfg),
+		},
+	}
+
+	for _, offset := range []int{2, strings.Index(cfg, "var")} {
+		_, ok := mod.HoverAtPos("main.tf", posAtByte(cfg, offset))
+		if ok {
+			t.Fatalf("expected no hover data at %d", offset)
+		}
+	}
+}
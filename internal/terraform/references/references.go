@@ -0,0 +1,441 @@
+// Package references provides completion and hover data for references
+// to named objects (such as variables, local values or outputs
+// of called modules) within Terraform configuration
+package references
+
+import (
+	"bytes"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/hcl-lang/lang"
+	"github.com/hashicorp/hcl-lang/schema"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/ext/typeexpr"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/hashicorp/terraform-ls/internal/terraform/declarations"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// Module represents a module within which references are completed
+type Module struct {
+	// Files contains parsed files of the module, keyed by filename
+	Files map[string]*hcl.File
+
+	// Children contains parsed files of called modules,
+	// keyed by name of the module call
+	Children map[string]map[string]*hcl.File
+
+	// VariableFiles contains parsed variable definitions files
+	// (terraform.tfvars and *.auto.tfvars), keyed by filename
+	VariableFiles map[string]*hcl.File
+
+	// Schema is the merged schema of the module, used to complete
+	// attributes of resources and data sources (if available)
+	Schema *schema.BodySchema
+}
+
+// CandidatesAtPos returns candidates for completion of a reference
+// being typed at the given position in src, which is content
+// of the file of the given filename within the module.
+//
+// The returned bool reports whether there is a reference
+// at the given position with any matching candidates.
+// Identifiers being typed may as well be keywords (such as true)
+// or object keys, so other candidates should be offered
+// alongside these, or instead of these when there are none.
+func (m *Module) CandidatesAtPos(filename string, src []byte, pos hcl.Pos) (lang.Candidates, bool) {
+	steps, prefixRng, ok := referencePrefix(src, filename, pos)
+	if !ok {
+		return lang.ZeroCandidates(), false
+	}
+
+	decls := declarations.Decode(m.Files)
+	scope := scopeAtPos(m.Files, filename, pos)
+	prefix := steps[len(steps)-1]
+
+	var items []item
+	switch len(steps) {
+	case 1:
+		items = m.rootItems(decls, scope)
+	case 2:
+		items = m.secondStepItems(decls, scope, steps[0])
+	case 3:
+		items = m.thirdStepItems(decls, steps[0], steps[1])
+	case 4:
+		if steps[0] == "data" {
+			items = m.attributeItems("data", steps[1])
+		}
+	}
+
+	cs := candidates(items, prefix, prefixRng)
+	if len(cs.List) == 0 {
+		return lang.ZeroCandidates(), false
+	}
+	return cs, true
+}
+
+func (m *Module) rootItems(decls *declarations.Module, scope scope) []item {
+	items := []item{
+		{name: "var", detail: "input variables"},
+		{name: "local", detail: "local values"},
+		{name: "module", detail: "module calls"},
+		{name: "data", detail: "data sources"},
+		{name: "path", detail: "filesystem paths"},
+		{name: "terraform", detail: "terraform metadata"},
+	}
+	if scope.hasCount {
+		items = append(items, item{name: "count", detail: "count metadata"})
+	}
+	if scope.hasForEach {
+		items = append(items, item{name: "each", detail: "for_each metadata"})
+	}
+	if scope.inProvisioner {
+		items = append(items, item{name: "self", detail: "enclosing resource"})
+	}
+
+	resourceTypes := make(map[string]bool, 0)
+	for _, decl := range decls.Resources {
+		resourceTypes[decl.Block.Labels[0]] = true
+	}
+	for rType := range resourceTypes {
+		items = append(items, item{name: rType, detail: "resource type"})
+	}
+
+	return items
+}
+
+func (m *Module) secondStepItems(decls *declarations.Module, scope scope, rootName string) []item {
+	items := make([]item, 0)
+
+	switch rootName {
+	case "var":
+		for name, decl := range decls.Variables {
+			items = append(items, item{
+				name:        name,
+				detail:      variableType(decl),
+				description: description(decl),
+			})
+		}
+	case "local":
+		for name, decl := range decls.Locals {
+			items = append(items, item{
+				name:   name,
+				detail: localType(decl),
+			})
+		}
+	case "module":
+		for name := range decls.ModuleCalls {
+			items = append(items, item{name: name, detail: "module"})
+		}
+	case "data":
+		dsTypes := make(map[string]bool, 0)
+		for _, decl := range decls.DataSources {
+			dsTypes[decl.Block.Labels[0]] = true
+		}
+		for dsType := range dsTypes {
+			items = append(items, item{name: dsType, detail: "data source type"})
+		}
+	case "path":
+		for _, name := range []string{"cwd", "module", "root"} {
+			items = append(items, item{name: name, detail: "string"})
+		}
+	case "terraform":
+		items = append(items, item{name: "workspace", detail: "string"})
+	case "count":
+		if scope.hasCount {
+			items = append(items, item{name: "index", detail: "number"})
+		}
+	case "each":
+		if scope.hasForEach {
+			items = append(items,
+				item{name: "key", detail: "string"},
+				item{name: "value", detail: "any"})
+		}
+	case "self":
+		if scope.inProvisioner && scope.block != nil && len(scope.block.Labels) > 0 {
+			items = m.attributeItems("resource", scope.block.Labels[0])
+		}
+	default:
+		for _, decl := range decls.Resources {
+			if decl.Block.Labels[0] == rootName {
+				items = append(items, item{name: decl.Block.Labels[1], detail: "resource"})
+			}
+		}
+	}
+
+	return items
+}
+
+func (m *Module) thirdStepItems(decls *declarations.Module, rootName, name string) []item {
+	items := make([]item, 0)
+
+	switch rootName {
+	case "module":
+		childFiles, ok := m.Children[name]
+		if !ok {
+			return items
+		}
+		child := declarations.Decode(childFiles)
+		for name, decl := range child.Outputs {
+			items = append(items, item{
+				name:        name,
+				detail:      "output",
+				description: description(decl),
+			})
+		}
+	case "data":
+		for _, decl := range decls.DataSources {
+			if decl.Block.Labels[0] == name {
+				items = append(items, item{name: decl.Block.Labels[1], detail: "data source"})
+			}
+		}
+	case "var", "local", "path", "terraform", "count", "each", "self":
+	default:
+		items = m.attributeItems("resource", rootName)
+	}
+
+	return items
+}
+
+// attributeItems returns attributes of the resource or data source
+// of the given type as found in the schema
+func (m *Module) attributeItems(blockType, typeName string) []item {
+	items := make([]item, 0)
+	if m.Schema == nil {
+		return items
+	}
+	bSchema, ok := m.Schema.Blocks[blockType]
+	if !ok {
+		return items
+	}
+	bodySchema, ok := bSchema.DependentBodySchema(schema.DependencyKeys{
+		Labels: []schema.LabelDependent{
+			{Index: 0, Value: typeName},
+		},
+	})
+	if !ok {
+		return items
+	}
+
+	for name, aSchema := range bodySchema.Attributes {
+		detail := "any"
+		if aSchema.ValueType != cty.NilType {
+			detail = aSchema.ValueType.FriendlyName()
+		}
+		items = append(items, item{
+			name:         name,
+			detail:       detail,
+			description:  aSchema.Description,
+			isDeprecated: aSchema.IsDeprecated,
+			isComputed:   aSchema.IsComputed,
+		})
+	}
+
+	return items
+}
+
+type item struct {
+	name         string
+	detail       string
+	description  lang.MarkupContent
+	isDeprecated bool
+	isComputed   bool
+}
+
+func candidates(items []item, prefix string, rng hcl.Range) lang.Candidates {
+	sort.Slice(items, func(i, j int) bool {
+		return items[i].name < items[j].name
+	})
+
+	cs := lang.NewCandidates()
+	cs.IsComplete = true
+	for _, it := range items {
+		if !strings.HasPrefix(it.name, prefix) {
+			continue
+		}
+		cs.List = append(cs.List, lang.Candidate{
+			Label:        it.name,
+			Detail:       it.detail,
+			Description:  it.description,
+			IsDeprecated: it.isDeprecated,
+			Kind:         lang.AttributeCandidateKind,
+			TextEdit: lang.TextEdit{
+				Range:   rng,
+				NewText: it.name,
+				Snippet: it.name,
+			},
+		})
+	}
+	return cs
+}
+
+func description(decl *declarations.Declaration) lang.MarkupContent {
+	if d, ok := decl.StringAttribute("description"); ok {
+		return lang.PlainText(d)
+	}
+	return lang.MarkupContent{}
+}
+
+func variableType(decl *declarations.Declaration) string {
+	attr, ok := decl.Block.Body.Attributes["type"]
+	if !ok {
+		return "any"
+	}
+	t, diags := typeexpr.TypeConstraint(attr.Expr)
+	if diags.HasErrors() {
+		return "any"
+	}
+	return typeexpr.TypeString(t)
+}
+
+func localType(decl *declarations.Declaration) string {
+	val, diags := decl.Attribute.Expr.Value(nil)
+	if diags.HasErrors() || !val.IsWhollyKnown() {
+		return "any"
+	}
+	return val.Type().FriendlyName()
+}
+
+// scope describes the context of the position in which
+// a reference is being completed
+type scope struct {
+	// block is the top-level block containing the position
+	block *hclsyntax.Block
+
+	hasCount      bool
+	hasForEach    bool
+	inProvisioner bool
+}
+
+func scopeAtPos(files map[string]*hcl.File, filename string, pos hcl.Pos) scope {
+	s := scope{}
+
+	f, ok := files[filename]
+	if !ok {
+		return s
+	}
+	body, ok := f.Body.(*hclsyntax.Body)
+	if !ok {
+		return s
+	}
+
+	for _, block := range body.Blocks {
+		if !block.Range().ContainsOffset(pos.Byte) {
+			continue
+		}
+		s.block = block
+		_, s.hasCount = block.Body.Attributes["count"]
+		_, s.hasForEach = block.Body.Attributes["for_each"]
+
+		for _, nested := range block.Body.Blocks {
+			if nested.Type != "provisioner" && nested.Type != "connection" {
+				continue
+			}
+			if nested.Range().ContainsOffset(pos.Byte) {
+				s.inProvisioner = true
+			}
+		}
+		break
+	}
+
+	return s
+}
+
+// referencePrefix returns steps of a (possibly incomplete) reference
+// ending at the given position, such as ["module", "foo", "ba"] for "module.foo.ba",
+// along with range of the last (incomplete) step.
+//
+// Source text is used instead of parsed configuration, as incomplete
+// references (e.g. "module.foo.") cannot be parsed.
+func referencePrefix(src []byte, filename string, pos hcl.Pos) ([]string, hcl.Range, bool) {
+	if pos.Byte > len(src) {
+		return nil, hcl.Range{}, false
+	}
+
+	start := pos.Byte
+	for start > 0 && isReferenceByte(src[start-1]) {
+		start--
+	}
+	if start == pos.Byte || !isExpressionStart(src[:start]) {
+		return nil, hcl.Range{}, false
+	}
+
+	prefix := string(src[start:pos.Byte])
+	steps := strings.Split(prefix, ".")
+	if steps[0] == "" || !isIdentifierStart(steps[0][0]) {
+		return nil, hcl.Range{}, false
+	}
+
+	last := steps[len(steps)-1]
+	lastStart := hcl.Pos{
+		Line:   pos.Line,
+		Column: pos.Column - len(last),
+		Byte:   pos.Byte - len(last),
+	}
+
+	return steps, hcl.Range{
+		Filename: filename,
+		Start:    lastStart,
+		End:      pos,
+	}, true
+}
+
+// isExpressionStart reports whether the source preceding a reference
+// indicates that the reference is part of an expression,
+// as opposed to e.g. an attribute name or a string literal
+func isExpressionStart(preceding []byte) bool {
+	if isInStringLiteral(preceding) {
+		return false
+	}
+
+	text := strings.TrimRight(string(preceding), " \t\r\n")
+	if strings.HasSuffix(text, "${") {
+		return true
+	}
+	if text == "" {
+		return false
+	}
+	return strings.ContainsRune("=([,:?+-*/%!<>&|", rune(text[len(text)-1]))
+}
+
+// isInStringLiteral reports whether the end of the given source
+// is inside of a quoted string, outside of any interpolation sequence.
+// Only the last line is inspected as quoted strings cannot span lines.
+func isInStringLiteral(preceding []byte) bool {
+	line := preceding
+	if idx := bytes.LastIndexByte(preceding, '\n'); idx >= 0 {
+		line = preceding[idx+1:]
+	}
+
+	inString := false
+	// interpolations tracks nesting of ${ ... } sequences within strings
+	interpolations := 0
+	for i := 0; i < len(line); i++ {
+		switch {
+		case inString && line[i] == '\\':
+			i++
+		case inString && line[i] == '"':
+			inString = false
+		case inString && line[i] == '$' && i+1 < len(line) && line[i+1] == '{':
+			inString = false
+			interpolations++
+			i++
+		case !inString && line[i] == '"':
+			inString = true
+		case !inString && interpolations > 0 && line[i] == '}':
+			inString = true
+			interpolations--
+		}
+	}
+
+	return inString
+}
+
+func isReferenceByte(b byte) bool {
+	return isIdentifierStart(b) || (b >= '0' && b <= '9') || b == '-' || b == '.'
+}
+
+func isIdentifierStart(b byte) bool {
+	return (b >= 'a' && b <= 'z') || (b >= 'A' && b <= 'Z') || b == '_'
+}
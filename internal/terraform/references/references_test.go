@@ -0,0 +1,322 @@
+package references
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/hashicorp/hcl-lang/lang"
+	"github.com/hashicorp/hcl-lang/schema"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/zclconf/go-cty/cty"
+)
+
+func TestCandidatesAtPos(t *testing.T) {
+	childCfg := `output "first" {
+  value       = "foo"
+  description = "First output"
+}
+output "second" {
+  value = "bar"
+}
+`
+	testCases := []struct {
+		name           string
+		cfg            string
+		expectedLabels []string
+		expectedDetail string
+	}{
+		{
+			"root names",
+			`resource "test_instance" "one" {
+  ami = te^
+}
+`,
+			[]string{"terraform", "test_instance"},
+			"",
+		},
+		{
+			"variables with type",
+			`variable "list" {
+  type = list(string)
+}
+variable "untyped" {}
+output "out" {
+  value = var.l^
+}
+`,
+			[]string{"list"},
+			"list(string)",
+		},
+		{
+			"local values",
+			`locals {
+  name = "foo"
+  num  = 42
+}
+output "out" {
+  value = "${local.na^}"
+}
+`,
+			[]string{"name"},
+			"string",
+		},
+		{
+			"module outputs",
+			`module "child" {
+  source = "./child"
+}
+output "out" {
+  value = module.child.^
+}
+`,
+			[]string{"first", "second"},
+			"output",
+		},
+		{
+			"data sources",
+			`data "test_image" "one" {}
+data "test_image" "two" {}
+output "out" {
+  value = data.test_image.^
+}
+`,
+			[]string{"one", "two"},
+			"data source",
+		},
+		{
+			"resource attributes",
+			`resource "test_instance" "one" {
+  ami = "foo"
+}
+output "out" {
+  value = test_instance.one.^
+}
+`,
+			[]string{"ami", "id", "tags"},
+			"",
+		},
+		{
+			"count within count",
+			`resource "test_instance" "one" {
+  count = 2
+  ami   = co^
+}
+`,
+			[]string{"count"},
+			"count metadata",
+		},
+		{
+			"each only within for_each",
+			`resource "test_instance" "one" {
+  for_each = {}
+  ami      = each.^
+}
+`,
+			[]string{"key", "value"},
+			"",
+		},
+		{
+			"self only within provisioner",
+			`resource "test_instance" "one" {
+  ami = se^
+  provisioner "local-exec" {
+    command = self.a^
+  }
+}
+`,
+			[]string{"ami"},
+			"string",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// last cursor marker is where completion is requested
+			idx := strings.LastIndex(tc.cfg, "^")
+			src := strings.ReplaceAll(tc.cfg, "^", "")
+			idx -= strings.Count(tc.cfg[:idx], "^")
+			pos := posAtByte(src, idx)
+
+			mod := &Module{
+				Files: map[string]*hcl.File{
+					"main.tf": parseFile(t, "main.tf", src),
+				},
+				Children: map[string]map[string]*hcl.File{
+					"child": {
+						"main.tf": parseFile(t, "main.tf", childCfg),
+					},
+				},
+				Schema: testSchema,
+			}
+
+			candidates, ok := mod.CandidatesAtPos("main.tf", []byte(src), pos)
+			if !ok {
+				t.Fatal("expected reference at position")
+			}
+
+			labels := make([]string, 0)
+			for _, c := range candidates.List {
+				labels = append(labels, c.Label)
+			}
+			if diff := cmp.Diff(tc.expectedLabels, labels); diff != "" {
+				t.Fatalf("labels mismatch: %s", diff)
+			}
+			if tc.expectedDetail != "" && candidates.List[0].Detail != tc.expectedDetail {
+				t.Fatalf("expected detail %q, given: %q", tc.expectedDetail, candidates.List[0].Detail)
+			}
+		})
+	}
+}
+
+func TestCandidatesAtPos_textEdit(t *testing.T) {
+	src := `output "out" {
+  value = module.child.fi
+}
+`
+	mod := &Module{
+		Files: map[string]*hcl.File{
+			"main.tf": parseFile(t, "main.tf", src),
+		},
+		Children: map[string]map[string]*hcl.File{
+			"child": {
+				"main.tf": parseFile(t, "main.tf", `output "first" { value = "foo" }`),
+			},
+		},
+	}
+
+	pos := hcl.Pos{Line: 2, Column: 26, Byte: 40}
+	candidates, ok := mod.CandidatesAtPos("main.tf", []byte(src), pos)
+	if !ok {
+		t.Fatal("expected reference at position")
+	}
+
+	expectedCandidates := lang.CompleteCandidates([]lang.Candidate{
+		{
+			Label:  "first",
+			Detail: "output",
+			Kind:   lang.AttributeCandidateKind,
+			TextEdit: lang.TextEdit{
+				Range: hcl.Range{
+					Filename: "main.tf",
+					Start:    hcl.Pos{Line: 2, Column: 24, Byte: 38},
+					End:      pos,
+				},
+				NewText: "first",
+				Snippet: "first",
+			},
+		},
+	})
+	if diff := cmp.Diff(expectedCandidates, candidates); diff != "" {
+		t.Fatalf("candidates mismatch: %s", diff)
+	}
+}
+
+func TestCandidatesAtPos_noReference(t *testing.T) {
+	testCases := []struct {
+		name string
+		cfg  string
+	}{
+		{
+			"string literal",
+			`module "child" {
+  source = "./ch^"
+}
+`,
+		},
+		{
+			"attribute name",
+			`resource "test_instance" "one" {
+  am^
+}
+`,
+		},
+		{
+			"no count outside of count",
+			`resource "test_instance" "one" {
+  count = 2
+}
+resource "test_instance" "two" {
+  ami = co^
+}
+`,
+		},
+		{
+			"keyword",
+			`resource "test_instance" "one" {
+  enabled = tr^
+}
+`,
+		},
+		{
+			"object key",
+			`resource "test_instance" "one" {
+  tags = {
+    a = "b",
+    ke^
+  }
+}
+`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			idx := strings.Index(tc.cfg, "^")
+			src := strings.Replace(tc.cfg, "^", "", 1)
+			mod := &Module{
+				Files: map[string]*hcl.File{
+					"main.tf": parseFile(t, "main.tf", src),
+				},
+			}
+
+			_, ok := mod.CandidatesAtPos("main.tf", []byte(src), posAtByte(src, idx))
+			if ok {
+				t.Fatal("expected no reference candidates at position")
+			}
+		})
+	}
+}
+
+var testSchema = &schema.BodySchema{
+	Blocks: map[string]*schema.BlockSchema{
+		"resource": {
+			Labels: []*schema.LabelSchema{
+				{Name: "type", IsDepKey: true},
+				{Name: "name"},
+			},
+			DependentBody: map[schema.SchemaKey]*schema.BodySchema{
+				schema.NewSchemaKey(schema.DependencyKeys{
+					Labels: []schema.LabelDependent{
+						{Index: 0, Value: "test_instance"},
+					},
+				}): {
+					Attributes: map[string]*schema.AttributeSchema{
+						"ami":  {ValueType: cty.String, IsRequired: true},
+						"tags": {ValueType: cty.Map(cty.String), IsOptional: true},
+						"id":   {ValueType: cty.String, IsComputed: true},
+					},
+				},
+			},
+		},
+	},
+}
+
+func posAtByte(src string, offset int) hcl.Pos {
+	line := strings.Count(src[:offset], "\n") + 1
+	lineStart := strings.LastIndex(src[:offset], "\n") + 1
+	return hcl.Pos{
+		Line:   line,
+		Column: offset - lineStart + 1,
+		Byte:   offset,
+	}
+}
+
+func parseFile(t *testing.T, filename, src string) *hcl.File {
+	f, _ := hclsyntax.ParseConfig([]byte(src), filename, hcl.InitialPos)
+	if f == nil {
+		t.Fatal("expected file")
+	}
+	return f
+}
@@ -0,0 +1,235 @@
+// Package snippets provides completion candidates which fill in whole blocks
+// including attributes and nested blocks required by the schema
+package snippets
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/hcl-lang/lang"
+	"github.com/hashicorp/hcl-lang/schema"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/zclconf/go-cty/cty"
+)
+
+const indent = "  "
+
+// blockTypes represent types of blocks whose body is completed
+var blockTypes = map[string]bool{
+	"data":     true,
+	"resource": true,
+}
+
+var placeholderRegexp = regexp.MustCompile(`\$\{\d+(?::([^}]*))?\}`)
+
+// BlockBodyCandidates returns the given label candidates, each followed
+// by a candidate which also fills in body of the block, if the position
+// is within the type label of a resource or data block with empty body.
+//
+// Other candidates are returned unchanged.
+func BlockBodyCandidates(file *hcl.File, pos hcl.Pos, bodySchema *schema.BodySchema,
+	candidates lang.Candidates, includeOptional bool) lang.Candidates {
+	if file == nil || bodySchema == nil {
+		return candidates
+	}
+
+	block, ok := emptyBlockAtTypeLabel(file, pos)
+	if !ok {
+		return candidates
+	}
+	bSchema, ok := bodySchema.Blocks[block.Type]
+	if !ok {
+		return candidates
+	}
+
+	list := make([]lang.Candidate, 0, len(candidates.List)*2)
+	for _, c := range candidates.List {
+		list = append(list, c)
+		if c.Kind != lang.LabelCandidateKind {
+			continue
+		}
+
+		depSchema, ok := bSchema.DependentBodySchema(schema.DependencyKeys{
+			Labels: []schema.LabelDependent{
+				{Index: 0, Value: c.Label},
+			},
+		})
+		if !ok {
+			continue
+		}
+		list = append(list, blockCandidate(c, block, depSchema, includeOptional))
+	}
+
+	return lang.Candidates{
+		List:       list,
+		IsComplete: candidates.IsComplete,
+	}
+}
+
+// emptyBlockAtTypeLabel returns the block whose first label is at the given
+// position, if the block has two labels, its body contains nothing
+// but whitespace and its opening brace is on the same line as the label
+func emptyBlockAtTypeLabel(file *hcl.File, pos hcl.Pos) (*hclsyntax.Block, bool) {
+	body, ok := file.Body.(*hclsyntax.Body)
+	if !ok {
+		return nil, false
+	}
+
+	for _, block := range body.Blocks {
+		if !blockTypes[block.Type] || len(block.Labels) != 2 {
+			continue
+		}
+		if !block.LabelRanges[0].ContainsPos(pos) {
+			continue
+		}
+		if block.OpenBraceRange.Start.Line != block.LabelRanges[0].Start.Line {
+			return nil, false
+		}
+		inner := file.Bytes[block.OpenBraceRange.End.Byte:block.CloseBraceRange.Start.Byte]
+		if len(strings.TrimSpace(string(inner))) > 0 {
+			return nil, false
+		}
+		return block, true
+	}
+
+	return nil, false
+}
+
+// blockCandidate returns candidate which replaces the given label
+// candidate's label along with the rest of the block header
+// and fills in the (empty) body of the block
+func blockCandidate(c lang.Candidate, block *hclsyntax.Block, bodySchema *schema.BodySchema,
+	includeOptional bool) lang.Candidate {
+	name := block.Labels[1]
+	if name == "" {
+		name = "name"
+	}
+
+	placeholder := 2
+	snippet := fmt.Sprintf("%s\" \"${1:%s}\" {", c.Label, escape(name))
+	if lines := bodyLines(bodySchema, includeOptional, 1, &placeholder); len(lines) > 0 {
+		snippet += "\n" + strings.Join(lines, "\n")
+	} else {
+		snippet += fmt.Sprintf("\n%s${%d}", indent, placeholder)
+	}
+
+	detail := "Block with required attributes"
+	if includeOptional {
+		detail = "Block with required and optional attributes"
+	}
+
+	return lang.Candidate{
+		Label:        c.Label + " {...}",
+		Detail:       detail,
+		Description:  c.Description,
+		IsDeprecated: c.IsDeprecated,
+		Kind:         c.Kind,
+		TextEdit: lang.TextEdit{
+			NewText: placeholderRegexp.ReplaceAllString(snippet, "$1"),
+			Snippet: snippet,
+			Range: hcl.Range{
+				Filename: block.OpenBraceRange.Filename,
+				Start:    c.TextEdit.Range.Start,
+				End:      block.OpenBraceRange.End,
+			},
+		},
+		AdditionalTextEdits: []lang.TextEdit{
+			{
+				NewText: "\n",
+				Snippet: "\n",
+				Range: hcl.Range{
+					Filename: block.OpenBraceRange.Filename,
+					Start:    block.OpenBraceRange.End,
+					End:      block.CloseBraceRange.Start,
+				},
+			},
+		},
+	}
+}
+
+// bodyLines returns lines declaring required attributes (and optionally
+// also optional ones) and required blocks of the given body schema,
+// where each value or label is a placeholder
+func bodyLines(bodySchema *schema.BodySchema, includeOptional bool, depth int, placeholder *int) []string {
+	prefix := strings.Repeat(indent, depth)
+	lines := make([]string, 0)
+
+	for _, name := range sortedAttributeNames(bodySchema.Attributes) {
+		aSchema := bodySchema.Attributes[name]
+		if !aSchema.IsRequired && !(includeOptional && aSchema.IsOptional && !aSchema.IsDeprecated) {
+			continue
+		}
+		lines = append(lines, fmt.Sprintf("%s%s = %s", prefix, name,
+			valuePlaceholder(attributeType(aSchema), *placeholder)))
+		*placeholder++
+	}
+
+	for _, blockType := range sortedBlockTypes(bodySchema.Blocks) {
+		bSchema := bodySchema.Blocks[blockType]
+		if bSchema.MinItems == 0 {
+			continue
+		}
+
+		header := prefix + blockType
+		for _, label := range bSchema.Labels {
+			header += fmt.Sprintf(" \"${%d:%s}\"", *placeholder, escape(label.Name))
+			*placeholder++
+		}
+		lines = append(lines, header+" {")
+		if bSchema.Body != nil {
+			lines = append(lines, bodyLines(bSchema.Body, includeOptional, depth+1, placeholder)...)
+		}
+		lines = append(lines, prefix+"}")
+	}
+
+	return lines
+}
+
+func attributeType(aSchema *schema.AttributeSchema) cty.Type {
+	if aSchema.ValueType != cty.NilType {
+		return aSchema.ValueType
+	}
+	if len(aSchema.ValueTypes) > 0 {
+		return aSchema.ValueTypes[0]
+	}
+	return cty.DynamicPseudoType
+}
+
+// valuePlaceholder returns placeholder shaped by the given type
+func valuePlaceholder(ty cty.Type, placeholder int) string {
+	switch {
+	case ty.IsListType(), ty.IsSetType(), ty.IsTupleType():
+		return fmt.Sprintf("[${%d}]", placeholder)
+	case ty.IsMapType(), ty.IsObjectType():
+		return fmt.Sprintf("{${%d}}", placeholder)
+	case ty == cty.String:
+		return fmt.Sprintf("\"${%d}\"", placeholder)
+	}
+	return fmt.Sprintf("${%d}", placeholder)
+}
+
+// escape escapes characters which have special meaning in snippets
+func escape(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `$`, `\$`, `}`, `\}`).Replace(s)
+}
+
+func sortedAttributeNames(attrs map[string]*schema.AttributeSchema) []string {
+	names := make([]string, 0, len(attrs))
+	for name := range attrs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func sortedBlockTypes(blocks map[string]*schema.BlockSchema) []string {
+	types := make([]string, 0, len(blocks))
+	for blockType := range blocks {
+		types = append(types, blockType)
+	}
+	sort.Strings(types)
+	return types
+}
@@ -0,0 +1,169 @@
+package snippets
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/hashicorp/hcl-lang/lang"
+	"github.com/hashicorp/hcl-lang/schema"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/zclconf/go-cty/cty"
+)
+
+var testSchema = &schema.BodySchema{
+	Blocks: map[string]*schema.BlockSchema{
+		"resource": {
+			Labels: []*schema.LabelSchema{
+				{Name: "type", IsDepKey: true},
+				{Name: "name"},
+			},
+			DependentBody: map[schema.SchemaKey]*schema.BodySchema{
+				schema.NewSchemaKey(schema.DependencyKeys{
+					Labels: []schema.LabelDependent{
+						{Index: 0, Value: "test_instance"},
+					},
+				}): {
+					Attributes: map[string]*schema.AttributeSchema{
+						"ami":   {ValueType: cty.String, IsRequired: true},
+						"count": {ValueType: cty.Number, IsRequired: true},
+						"zones": {ValueType: cty.List(cty.String), IsRequired: true},
+						"tags":  {ValueType: cty.Map(cty.String), IsOptional: true},
+						"id":    {ValueType: cty.String, IsComputed: true},
+					},
+					Blocks: map[string]*schema.BlockSchema{
+						"disk": {
+							MinItems: 1,
+							Body: &schema.BodySchema{
+								Attributes: map[string]*schema.AttributeSchema{
+									"size": {ValueType: cty.Number, IsRequired: true},
+								},
+							},
+						},
+						"network": {
+							Body: &schema.BodySchema{},
+						},
+					},
+				},
+			},
+		},
+	},
+}
+
+func TestBlockBodyCandidates(t *testing.T) {
+	testCases := []struct {
+		name            string
+		includeOptional bool
+		expectedSnippet string
+	}{
+		{
+			"required attributes",
+			false,
+			`test_instance" "${1:web}" {
+  ami = "${2}"
+  count = ${3}
+  zones = [${4}]
+  disk {
+    size = ${5}
+  }`,
+		},
+		{
+			"optional attributes",
+			true,
+			`test_instance" "${1:web}" {
+  ami = "${2}"
+  count = ${3}
+  tags = {${4}}
+  zones = [${5}]
+  disk {
+    size = ${6}
+  }`,
+		},
+	}
+
+	src := "resource \"test_\" \"web\" {\n  \n}\n"
+	f, diags := hclsyntax.ParseConfig([]byte(src), "main.tf", hcl.InitialPos)
+	if diags.HasErrors() {
+		t.Fatal(diags)
+	}
+	pos := hcl.Pos{Line: 1, Column: 16, Byte: 15}
+	labelRng := hcl.Range{
+		Filename: "main.tf",
+		Start:    hcl.Pos{Line: 1, Column: 11, Byte: 10},
+		End:      pos,
+	}
+	candidates := lang.Candidates{
+		List: []lang.Candidate{
+			{
+				Label: "test_instance",
+				Kind:  lang.LabelCandidateKind,
+				TextEdit: lang.TextEdit{
+					NewText: "test_instance",
+					Snippet: "test_instance",
+					Range:   labelRng,
+				},
+			},
+		},
+		IsComplete: true,
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			result := BlockBodyCandidates(f, pos, testSchema, candidates, tc.includeOptional)
+			if len(result.List) != 2 {
+				t.Fatalf("expected 2 candidates, %d given", len(result.List))
+			}
+
+			c := result.List[1]
+			if c.Label != "test_instance {...}" {
+				t.Fatalf("unexpected label: %q", c.Label)
+			}
+			if diff := cmp.Diff(tc.expectedSnippet, c.TextEdit.Snippet); diff != "" {
+				t.Fatalf("snippet mismatch: %s", diff)
+			}
+
+			expectedRange := hcl.Range{
+				Filename: "main.tf",
+				Start:    labelRng.Start,
+				End:      hcl.Pos{Line: 1, Column: 25, Byte: 24},
+			}
+			if diff := cmp.Diff(expectedRange, c.TextEdit.Range); diff != "" {
+				t.Fatalf("range mismatch: %s", diff)
+			}
+
+			expectedEdits := []lang.TextEdit{
+				{
+					NewText: "\n",
+					Snippet: "\n",
+					Range: hcl.Range{
+						Filename: "main.tf",
+						Start:    hcl.Pos{Line: 1, Column: 25, Byte: 24},
+						End:      hcl.Pos{Line: 3, Column: 1, Byte: 28},
+					},
+				},
+			}
+			if diff := cmp.Diff(expectedEdits, c.AdditionalTextEdits); diff != "" {
+				t.Fatalf("additional edits mismatch: %s", diff)
+			}
+		})
+	}
+}
+
+func TestBlockBodyCandidates_nonEmptyBody(t *testing.T) {
+	src := "resource \"test_\" \"web\" {\n  ami = \"foo\"\n}\n"
+	f, diags := hclsyntax.ParseConfig([]byte(src), "main.tf", hcl.InitialPos)
+	if diags.HasErrors() {
+		t.Fatal(diags)
+	}
+	candidates := lang.Candidates{
+		List: []lang.Candidate{
+			{Label: "test_instance", Kind: lang.LabelCandidateKind},
+		},
+		IsComplete: true,
+	}
+
+	result := BlockBodyCandidates(f, hcl.Pos{Line: 1, Column: 16, Byte: 15}, testSchema, candidates, false)
+	if diff := cmp.Diff(candidates, result); diff != "" {
+		t.Fatalf("unexpected candidates: %s", diff)
+	}
+}
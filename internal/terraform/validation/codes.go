@@ -0,0 +1,80 @@
+package validation
+
+// Code is a stable identifier of a kind of diagnostic, which (unlike
+// the summary or detail) is not expected to change between releases.
+// Codes are set by the validator producing each diagnostic.
+type Code string
+
+const (
+	CodeUnsupportedArgument     Code = "unsupported-argument"
+	CodeUnsupportedBlockType    Code = "unsupported-block-type"
+	CodeMissingRequiredArgument Code = "missing-required-argument"
+	CodeMissingLabel            Code = "missing-label"
+	CodeExtraneousLabel         Code = "extraneous-label"
+	CodeCountAndForEach         Code = "count-and-for-each"
+
+	CodeUndeclaredVariable   Code = "undeclared-variable"
+	CodeUndeclaredLocal      Code = "undeclared-local"
+	CodeUndeclaredModule     Code = "undeclared-module"
+	CodeUndeclaredResource   Code = "undeclared-resource"
+	CodeUnsupportedAttribute Code = "unsupported-attribute"
+
+	CodeDuplicateVariable   Code = "duplicate-variable"
+	CodeDuplicateLocal      Code = "duplicate-local"
+	CodeDuplicateOutput     Code = "duplicate-output"
+	CodeDuplicateModuleCall Code = "duplicate-module-call"
+	CodeDuplicateResource   Code = "duplicate-resource"
+
+	CodeUnusedVariable   Code = "unused-variable"
+	CodeUnusedLocal      Code = "unused-local"
+	CodeUnusedDataSource Code = "unused-data-source"
+
+	CodeDeprecatedResourceType      Code = "deprecated-resource-type"
+	CodeDeprecatedAttribute         Code = "deprecated-attribute"
+	CodeDeprecatedBlock             Code = "deprecated-block"
+	CodeDeprecatedInterpolation     Code = "deprecated-interpolation"
+	CodeDeprecatedFunction          Code = "deprecated-function"
+	CodeRemovedFunction             Code = "removed-function"
+	CodeUnsupportedTerraformVersion Code = "unsupported-terraform-version"
+	CodeUnsupportedProviderVersion  Code = "unsupported-provider-version"
+)
+
+const docsURL = "https://www.terraform.io/docs/configuration/"
+
+var docsByCode = map[Code]string{
+	CodeUnsupportedArgument:     docsURL + "syntax.html",
+	CodeUnsupportedBlockType:    docsURL + "syntax.html",
+	CodeMissingRequiredArgument: docsURL + "syntax.html",
+	CodeMissingLabel:            docsURL + "syntax.html",
+	CodeExtraneousLabel:         docsURL + "syntax.html",
+	CodeCountAndForEach:         docsURL + "resources.html",
+
+	CodeUndeclaredVariable:   docsURL + "variables.html",
+	CodeUndeclaredLocal:      docsURL + "locals.html",
+	CodeUndeclaredModule:     docsURL + "modules.html",
+	CodeUndeclaredResource:   docsURL + "resources.html",
+	CodeUnsupportedAttribute: docsURL + "expressions.html",
+
+	CodeDuplicateVariable:   docsURL + "variables.html",
+	CodeDuplicateLocal:      docsURL + "locals.html",
+	CodeDuplicateOutput:     docsURL + "outputs.html",
+	CodeDuplicateModuleCall: docsURL + "modules.html",
+	CodeDuplicateResource:   docsURL + "resources.html",
+
+	CodeUnusedVariable:   docsURL + "variables.html",
+	CodeUnusedLocal:      docsURL + "locals.html",
+	CodeUnusedDataSource: docsURL + "data-sources.html",
+
+	CodeDeprecatedInterpolation:     docsURL + "expressions.html",
+	CodeDeprecatedFunction:          docsURL + "functions.html",
+	CodeRemovedFunction:             docsURL + "functions.html",
+	CodeUnsupportedTerraformVersion: docsURL + "terraform.html",
+	CodeUnsupportedProviderVersion:  docsURL + "provider-requirements.html",
+}
+
+// DocsURL returns URL of documentation related to the code, if any.
+// Deprecations of provider resources and attributes are documented
+// by each provider, so no URL is returned for these.
+func (c Code) DocsURL() string {
+	return docsByCode[c]
+}
@@ -0,0 +1,49 @@
+package validation
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/hashicorp/go-version"
+	"github.com/hashicorp/hcl/v2"
+)
+
+func TestDiagnostics_codes(t *testing.T) {
+	cfg := `variable "name" {}
+variable "name" {}
+resource "test_instance" "one" {
+  ami  = "${var.ami}"
+  tags = list("a")
+}
+resource "test_instance" {}
+`
+	files := map[string]*hcl.File{
+		"test.tf": hclFile(t, "test.tf", cfg),
+	}
+	v := version.Must(version.NewVersion("0.14.0"))
+
+	diags := ValidateModule(files, testSchema(t, files))
+	diags.Merge(Deprecations(files, testSchema(t, files), v))
+	diags.Merge(UnusedDeclarations(files))
+
+	codes := make([]Code, 0)
+	for _, diag := range diags.Files["test.tf"] {
+		details, ok := diags.Details[diag]
+		if !ok {
+			t.Fatalf("expected details of %q", diag.Summary)
+		}
+		codes = append(codes, details.Code)
+	}
+
+	expectedCodes := []Code{
+		CodeDuplicateVariable,
+		CodeUnusedVariable,
+		CodeDeprecatedInterpolation,
+		CodeUndeclaredVariable,
+		CodeDeprecatedFunction,
+		CodeMissingLabel,
+	}
+	if diff := cmp.Diff(expectedCodes, codes); diff != "" {
+		t.Fatalf("codes mismatch: %s", diff)
+	}
+}
@@ -0,0 +1,164 @@
+package validation
+
+import (
+	"fmt"
+
+	"github.com/hashicorp/go-version"
+	"github.com/hashicorp/hcl-lang/schema"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/hashicorp/terraform-ls/internal/terraform/declarations"
+)
+
+var (
+	// interpolationOnlyDeprecatedVersion is the first version
+	// which warns about interpolation-only expressions
+	interpolationOnlyDeprecatedVersion = version.Must(version.NewVersion("0.12.14"))
+)
+
+// Deprecations reports use of deprecated resources, data sources,
+// attributes and blocks according to the given (merged) schema,
+// as well as deprecated language constructs according to the given
+// Terraform version.
+//
+// Version-specific constructs are only reported when tfVersion is known.
+func Deprecations(files map[string]*hcl.File, bodySchema *schema.BodySchema, tfVersion *version.Version) Diagnostics {
+	diags := newDiagnostics(files)
+
+	for _, f := range files {
+		body, ok := f.Body.(*hclsyntax.Body)
+		if !ok {
+			continue
+		}
+
+		if bodySchema != nil {
+			deprecatedSchemaItems(diags, body, bodySchema)
+		}
+		if tfVersion != nil {
+			deprecatedConstructs(diags, body, tfVersion)
+		}
+	}
+
+	diags.sort()
+
+	return diags
+}
+
+func deprecatedSchemaItems(diags Diagnostics, body *hclsyntax.Body, bodySchema *schema.BodySchema) {
+	for _, block := range body.Blocks {
+		bSchema, ok := bodySchema.Blocks[block.Type]
+		if !ok || len(block.Labels) != len(bSchema.Labels) {
+			continue
+		}
+
+		if !hasDependencyKeys(bSchema) {
+			if bSchema.Body != nil {
+				deprecatedInBody(diags, block.Body, bSchema.Body)
+			}
+			continue
+		}
+
+		depSchema, ok := bSchema.DependentBodySchema(dependencyKeysFromBlock(block, bSchema))
+		if !ok {
+			continue
+		}
+
+		if depSchema.IsDeprecated && len(block.Labels) > 0 {
+			diags.add(CodeDeprecatedResourceType, &hcl.Diagnostic{
+				Severity: hcl.DiagWarning,
+				Summary:  fmt.Sprintf("Deprecated %s type", block.Type),
+				Detail:   fmt.Sprintf("The %s type %q is deprecated.", block.Type, block.Labels[0]),
+				Subject:  block.LabelRanges[0].Ptr(),
+			})
+		}
+
+		deprecatedInBody(diags, block.Body, mergeBodySchemas(bSchema.Body, depSchema))
+	}
+}
+
+func deprecatedInBody(diags Diagnostics, body *hclsyntax.Body, bodySchema *schema.BodySchema) {
+	for _, attr := range declarations.SortedAttributes(body) {
+		aSchema, ok := bodySchema.Attributes[attr.Name]
+		if !ok || !aSchema.IsDeprecated {
+			continue
+		}
+		diags.add(CodeDeprecatedAttribute, &hcl.Diagnostic{
+			Severity: hcl.DiagWarning,
+			Summary:  "Deprecated attribute",
+			Detail:   fmt.Sprintf("The attribute %q is deprecated. Refer to the provider documentation for details.", attr.Name),
+			Subject:  attr.NameRange.Ptr(),
+		})
+	}
+
+	for _, block := range body.Blocks {
+		bType, typeRange, nestedBody := block.Type, block.TypeRange, block.Body
+		if block.Type == "dynamic" {
+			if len(block.Labels) != 1 {
+				continue
+			}
+			bType, typeRange = block.Labels[0], block.LabelRanges[0]
+			nestedBody = dynamicContentBody(block)
+		}
+
+		bSchema, ok := bodySchema.Blocks[bType]
+		if !ok {
+			continue
+		}
+		if bSchema.IsDeprecated {
+			diags.add(CodeDeprecatedBlock, &hcl.Diagnostic{
+				Severity: hcl.DiagWarning,
+				Summary:  "Deprecated block",
+				Detail:   fmt.Sprintf("The block type %q is deprecated. Refer to the provider documentation for details.", bType),
+				Subject:  typeRange.Ptr(),
+			})
+		}
+		if bSchema.Body != nil && nestedBody != nil {
+			deprecatedInBody(diags, nestedBody, bSchema.Body)
+		}
+	}
+}
+
+func dynamicContentBody(block *hclsyntax.Block) *hclsyntax.Body {
+	for _, content := range block.Body.Blocks {
+		if content.Type == "content" {
+			return content.Body
+		}
+	}
+	return nil
+}
+
+func deprecatedConstructs(diags Diagnostics, body *hclsyntax.Body, tfVersion *version.Version) {
+	hclsyntax.VisitAll(body, func(node hclsyntax.Node) hcl.Diagnostics {
+		switch expr := node.(type) {
+		case *hclsyntax.TemplateWrapExpr:
+			if tfVersion.LessThan(interpolationOnlyDeprecatedVersion) {
+				return nil
+			}
+			diags.add(CodeDeprecatedInterpolation, &hcl.Diagnostic{
+				Severity: hcl.DiagWarning,
+				Summary:  "Interpolation-only expressions are deprecated",
+				Detail: "Terraform 0.11 and earlier required all non-constant expressions " +
+					"to be provided via interpolation syntax, but this pattern is now deprecated. " +
+					"To silence this warning, remove the \"${ sequence from the start and the }\" " +
+					"sequence from the end of this expression, leaving just the inner expression.",
+				Subject: expr.SrcRange.Ptr(),
+			})
+		case *hclsyntax.FunctionCallExpr:
+			if !isCollectionFunc(expr.Name) {
+				return nil
+			}
+			// removed functions are reported by RemovedFunctions
+			if !tfVersion.LessThan(collectionFuncsRemovedVersion) {
+				return nil
+			}
+			diags.add(CodeDeprecatedFunction, &hcl.Diagnostic{
+				Severity: hcl.DiagWarning,
+				Summary:  fmt.Sprintf("Deprecated %s function", expr.Name),
+				Detail: fmt.Sprintf("The %s function is deprecated since Terraform v0.12; "+
+					"use %s syntax to write a literal %s.", expr.Name, collectionFuncReplacement(expr.Name), expr.Name),
+				Subject: expr.NameRange.Ptr(),
+			})
+		}
+		return nil
+	})
+}
@@ -0,0 +1,102 @@
+package validation
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/hashicorp/go-version"
+	"github.com/hashicorp/hcl/v2"
+)
+
+func TestDeprecations(t *testing.T) {
+	testCases := []struct {
+		name              string
+		cfg               string
+		tfVersion         string
+		expectedSummaries []string
+	}{
+		{
+			"no deprecations",
+			`resource "test_instance" "one" {
+  ami  = var.ami
+  tags = tomap({ a = "b" })
+}
+`,
+			"0.14.0",
+			[]string{},
+		},
+		{
+			"deprecated schema items",
+			`resource "test_instance" "one" {
+  ami    = "foo"
+  legacy = "bar"
+  legacy_disk {}
+  dynamic "legacy_disk" {
+    for_each = []
+    content {}
+  }
+}
+data "test_legacy_image" "two" {
+}
+`,
+			"0.14.0",
+			[]string{
+				"Deprecated attribute",
+				"Deprecated block",
+				"Deprecated block",
+				"Deprecated data type",
+			},
+		},
+		{
+			"deprecated constructs",
+			`resource "test_instance" "one" {
+  ami  = "${var.ami}"
+  tags = map("a", list("b"))
+}
+`,
+			"0.14.0",
+			[]string{
+				"Interpolation-only expressions are deprecated",
+				"Deprecated map function",
+				"Deprecated list function",
+			},
+		},
+		{
+			"removed functions",
+			`resource "test_instance" "one" {
+  ami  = "foo"
+  tags = map("a", "b")
+}
+`,
+			"0.15.0",
+			[]string{},
+		},
+		{
+			"interpolation-only expressions in older version",
+			`resource "test_instance" "one" {
+  ami = "${var.ami}"
+}
+`,
+			"0.12.0",
+			[]string{},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			files := map[string]*hcl.File{
+				"test.tf": hclFile(t, "test.tf", tc.cfg),
+			}
+
+			diagsMap := Deprecations(files, testSchema(t, files), version.Must(version.NewVersion(tc.tfVersion))).Files
+			summaries := make([]string, 0)
+			for _, diag := range diagsMap["test.tf"] {
+				summaries = append(summaries, diag.Summary)
+			}
+
+			if diff := cmp.Diff(tc.expectedSummaries, summaries); diff != "" {
+				t.Fatalf("diagnostics mismatch: %s", diff)
+			}
+		})
+	}
+}
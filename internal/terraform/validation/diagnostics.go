@@ -0,0 +1,68 @@
+package validation
+
+import (
+	"github.com/hashicorp/hcl/v2"
+)
+
+// Details represents details of a diagnostic
+// which hcl.Diagnostic has no room for
+type Details struct {
+	Code Code
+
+	// OriginalDeclaration is the range of the first declaration
+	// of an object declared again in the subject of the diagnostic
+	OriginalDeclaration *hcl.Range
+}
+
+// DiagnosticDetails maps diagnostics to their details
+type DiagnosticDetails map[*hcl.Diagnostic]Details
+
+// Diagnostics represents diagnostics of a single module
+// along with details of each diagnostic, as set by
+// the validator which produced it
+type Diagnostics struct {
+	// Files contains diagnostics keyed by filename,
+	// with an entry for each file, even if it has no diagnostics
+	Files map[string]hcl.Diagnostics
+
+	Details DiagnosticDetails
+}
+
+func newDiagnostics(files map[string]*hcl.File) Diagnostics {
+	diags := Diagnostics{
+		Files:   make(map[string]hcl.Diagnostics, len(files)),
+		Details: make(DiagnosticDetails, 0),
+	}
+	for filename := range files {
+		diags.Files[filename] = make(hcl.Diagnostics, 0)
+	}
+	return diags
+}
+
+// add appends the diagnostic to diagnostics of the file in its subject
+func (d Diagnostics) add(code Code, diag *hcl.Diagnostic) {
+	d.addDetailed(diag, Details{Code: code})
+}
+
+func (d Diagnostics) addDetailed(diag *hcl.Diagnostic, details Details) {
+	filename := diag.Subject.Filename
+	d.Files[filename] = append(d.Files[filename], diag)
+	d.Details[diag] = details
+}
+
+// Merge appends diagnostics of other to d
+func (d Diagnostics) Merge(other Diagnostics) {
+	for filename, diags := range other.Files {
+		d.Files[filename] = append(d.Files[filename], diags...)
+		sortDiagnostics(d.Files[filename])
+	}
+	for diag, details := range other.Details {
+		d.Details[diag] = details
+	}
+}
+
+func (d Diagnostics) sort() {
+	for _, diags := range d.Files {
+		sortDiagnostics(diags)
+	}
+}
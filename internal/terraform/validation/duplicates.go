@@ -0,0 +1,134 @@
+package validation
+
+import (
+	"fmt"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/hashicorp/terraform-ls/internal/terraform/declarations"
+)
+
+// declaredObject represents a single declaration of a named object,
+// incl. any declaration duplicating an earlier one
+type declaredObject struct {
+	kind      string
+	blockType string
+	name      string
+	rng       hcl.Range
+}
+
+type declaredObjectKey struct {
+	kind, blockType, name string
+}
+
+func (o declaredObject) key() declaredObjectKey {
+	return declaredObjectKey{o.kind, o.blockType, o.name}
+}
+
+// duplicateDeclarations reports objects declared more than once within
+// the given files, where the first declaration is considered the original
+func duplicateDeclarations(diags Diagnostics, files map[string]*hcl.File) {
+	originals := make(map[declaredObjectKey]declaredObject, 0)
+	for _, obj := range declaredObjects(files) {
+		orig, ok := originals[obj.key()]
+		if !ok {
+			originals[obj.key()] = obj
+			continue
+		}
+		diag, code := duplicateDiag(obj, orig)
+		diags.addDetailed(diag, Details{
+			Code:                code,
+			OriginalDeclaration: orig.rng.Ptr(),
+		})
+	}
+}
+
+// declaredObjects returns all declarations within the given files
+// in the order in which they appear
+func declaredObjects(files map[string]*hcl.File) []declaredObject {
+	objects := make([]declaredObject, 0)
+
+	for _, filename := range declarations.SortedFilenames(files) {
+		body, ok := files[filename].Body.(*hclsyntax.Body)
+		if !ok {
+			continue
+		}
+
+		for _, block := range body.Blocks {
+			switch block.Type {
+			case "variable", "output", "module":
+				if len(block.Labels) == 1 {
+					objects = append(objects, declaredObject{
+						kind: block.Type,
+						name: block.Labels[0],
+						rng:  block.DefRange(),
+					})
+				}
+			case "resource", "data":
+				if len(block.Labels) == 2 {
+					objects = append(objects, declaredObject{
+						kind:      block.Type,
+						blockType: block.Labels[0],
+						name:      block.Labels[1],
+						rng:       block.DefRange(),
+					})
+				}
+			case "locals":
+				for _, attr := range declarations.SortedAttributes(block.Body) {
+					objects = append(objects, declaredObject{
+						kind: "local",
+						name: attr.Name,
+						rng:  attr.NameRange,
+					})
+				}
+			}
+		}
+	}
+
+	return objects
+}
+
+func duplicateDiag(obj, orig declaredObject) (*hcl.Diagnostic, Code) {
+	var summary, detail string
+	var code Code
+
+	switch obj.kind {
+	case "variable":
+		code = CodeDuplicateVariable
+		summary = "Duplicate variable declaration"
+		detail = fmt.Sprintf("A variable named %q was already declared at %s. "+
+			"Variable names must be unique within a module.", obj.name, orig.rng)
+	case "output":
+		code = CodeDuplicateOutput
+		summary = "Duplicate output definition"
+		detail = fmt.Sprintf("An output named %q was already defined at %s. "+
+			"Output names must be unique within a module.", obj.name, orig.rng)
+	case "module":
+		code = CodeDuplicateModuleCall
+		summary = "Duplicate module call"
+		detail = fmt.Sprintf("A module call named %q was already defined at %s. "+
+			"Module calls must have unique names within a module.", obj.name, orig.rng)
+	case "local":
+		code = CodeDuplicateLocal
+		summary = "Duplicate local value definition"
+		detail = fmt.Sprintf("A local value named %q was already defined at %s. "+
+			"Local value names must be unique within a module.", obj.name, orig.rng)
+	case "resource":
+		code = CodeDuplicateResource
+		summary = fmt.Sprintf("Duplicate resource %q configuration", obj.blockType)
+		detail = fmt.Sprintf("A %s resource named %q was already declared at %s. "+
+			"Resource names must be unique per type in each module.", obj.blockType, obj.name, orig.rng)
+	case "data":
+		code = CodeDuplicateResource
+		summary = fmt.Sprintf("Duplicate data %q configuration", obj.blockType)
+		detail = fmt.Sprintf("A %s data resource named %q was already declared at %s. "+
+			"Resource names must be unique per type in each module.", obj.blockType, obj.name, orig.rng)
+	}
+
+	return &hcl.Diagnostic{
+		Severity: hcl.DiagError,
+		Summary:  summary,
+		Detail:   detail,
+		Subject:  obj.rng.Ptr(),
+	}, code
+}
@@ -0,0 +1,60 @@
+package validation
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/hashicorp/hcl/v2"
+)
+
+func TestDuplicateDeclarations(t *testing.T) {
+	files := map[string]*hcl.File{
+		"main.tf": hclFile(t, "main.tf", `variable "name" {}
+locals {
+  prefix = "foo"
+}
+resource "test_instance" "one" {}
+data "test_instance" "one" {}
+`),
+		"other.tf": hclFile(t, "other.tf", `variable "name" {}
+locals {
+  prefix = "bar"
+}
+resource "test_instance" "one" {}
+resource "test_instance" "two" {}
+output "out" {}
+output "out" {}
+`),
+	}
+
+	diags := newDiagnostics(files)
+	duplicateDeclarations(diags, files)
+	diags.sort()
+
+	details := make([]string, 0)
+	for _, filename := range []string{"main.tf", "other.tf"} {
+		for _, diag := range diags.Files[filename] {
+			details = append(details, diag.Detail)
+		}
+	}
+
+	expectedDetails := []string{
+		`A variable named "name" was already declared at main.tf:1,1-18. Variable names must be unique within a module.`,
+		`A local value named "prefix" was already defined at main.tf:3,3-9. Local value names must be unique within a module.`,
+		`A test_instance resource named "one" was already declared at main.tf:5,1-33. Resource names must be unique per type in each module.`,
+		`An output named "out" was already defined at other.tf:7,1-15. Output names must be unique within a module.`,
+	}
+	if diff := cmp.Diff(expectedDetails, details); diff != "" {
+		t.Fatalf("unexpected diagnostics: %s", diff)
+	}
+
+	for diag, d := range diags.Details {
+		if d.OriginalDeclaration == nil {
+			t.Fatalf("expected original declaration for %q", diag.Summary)
+		}
+		rng := *d.OriginalDeclaration
+		if rng.Start.Line >= diag.Subject.Start.Line && rng.Filename == diag.Subject.Filename {
+			t.Fatalf("expected original declaration before %s, given %s", diag.Subject, rng)
+		}
+	}
+}
@@ -0,0 +1,111 @@
+package validation
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/terraform-ls/internal/terraform/declarations"
+)
+
+// moduleCallMetaAttributes represent arguments of a module block
+// which are not passed to the called module as variables
+var moduleCallMetaAttributes = map[string]bool{
+	"count":      true,
+	"depends_on": true,
+	"for_each":   true,
+	"providers":  true,
+	"source":     true,
+	"version":    true,
+}
+
+// ModuleCalls validates arguments of module calls declared in the given
+// parsed files of a single module against variables declared
+// in the called modules, as well as references to outputs
+// of the called modules.
+//
+// children contains parsed files of called modules,
+// keyed by name of the module call. Calls of modules
+// which are not present in children are not validated.
+func ModuleCalls(files map[string]*hcl.File, children map[string]map[string]*hcl.File) Diagnostics {
+	diags := newDiagnostics(files)
+
+	mod := declarations.Decode(files)
+	childDecls := make(map[string]*declarations.Module, len(children))
+	for name, childFiles := range children {
+		childDecls[name] = declarations.Decode(childFiles)
+	}
+
+	for name, call := range mod.ModuleCalls {
+		child, ok := childDecls[name]
+		if !ok {
+			continue
+		}
+		validateModuleCall(diags, call, child)
+	}
+
+	for _, ref := range declarations.DecodeReferences(files) {
+		if ref.Traversal.RootName() != "module" {
+			continue
+		}
+		name, ok := attrName(ref.Traversal, 1)
+		if !ok {
+			continue
+		}
+		child, ok := childDecls[name]
+		if !ok {
+			continue
+		}
+		output, ok := attrName(ref.Traversal, 2)
+		if !ok {
+			continue
+		}
+		if _, ok := child.Outputs[output]; ok {
+			continue
+		}
+
+		diags.add(CodeUnsupportedAttribute, &hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Unsupported attribute",
+			Detail:   fmt.Sprintf("An output value with the name %q has not been declared in module %q.", output, name),
+			Subject:  ref.Range().Ptr(),
+		})
+	}
+
+	diags.sort()
+
+	return diags
+}
+
+func validateModuleCall(diags Diagnostics, call *declarations.Declaration, child *declarations.Module) {
+	body := call.Block.Body
+
+	for _, attr := range declarations.SortedAttributes(body) {
+		if moduleCallMetaAttributes[attr.Name] {
+			continue
+		}
+		if _, ok := child.Variables[attr.Name]; !ok {
+			diags.add(CodeUnsupportedArgument, unsupportedArgumentDiag(attr))
+		}
+	}
+
+	for _, name := range sortedDeclarationNames(child.Variables) {
+		variable := child.Variables[name]
+		if _, ok := variable.Block.Body.Attributes["default"]; ok {
+			continue
+		}
+		if _, ok := body.Attributes[name]; ok {
+			continue
+		}
+		diags.add(CodeMissingRequiredArgument, missingArgumentDiag(name, call.DeclRange))
+	}
+}
+
+func sortedDeclarationNames(decls map[string]*declarations.Declaration) []string {
+	names := make([]string, 0, len(decls))
+	for name := range decls {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
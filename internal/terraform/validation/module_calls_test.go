@@ -0,0 +1,98 @@
+package validation
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/hashicorp/hcl/v2"
+)
+
+func TestModuleCalls(t *testing.T) {
+	childCfg := `variable "required" {}
+variable "optional" {
+  default = "foo"
+}
+output "out" {
+  value = var.required
+}
+`
+	testCases := []struct {
+		name            string
+		cfg             string
+		expectedDetails []string
+	}{
+		{
+			"valid module call",
+			`module "child" {
+  source   = "./child"
+  required = "bar"
+}
+output "out" {
+  value = module.child.out
+}
+`,
+			[]string{},
+		},
+		{
+			"unknown and missing arguments",
+			`module "child" {
+  source  = "./child"
+  unknown = "bar"
+}
+`,
+			[]string{
+				`The argument "required" is required, but no definition was found.`,
+				`An argument named "unknown" is not expected here.`,
+			},
+		},
+		{
+			"undeclared output",
+			`module "child" {
+  source   = "./child"
+  required = "bar"
+}
+output "out" {
+  value = module.child.unknown
+}
+`,
+			[]string{
+				`An output value with the name "unknown" has not been declared in module "child".`,
+			},
+		},
+		{
+			"unknown module is not validated",
+			`module "remote" {
+  source  = "hashicorp/remote/aws"
+  unknown = "bar"
+}
+output "out" {
+  value = module.remote.unknown
+}
+`,
+			[]string{},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			files := map[string]*hcl.File{
+				"test.tf": hclFile(t, "test.tf", tc.cfg),
+			}
+			children := map[string]map[string]*hcl.File{
+				"child": {
+					"main.tf": hclFile(t, "main.tf", childCfg),
+				},
+			}
+
+			diagsMap := ModuleCalls(files, children).Files
+			details := make([]string, 0)
+			for _, diag := range diagsMap["test.tf"] {
+				details = append(details, diag.Detail)
+			}
+
+			if diff := cmp.Diff(tc.expectedDetails, details); diff != "" {
+				t.Fatalf("diagnostics mismatch: %s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,114 @@
+package validation
+
+import (
+	"fmt"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/terraform-ls/internal/terraform/declarations"
+)
+
+// builtinRootNames represent root names of references
+// which do not refer to any declared object
+var builtinRootNames = map[string]bool{
+	"count":     true,
+	"each":      true,
+	"path":      true,
+	"self":      true,
+	"terraform": true,
+}
+
+func validateReference(diags Diagnostics, mod *declarations.Module, ref declarations.Reference) {
+	traversal := ref.Traversal
+	rootName := traversal.RootName()
+
+	if builtinRootNames[rootName] {
+		return
+	}
+
+	switch rootName {
+	case "var":
+		name, ok := attrName(traversal, 1)
+		if !ok {
+			return
+		}
+		if _, ok := mod.Variables[name]; !ok {
+			diags.add(CodeUndeclaredVariable, &hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Reference to undeclared input variable",
+				Detail: fmt.Sprintf("An input variable with the name %q has not been declared. "+
+					"This variable can be declared with a variable %q {} block.", name, name),
+				Subject: ref.Range().Ptr(),
+			})
+		}
+	case "local":
+		name, ok := attrName(traversal, 1)
+		if !ok {
+			return
+		}
+		if _, ok := mod.Locals[name]; !ok {
+			diags.add(CodeUndeclaredLocal, &hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Reference to undeclared local value",
+				Detail:   fmt.Sprintf("A local value with the name %q has not been declared.", name),
+				Subject:  ref.Range().Ptr(),
+			})
+		}
+	case "module":
+		name, ok := attrName(traversal, 1)
+		if !ok {
+			return
+		}
+		if _, ok := mod.ModuleCalls[name]; !ok {
+			diags.add(CodeUndeclaredModule, &hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Reference to undeclared module",
+				Detail:   fmt.Sprintf("No module call named %q is declared in this module.", name),
+				Subject:  ref.Range().Ptr(),
+			})
+		}
+	case "data":
+		dsType, ok := attrName(traversal, 1)
+		if !ok {
+			return
+		}
+		name, ok := attrName(traversal, 2)
+		if !ok {
+			return
+		}
+		if _, ok := mod.DataSources[dsType+"."+name]; !ok {
+			diags.add(CodeUndeclaredResource, &hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Reference to undeclared resource",
+				Detail: fmt.Sprintf("A data resource %q %q has not been declared in this module.",
+					dsType, name),
+				Subject: ref.Range().Ptr(),
+			})
+		}
+	default:
+		name, ok := attrName(traversal, 1)
+		if !ok {
+			return
+		}
+		if _, ok := mod.Resources[rootName+"."+name]; !ok {
+			diags.add(CodeUndeclaredResource, &hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Reference to undeclared resource",
+				Detail: fmt.Sprintf("A managed resource %q %q has not been declared in this module.",
+					rootName, name),
+				Subject: ref.Range().Ptr(),
+			})
+		}
+	}
+}
+
+// attrName returns name of the attribute step at the given index
+func attrName(traversal hcl.Traversal, idx int) (string, bool) {
+	if len(traversal) <= idx {
+		return "", false
+	}
+	step, ok := traversal[idx].(hcl.TraverseAttr)
+	if !ok {
+		return "", false
+	}
+	return step.Name, true
+}
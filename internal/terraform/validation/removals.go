@@ -0,0 +1,64 @@
+package validation
+
+import (
+	"fmt"
+
+	"github.com/hashicorp/go-version"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+)
+
+// collectionFuncsRemovedVersion is the first version
+// in which list() and map() functions are no longer available
+var collectionFuncsRemovedVersion = version.Must(version.NewVersion("0.15.0"))
+
+// RemovedFunctions reports calls to functions which are no longer
+// available in the given version of Terraform, such as list() and map().
+//
+// Unlike deprecations these are errors, which would fail
+// any plan or apply with the given version.
+func RemovedFunctions(files map[string]*hcl.File, tfVersion *version.Version) Diagnostics {
+	diags := newDiagnostics(files)
+	if tfVersion == nil || tfVersion.LessThan(collectionFuncsRemovedVersion) {
+		return diags
+	}
+
+	for _, f := range files {
+		body, ok := f.Body.(*hclsyntax.Body)
+		if !ok {
+			continue
+		}
+
+		hclsyntax.VisitAll(body, func(node hclsyntax.Node) hcl.Diagnostics {
+			expr, ok := node.(*hclsyntax.FunctionCallExpr)
+			if !ok || !isCollectionFunc(expr.Name) {
+				return nil
+			}
+			diags.add(CodeRemovedFunction, &hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  fmt.Sprintf("Removed %s function", expr.Name),
+				Detail: fmt.Sprintf("The %q function was deprecated in Terraform v0.12 and is no longer available; "+
+					"use %s syntax to write a literal %s.", expr.Name, collectionFuncReplacement(expr.Name), expr.Name),
+				Subject: expr.NameRange.Ptr(),
+			})
+			return nil
+		})
+	}
+
+	diags.sort()
+
+	return diags
+}
+
+func isCollectionFunc(name string) bool {
+	return name == "list" || name == "map"
+}
+
+// collectionFuncReplacement returns the literal syntax
+// to be used instead of the given collection function
+func collectionFuncReplacement(name string) string {
+	if name == "map" {
+		return "tomap({ ... })"
+	}
+	return "tolist([ ... ])"
+}
@@ -0,0 +1,68 @@
+package validation
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/hashicorp/go-version"
+	"github.com/hashicorp/hcl/v2"
+)
+
+func TestRemovedFunctions(t *testing.T) {
+	testCases := []struct {
+		name              string
+		cfg               string
+		tfVersion         *version.Version
+		expectedSummaries []string
+	}{
+		{
+			"unknown version",
+			`resource "test_instance" "one" {
+  tags = map("a", list("b"))
+}
+`,
+			nil,
+			[]string{},
+		},
+		{
+			"deprecated functions",
+			`resource "test_instance" "one" {
+  tags = map("a", list("b"))
+}
+`,
+			version.Must(version.NewVersion("0.14.0")),
+			[]string{},
+		},
+		{
+			"removed functions",
+			`resource "test_instance" "one" {
+  ami  = "foo"
+  tags = map("a", list("b"))
+}
+`,
+			version.Must(version.NewVersion("0.15.0")),
+			[]string{
+				"Removed map function",
+				"Removed list function",
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			files := map[string]*hcl.File{
+				"test.tf": hclFile(t, "test.tf", tc.cfg),
+			}
+
+			diagsMap := RemovedFunctions(files, tc.tfVersion).Files
+			summaries := make([]string, 0)
+			for _, diag := range diagsMap["test.tf"] {
+				summaries = append(summaries, diag.Summary)
+			}
+
+			if diff := cmp.Diff(tc.expectedSummaries, summaries); diff != "" {
+				t.Fatalf("diagnostics mismatch: %s", diff)
+			}
+		})
+	}
+}
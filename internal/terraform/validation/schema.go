@@ -0,0 +1,406 @@
+package validation
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/hcl-lang/lang"
+	"github.com/hashicorp/hcl-lang/schema"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+)
+
+var (
+	// rootBlockTypes represent block types which are valid in any
+	// module, even if the (e.g. universal) core schema doesn't know them
+	rootBlockTypes = map[string]bool{
+		"data":      true,
+		"locals":    true,
+		"module":    true,
+		"output":    true,
+		"provider":  true,
+		"resource":  true,
+		"terraform": true,
+		"variable":  true,
+	}
+
+	// metaBlockTypes represent block types which may not be part
+	// of the schema, but are valid within resource, data or provider blocks
+	metaBlockTypes = map[string]bool{
+		"connection":  true,
+		"dynamic":     true,
+		"lifecycle":   true,
+		"provisioner": true,
+	}
+
+	// metaAttributes represent meta-arguments which may not be part
+	// of the schema, but are valid within resource, data or module blocks
+	metaAttributes = map[string]bool{
+		"count":      true,
+		"depends_on": true,
+		"for_each":   true,
+		"provider":   true,
+	}
+
+	// incompleteBlockTypes represent block types whose schema only
+	// declares common arguments, while the remaining ones depend
+	// on the connection type or provisioner and aren't known
+	incompleteBlockTypes = map[string]bool{
+		"connection":  true,
+		"provisioner": true,
+	}
+
+	countAndForEachBlockTypes = map[string]bool{
+		"data":     true,
+		"module":   true,
+		"resource": true,
+	}
+)
+
+func validateRootBody(diags Diagnostics, body *hclsyntax.Body, bodySchema *schema.BodySchema) {
+	for _, attr := range body.Attributes {
+		diags.add(CodeUnsupportedArgument, unsupportedArgumentDiag(attr))
+	}
+
+	for _, block := range body.Blocks {
+		bSchema, ok := bodySchema.Blocks[block.Type]
+		if !ok {
+			if !rootBlockTypes[block.Type] {
+				diags.add(CodeUnsupportedBlockType, unsupportedBlockDiag(block.Type, block.TypeRange))
+			}
+			continue
+		}
+
+		if countAndForEachBlockTypes[block.Type] {
+			validateCountAndForEach(diags, block)
+		}
+
+		if block.Type == "module" {
+			// arguments of module calls are validated
+			// against the called module by ModuleCalls
+			validateLabels(diags, block, bSchema)
+			continue
+		}
+
+		// Providers can be configured via environment variables
+		// or by the parent module, so we only check what is declared
+		checkRequired := block.Type != "provider"
+
+		validateBlock(diags, block, bSchema, checkRequired)
+	}
+}
+
+func validateBlock(diags Diagnostics, block *hclsyntax.Block, bSchema *schema.BlockSchema, checkRequired bool) {
+	if !validateLabels(diags, block, bSchema) {
+		// dependent schema cannot be reliably found
+		return
+	}
+
+	bodySchema, isComplete := bodySchemaForBlock(block, bSchema)
+	if bodySchema == nil {
+		return
+	}
+	if incompleteBlockTypes[block.Type] {
+		isComplete = false
+	}
+
+	validateBody(diags, block.Body, bodySchema, isComplete, checkRequired)
+}
+
+// validateBody checks attributes and nested blocks against the given schema.
+// Unknown and missing attributes and blocks are only reported
+// when the schema is known to be complete.
+func validateBody(diags Diagnostics, body *hclsyntax.Body, bodySchema *schema.BodySchema, isComplete, checkRequired bool) {
+	if isComplete && bodySchema.AnyAttribute == nil {
+		for _, attr := range body.Attributes {
+			if _, ok := bodySchema.Attributes[attr.Name]; !ok && !metaAttributes[attr.Name] {
+				diags.add(CodeUnsupportedArgument, unsupportedArgumentDiag(attr))
+			}
+		}
+
+		if checkRequired {
+			for name, aSchema := range bodySchema.Attributes {
+				if !aSchema.IsRequired {
+					continue
+				}
+				if _, ok := body.Attributes[name]; !ok {
+					diags.add(CodeMissingRequiredArgument, missingArgumentDiag(name, body.MissingItemRange()))
+				}
+			}
+		}
+	}
+
+	for _, block := range body.Blocks {
+		if block.Type == "dynamic" {
+			validateDynamicBlock(diags, block, bodySchema, isComplete, checkRequired)
+			continue
+		}
+
+		bSchema, ok := bodySchema.Blocks[block.Type]
+		if !ok {
+			if isComplete && !metaBlockTypes[block.Type] && !isAttributeAsBlock(bodySchema, block.Type) {
+				diags.add(CodeUnsupportedBlockType, unsupportedBlockDiag(block.Type, block.TypeRange))
+			}
+			continue
+		}
+
+		validateBlock(diags, block, bSchema, checkRequired)
+	}
+}
+
+// validateDynamicBlock validates content of a dynamic block
+// against the schema of the block it generates
+func validateDynamicBlock(diags Diagnostics, block *hclsyntax.Block, parentSchema *schema.BodySchema, isComplete, checkRequired bool) {
+	if len(block.Labels) != 1 {
+		return
+	}
+
+	bType := block.Labels[0]
+	bSchema, ok := parentSchema.Blocks[bType]
+	if !ok {
+		if isComplete && !isAttributeAsBlock(parentSchema, bType) {
+			diags.add(CodeUnsupportedBlockType, unsupportedBlockDiag(bType, block.LabelRanges[0]))
+		}
+		return
+	}
+
+	if bSchema.Body == nil {
+		return
+	}
+
+	for _, content := range block.Body.Blocks {
+		if content.Type != "content" {
+			continue
+		}
+		validateBody(diags, content.Body, bSchema.Body, isComplete, checkRequired)
+	}
+}
+
+// validateLabels reports missing or extraneous labels
+// of the block and returns true if the labels are valid
+func validateLabels(diags Diagnostics, block *hclsyntax.Block, bSchema *schema.BlockSchema) bool {
+	expected := len(bSchema.Labels)
+	found := len(block.Labels)
+
+	if found < expected {
+		missing := bSchema.Labels[found]
+		diags.add(CodeMissingLabel, &hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  fmt.Sprintf("Missing %s for %s", missing.Name, block.Type),
+			Detail: fmt.Sprintf("All %s blocks must have %d labels (%s).",
+				block.Type, expected, strings.Join(labelNames(bSchema), ", ")),
+			Subject: block.DefRange().Ptr(),
+		})
+		return false
+	}
+
+	if found > expected {
+		detail := fmt.Sprintf("No labels are expected for %s blocks.", block.Type)
+		if expected > 0 {
+			detail = fmt.Sprintf("Only %d labels (%s) are expected for %s blocks.",
+				expected, strings.Join(labelNames(bSchema), ", "), block.Type)
+		}
+		diags.add(CodeExtraneousLabel, &hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  fmt.Sprintf("Extraneous label for %s", block.Type),
+			Detail:   detail,
+			Subject:  block.LabelRanges[expected].Ptr(),
+		})
+		return false
+	}
+
+	return true
+}
+
+func validateCountAndForEach(diags Diagnostics, block *hclsyntax.Block) {
+	_, hasCount := block.Body.Attributes["count"]
+	forEach, hasForEach := block.Body.Attributes["for_each"]
+	if !hasCount || !hasForEach {
+		return
+	}
+
+	diags.add(CodeCountAndForEach, &hcl.Diagnostic{
+		Severity: hcl.DiagError,
+		Summary:  `Invalid combination of "count" and "for_each"`,
+		Detail: `The "count" and "for_each" meta-arguments are mutually-exclusive, ` +
+			`only one should be used to be explicit about the number of instances to be created.`,
+		Subject: forEach.NameRange.Ptr(),
+	})
+}
+
+// bodySchemaForBlock returns body schema of the given block,
+// merged with any dependent schema matching the block's labels
+// or attributes. The returned bool reports whether the schema
+// is complete, i.e. whether any dependent schema was found
+// where one was expected.
+func bodySchemaForBlock(block *hclsyntax.Block, bSchema *schema.BlockSchema) (*schema.BodySchema, bool) {
+	if !hasDependencyKeys(bSchema) {
+		return bSchema.Body, true
+	}
+
+	dk := dependencyKeysFromBlock(block, bSchema)
+	depSchema, ok := bSchema.DependentBodySchema(dk)
+	if !ok && len(dk.Attributes) > 0 {
+		// e.g. explicit reference to a default (non-aliased) provider
+		dk.Attributes = []schema.AttributeDependent{}
+		depSchema, ok = bSchema.DependentBodySchema(dk)
+	}
+	if !ok {
+		return bSchema.Body, false
+	}
+
+	return mergeBodySchemas(bSchema.Body, depSchema), true
+}
+
+func hasDependencyKeys(bSchema *schema.BlockSchema) bool {
+	for _, label := range bSchema.Labels {
+		if label.IsDepKey {
+			return true
+		}
+	}
+	if bSchema.Body != nil {
+		for _, attr := range bSchema.Body.Attributes {
+			if attr.IsDepKey {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func dependencyKeysFromBlock(block *hclsyntax.Block, bSchema *schema.BlockSchema) schema.DependencyKeys {
+	dk := schema.DependencyKeys{
+		Labels:     []schema.LabelDependent{},
+		Attributes: []schema.AttributeDependent{},
+	}
+	for i, label := range bSchema.Labels {
+		if label.IsDepKey {
+			dk.Labels = append(dk.Labels, schema.LabelDependent{
+				Index: i,
+				Value: block.Labels[i],
+			})
+		}
+	}
+
+	if bSchema.Body == nil {
+		return dk
+	}
+
+	for name, aSchema := range bSchema.Body.Attributes {
+		if !aSchema.IsDepKey {
+			continue
+		}
+		attr, ok := block.Body.Attributes[name]
+		if !ok {
+			continue
+		}
+
+		if st, ok := attr.Expr.(*hclsyntax.ScopeTraversalExpr); ok {
+			ref, ok := traversalToReference(st.AsTraversal())
+			if !ok {
+				continue
+			}
+			dk.Attributes = append(dk.Attributes, schema.AttributeDependent{
+				Name: name,
+				Expr: schema.ExpressionValue{Reference: ref},
+			})
+			continue
+		}
+
+		val, vDiags := attr.Expr.Value(nil)
+		if vDiags.HasErrors() || !val.IsWhollyKnown() || val.IsNull() {
+			continue
+		}
+		dk.Attributes = append(dk.Attributes, schema.AttributeDependent{
+			Name: name,
+			Expr: schema.ExpressionValue{Static: val},
+		})
+	}
+
+	return dk
+}
+
+func traversalToReference(traversal hcl.Traversal) (lang.Reference, bool) {
+	ref := lang.Reference{}
+	for _, step := range traversal {
+		switch s := step.(type) {
+		case hcl.TraverseRoot:
+			ref = append(ref, lang.RootStep{Name: s.Name})
+		case hcl.TraverseAttr:
+			ref = append(ref, lang.AttrStep{Name: s.Name})
+		case hcl.TraverseIndex:
+			ref = append(ref, lang.IndexStep{Key: s.Key})
+		default:
+			return nil, false
+		}
+	}
+	return ref, true
+}
+
+// mergeBodySchemas returns a new body schema containing attributes
+// and blocks of both schemas, where base takes precedence
+func mergeBodySchemas(base, dependent *schema.BodySchema) *schema.BodySchema {
+	merged := schema.NewBodySchema()
+	for _, bs := range []*schema.BodySchema{dependent, base} {
+		if bs == nil {
+			continue
+		}
+		for name, attr := range bs.Attributes {
+			merged.Attributes[name] = attr
+		}
+		for bType, block := range bs.Blocks {
+			merged.Blocks[bType] = block
+		}
+		if bs.AnyAttribute != nil {
+			merged.AnyAttribute = bs.AnyAttribute
+		}
+	}
+	return merged
+}
+
+// isAttributeAsBlock reports whether the given name represents
+// an attribute of a list or set of objects, which can also
+// be declared using block syntax
+func isAttributeAsBlock(bodySchema *schema.BodySchema, name string) bool {
+	aSchema, ok := bodySchema.Attributes[name]
+	if !ok {
+		return false
+	}
+	t := aSchema.ValueType
+	return (t.IsListType() || t.IsSetType()) && t.ElementType().IsObjectType()
+}
+
+func labelNames(bSchema *schema.BlockSchema) []string {
+	names := make([]string, len(bSchema.Labels))
+	for i, label := range bSchema.Labels {
+		names[i] = label.Name
+	}
+	return names
+}
+
+func unsupportedArgumentDiag(attr *hclsyntax.Attribute) *hcl.Diagnostic {
+	return &hcl.Diagnostic{
+		Severity: hcl.DiagError,
+		Summary:  "Unsupported argument",
+		Detail:   fmt.Sprintf("An argument named %q is not expected here.", attr.Name),
+		Subject:  attr.NameRange.Ptr(),
+	}
+}
+
+func missingArgumentDiag(name string, rng hcl.Range) *hcl.Diagnostic {
+	return &hcl.Diagnostic{
+		Severity: hcl.DiagError,
+		Summary:  "Missing required argument",
+		Detail:   fmt.Sprintf("The argument %q is required, but no definition was found.", name),
+		Subject:  rng.Ptr(),
+	}
+}
+
+func unsupportedBlockDiag(bType string, rng hcl.Range) *hcl.Diagnostic {
+	return &hcl.Diagnostic{
+		Severity: hcl.DiagError,
+		Summary:  "Unsupported block type",
+		Detail:   fmt.Sprintf("Blocks of type %q are not expected here.", bType),
+		Subject:  rng.Ptr(),
+	}
+}
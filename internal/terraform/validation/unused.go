@@ -0,0 +1,107 @@
+package validation
+
+import (
+	"fmt"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/terraform-ls/internal/terraform/declarations"
+)
+
+// UnusedDeclarations reports input variables, local values
+// and data sources declared in the given parsed files
+// of a single module (where key is a filename) which are never
+// referenced from anywhere else in the module.
+//
+// Outputs are never reported as they represent the interface
+// of the module, which is consumed from outside of it.
+func UnusedDeclarations(files map[string]*hcl.File) Diagnostics {
+	diags := newDiagnostics(files)
+
+	mod := declarations.Decode(files)
+	refs := declarations.DecodeReferences(files)
+
+	for name, decl := range mod.Variables {
+		if isReferenced(refs, decl, "var", name) {
+			continue
+		}
+		diags.add(CodeUnusedVariable, &hcl.Diagnostic{
+			Severity: hcl.DiagWarning,
+			Summary:  "Unused variable",
+			Detail:   fmt.Sprintf("Input variable %q is declared but never referenced.", name),
+			Subject:  decl.DeclRange.Ptr(),
+		})
+	}
+
+	for name, decl := range mod.Locals {
+		if isReferenced(refs, decl, "local", name) {
+			continue
+		}
+		diags.add(CodeUnusedLocal, &hcl.Diagnostic{
+			Severity: hcl.DiagWarning,
+			Summary:  "Unused local value",
+			Detail:   fmt.Sprintf("Local value %q is declared but never referenced.", name),
+			Subject:  decl.DeclRange.Ptr(),
+		})
+	}
+
+	for address, decl := range mod.DataSources {
+		if isReferenced(refs, decl, "data", address) {
+			continue
+		}
+		diags.add(CodeUnusedDataSource, &hcl.Diagnostic{
+			Severity: hcl.DiagWarning,
+			Summary:  "Unused data source",
+			Detail:   fmt.Sprintf("Data source %q is declared but never referenced.", "data."+address),
+			Subject:  decl.DeclRange.Ptr(),
+		})
+	}
+
+	diags.sort()
+
+	return diags
+}
+
+// isReferenced returns true if the declaration is referenced
+// from outside of its own definition, e.g. a variable referring
+// to itself from its validation block does not count as a reference
+func isReferenced(refs []declarations.Reference, decl *declarations.Declaration, rootName, address string) bool {
+	for _, ref := range refs {
+		if ref.Traversal.RootName() != rootName {
+			continue
+		}
+		if referencedAddress(ref.Traversal, rootName) != address {
+			continue
+		}
+		if decl.Attribute != nil {
+			// locals may reference each other within the same block
+			if rangeContains(decl.Attribute.SrcRange, ref.Range()) {
+				continue
+			}
+		} else if ref.Block == decl.Block {
+			continue
+		}
+		return true
+	}
+	return false
+}
+
+func referencedAddress(traversal hcl.Traversal, rootName string) string {
+	name, ok := attrName(traversal, 1)
+	if !ok {
+		return ""
+	}
+	if rootName != "data" {
+		return name
+	}
+	dsName, ok := attrName(traversal, 2)
+	if !ok {
+		return ""
+	}
+	return name + "." + dsName
+}
+
+func rangeContains(outer, inner hcl.Range) bool {
+	return outer.Filename == inner.Filename &&
+		outer.Start.Byte <= inner.Start.Byte &&
+		inner.End.Byte <= outer.End.Byte
+}
@@ -0,0 +1,88 @@
+package validation
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/hashicorp/hcl/v2"
+)
+
+func TestUnusedDeclarations(t *testing.T) {
+	testCases := []struct {
+		name            string
+		cfg             string
+		expectedDetails []string
+	}{
+		{
+			"all declarations used",
+			`variable "name" {}
+locals {
+  prefix = "${var.name}-"
+  full   = "${local.prefix}foo"
+}
+data "test_image" "one" {
+  name = local.full
+}
+output "out" {
+  value = data.test_image.one.id
+}
+`,
+			[]string{},
+		},
+		{
+			"unused declarations",
+			`variable "name" {}
+locals {
+  prefix = "foo"
+}
+data "test_image" "one" {
+}
+resource "test_instance" "two" {
+}
+output "out" {
+  value = "bar"
+}
+`,
+			[]string{
+				`Input variable "name" is declared but never referenced.`,
+				`Local value "prefix" is declared but never referenced.`,
+				`Data source "data.test_image.one" is declared but never referenced.`,
+			},
+		},
+		{
+			"self-references are ignored",
+			`variable "name" {
+  validation {
+    condition     = length(var.name) > 0
+    error_message = "Name must not be empty."
+  }
+}
+locals {
+  list = [for v in local.list : v]
+}
+`,
+			[]string{
+				`Input variable "name" is declared but never referenced.`,
+				`Local value "list" is declared but never referenced.`,
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			files := map[string]*hcl.File{
+				"test.tf": hclFile(t, "test.tf", tc.cfg),
+			}
+
+			diagsMap := UnusedDeclarations(files).Files
+			details := make([]string, 0)
+			for _, diag := range diagsMap["test.tf"] {
+				details = append(details, diag.Detail)
+			}
+
+			if diff := cmp.Diff(tc.expectedDetails, details); diff != "" {
+				t.Fatalf("diagnostics mismatch: %s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,51 @@
+// Package validation implements offline (in-process) validation
+// of Terraform configuration, which doesn't require Terraform
+// to be installed, or the module to be initialized.
+package validation
+
+import (
+	"sort"
+
+	"github.com/hashicorp/hcl-lang/schema"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/hashicorp/terraform-ls/internal/terraform/declarations"
+)
+
+// ValidateModule validates the given parsed files of a single module
+// (where key is a filename) against the given (merged) schema,
+// checks that objects are declared only once and that all
+// references point to declared objects.
+func ValidateModule(files map[string]*hcl.File, bodySchema *schema.BodySchema) Diagnostics {
+	diags := newDiagnostics(files)
+
+	if bodySchema != nil {
+		for _, f := range files {
+			body, ok := f.Body.(*hclsyntax.Body)
+			if !ok {
+				continue
+			}
+			validateRootBody(diags, body, bodySchema)
+		}
+	}
+
+	duplicateDeclarations(diags, files)
+
+	mod := declarations.Decode(files)
+	for _, ref := range declarations.DecodeReferences(files) {
+		validateReference(diags, mod, ref)
+	}
+
+	diags.sort()
+
+	return diags
+}
+
+func sortDiagnostics(diags hcl.Diagnostics) {
+	sort.SliceStable(diags, func(i, j int) bool {
+		if diags[i].Subject == nil || diags[j].Subject == nil {
+			return diags[j].Subject == nil && diags[i].Subject != nil
+		}
+		return diags[i].Subject.Start.Byte < diags[j].Subject.Start.Byte
+	})
+}
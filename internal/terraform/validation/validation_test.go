@@ -0,0 +1,289 @@
+package validation
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/hashicorp/go-version"
+	"github.com/hashicorp/hcl-lang/schema"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	tfjson "github.com/hashicorp/terraform-json"
+	tfschema "github.com/hashicorp/terraform-schema/schema"
+)
+
+func TestValidateModule(t *testing.T) {
+	testCases := []struct {
+		name              string
+		cfg               string
+		expectedSummaries []string
+	}{
+		{
+			"valid configuration",
+			`variable "name" {}
+locals {
+  prefix = "${var.name}-"
+}
+resource "test_instance" "one" {
+  ami = "${local.prefix}foo"
+}
+data "test_image" "two" {
+  name = test_instance.one.id
+}
+module "child" {
+  source = "./child"
+  input  = data.test_image.two.id
+}
+output "out" {
+  value = module.child.out
+}
+`,
+			[]string{},
+		},
+		{
+			"undeclared references",
+			`resource "test_instance" "one" {
+  ami   = var.name
+  tags  = local.tags
+  count = module.child.count
+}
+output "out" {
+  value = [test_instance.two.id, data.test_image.three.id]
+}
+`,
+			[]string{
+				"Reference to undeclared input variable",
+				"Reference to undeclared local value",
+				"Reference to undeclared module",
+				"Reference to undeclared resource",
+				"Reference to undeclared resource",
+			},
+		},
+		{
+			"builtin references and iterators",
+			`variable "list" {
+  type = list(string)
+}
+resource "test_instance" "one" {
+  for_each = toset(var.list)
+  ami      = "${each.key}-${path.module}-${terraform.workspace}"
+  dynamic "disk" {
+    for_each = var.list
+    content {
+      size = disk.value
+    }
+  }
+  tags = { for k, v in var.list : k => v }
+}
+`,
+			[]string{},
+		},
+		{
+			"unknown and missing attributes",
+			`resource "test_instance" "one" {
+  foo = "bar"
+  disk {
+    unknown = true
+  }
+  network {}
+}
+`,
+			[]string{
+				"Missing required argument",
+				"Unsupported argument",
+				"Unsupported argument",
+				"Unsupported block type",
+			},
+		},
+		{
+			"connection and provisioner arguments are not validated",
+			`resource "test_instance" "one" {
+  ami = "foo"
+  connection {
+    type        = "ssh"
+    host        = "example.com"
+    user        = "root"
+    private_key = "key"
+  }
+  provisioner "remote-exec" {
+    inline = ["echo hello"]
+    connection {
+      host = "example.com"
+    }
+  }
+}
+`,
+			[]string{},
+		},
+		{
+			"unknown resource type is not validated",
+			`resource "unknown_type" "one" {
+  foo = "bar"
+}
+`,
+			[]string{},
+		},
+		{
+			"invalid label count",
+			`resource "test_instance" {
+}
+variable "one" "two" {
+}
+`,
+			[]string{
+				"Missing name for resource",
+				"Extraneous label for variable",
+			},
+		},
+		{
+			"count and for_each",
+			`resource "test_instance" "one" {
+  ami      = "foo"
+  count    = 2
+  for_each = {}
+}
+`,
+			[]string{
+				`Invalid combination of "count" and "for_each"`,
+			},
+		},
+		{
+			"unknown root block and attribute",
+			`foo = "bar"
+resources "test_instance" "one" {
+}
+`,
+			[]string{
+				"Unsupported argument",
+				"Unsupported block type",
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			f, pDiags := hclsyntax.ParseConfig([]byte(tc.cfg), "test.tf", hcl.InitialPos)
+			if len(pDiags) > 0 {
+				t.Fatal(pDiags)
+			}
+			files := map[string]*hcl.File{
+				"test.tf": f,
+			}
+
+			diagsMap := ValidateModule(files, testSchema(t, files)).Files
+			summaries := make([]string, 0)
+			for _, diag := range diagsMap["test.tf"] {
+				summaries = append(summaries, diag.Summary)
+			}
+
+			if diff := cmp.Diff(tc.expectedSummaries, summaries); diff != "" {
+				t.Fatalf("diagnostics mismatch: %s", diff)
+			}
+		})
+	}
+}
+
+func TestValidateModule_entryForEachFile(t *testing.T) {
+	files := map[string]*hcl.File{
+		"first.tf":  hclFile(t, "first.tf", `variable "one" {}`),
+		"second.tf": hclFile(t, "second.tf", `output "two" { value = var.one }`),
+	}
+
+	diagsMap := ValidateModule(files, nil).Files
+	if len(diagsMap) != 2 {
+		t.Fatalf("expected 2 entries, %d given", len(diagsMap))
+	}
+	for filename, diags := range diagsMap {
+		if len(diags) > 0 {
+			t.Fatalf("unexpected diagnostics for %s: %s", filename, diags)
+		}
+	}
+}
+
+var testProviderSchemaJSON = `{
+  "format_version": "0.1",
+  "provider_schemas": {
+    "registry.terraform.io/hashicorp/test": {
+      "resource_schemas": {
+        "test_instance": {
+          "version": 0,
+          "block": {
+            "attributes": {
+              "ami": {"type": "string", "required": true},
+              "tags": {"type": ["map", "string"], "optional": true},
+              "legacy": {"type": "string", "optional": true, "deprecated": true},
+              "id": {"type": "string", "computed": true}
+            },
+            "block_types": {
+              "legacy_disk": {
+                "nesting_mode": "list",
+                "block": {"deprecated": true}
+              },
+              "disk": {
+                "nesting_mode": "list",
+                "block": {
+                  "attributes": {
+                    "size": {"type": "number", "optional": true}
+                  }
+                }
+              }
+            }
+          }
+        }
+      },
+      "data_source_schemas": {
+        "test_image": {
+          "version": 0,
+          "block": {
+            "attributes": {
+              "name": {"type": "string", "optional": true},
+              "id": {"type": "string", "computed": true}
+            }
+          }
+        },
+        "test_legacy_image": {
+          "version": 0,
+          "block": {
+            "attributes": {
+              "id": {"type": "string", "computed": true}
+            },
+            "deprecated": true
+          }
+        }
+      }
+    }
+  }
+}`
+
+func testSchema(t *testing.T, files map[string]*hcl.File) *schema.BodySchema {
+	ps := &tfjson.ProviderSchemas{}
+	err := json.Unmarshal([]byte(testProviderSchemaJSON), ps)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	coreSchema, err := tfschema.CoreModuleSchemaForVersion(version.Must(version.NewVersion("0.14.0")))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sm := tfschema.NewSchemaMerger(coreSchema)
+	sm.SetParsedFiles(files)
+	// Merger returns the core schema along with any errors
+	// from decoding invalid configuration, which is what we want
+	bodySchema, _ := sm.MergeWithJsonProviderSchemas(ps)
+	if bodySchema == nil {
+		t.Fatal("expected schema")
+	}
+
+	return bodySchema
+}
+
+func hclFile(t *testing.T, filename, src string) *hcl.File {
+	f, diags := hclsyntax.ParseConfig([]byte(src), filename, hcl.InitialPos)
+	if len(diags) > 0 {
+		t.Fatal(diags)
+	}
+	return f
+}
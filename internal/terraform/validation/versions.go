@@ -0,0 +1,215 @@
+package validation
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/go-version"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/hashicorp/terraform-ls/internal/terraform/declarations"
+	"github.com/zclconf/go-cty/cty"
+)
+
+const defaultProviderHost = "registry.terraform.io"
+
+// UnsatisfiedConstraint represents a version constraint declared
+// in the module, which is not satisfied by the installed version
+// of Terraform or a provider
+type UnsatisfiedConstraint struct {
+	// ProviderName is the local name of the provider as declared
+	// in required_providers, or empty for Terraform itself
+	ProviderName string
+
+	Constraint string
+	Version    *version.Version
+
+	// Range is the range of the string literal containing the constraint
+	Range hcl.Range
+}
+
+// Diagnostic returns the diagnostic describing the unsatisfied constraint
+func (c UnsatisfiedConstraint) Diagnostic() *hcl.Diagnostic {
+	if c.ProviderName == "" {
+		return &hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Unsupported Terraform Core version",
+			Detail: fmt.Sprintf("This configuration does not support Terraform version %s "+
+				"(required_version = %q). To proceed, either choose another supported "+
+				"Terraform version or update this version constraint.", c.Version, c.Constraint),
+			Subject: c.Range.Ptr(),
+		}
+	}
+
+	return &hcl.Diagnostic{
+		Severity: hcl.DiagError,
+		Summary:  "Unsupported provider version",
+		Detail: fmt.Sprintf("Installed version %s of provider %q does not match "+
+			"the version constraint %q. To proceed, either install another supported "+
+			"version of the provider or update this version constraint.",
+			c.Version, c.ProviderName, c.Constraint),
+		Subject: c.Range.Ptr(),
+	}
+}
+
+// Code returns code of the diagnostic describing the unsatisfied constraint
+func (c UnsatisfiedConstraint) Code() Code {
+	if c.ProviderName == "" {
+		return CodeUnsupportedTerraformVersion
+	}
+	return CodeUnsupportedProviderVersion
+}
+
+// PinnedConstraint returns a constraint matching exactly the installed version
+func (c UnsatisfiedConstraint) PinnedConstraint() string {
+	return c.Version.String()
+}
+
+// RelaxedConstraint returns a constraint allowing the installed version
+// along with any newer minor and patch versions
+func (c UnsatisfiedConstraint) RelaxedConstraint() string {
+	segments := c.Version.Segments()
+	return fmt.Sprintf("~> %d.%d", segments[0], segments[1])
+}
+
+// UnsatisfiedVersionConstraints compares required_version and required_providers
+// constraints declared in the given parsed files of a single module
+// against the installed versions of Terraform and providers,
+// where providerVersions is keyed by provider address.
+//
+// Constraints are only checked where the installed version is known.
+func UnsatisfiedVersionConstraints(files map[string]*hcl.File, tfVersion *version.Version,
+	providerVersions map[string]*version.Version) []UnsatisfiedConstraint {
+	constraints := make([]UnsatisfiedConstraint, 0)
+
+	for _, filename := range declarations.SortedFilenames(files) {
+		body, ok := files[filename].Body.(*hclsyntax.Body)
+		if !ok {
+			continue
+		}
+
+		for _, block := range body.Blocks {
+			if block.Type != "terraform" {
+				continue
+			}
+
+			if attr, ok := block.Body.Attributes["required_version"]; ok && tfVersion != nil {
+				c, ok := checkConstraint(attr.Expr, tfVersion)
+				if ok {
+					constraints = append(constraints, c)
+				}
+			}
+
+			for _, rpBlock := range block.Body.Blocks {
+				if rpBlock.Type != "required_providers" {
+					continue
+				}
+				for _, attr := range declarations.SortedAttributes(rpBlock.Body) {
+					constraintExpr, source := requiredProviderExprs(attr.Expr)
+					if constraintExpr == nil {
+						continue
+					}
+					pVersion, ok := findProviderVersion(providerVersions, attr.Name, source)
+					if !ok {
+						continue
+					}
+					c, ok := checkConstraint(constraintExpr, pVersion)
+					if ok {
+						c.ProviderName = attr.Name
+						constraints = append(constraints, c)
+					}
+				}
+			}
+		}
+	}
+
+	return constraints
+}
+
+// VersionConstraints reports version constraints which are not satisfied
+// by the installed versions of Terraform and providers.
+func VersionConstraints(files map[string]*hcl.File, tfVersion *version.Version,
+	providerVersions map[string]*version.Version) Diagnostics {
+	diags := newDiagnostics(files)
+
+	for _, c := range UnsatisfiedVersionConstraints(files, tfVersion, providerVersions) {
+		diags.add(c.Code(), c.Diagnostic())
+	}
+
+	return diags
+}
+
+// checkConstraint returns the unsatisfied constraint if the given
+// expression is a valid constraint which the given version does not satisfy
+func checkConstraint(expr hclsyntax.Expression, v *version.Version) (UnsatisfiedConstraint, bool) {
+	val, diags := expr.Value(nil)
+	if diags.HasErrors() || !val.IsWhollyKnown() || val.IsNull() || val.Type() != cty.String {
+		return UnsatisfiedConstraint{}, false
+	}
+
+	raw := val.AsString()
+	vc, err := version.NewConstraint(raw)
+	if err != nil {
+		return UnsatisfiedConstraint{}, false
+	}
+
+	if vc.Check(v) {
+		return UnsatisfiedConstraint{}, false
+	}
+
+	return UnsatisfiedConstraint{
+		Constraint: raw,
+		Version:    v,
+		Range:      expr.Range(),
+	}, true
+}
+
+// requiredProviderExprs returns the version constraint expression and source
+// (if any) of an entry in the required_providers block, which can be
+// either a string (legacy syntax), or an object with version and source
+func requiredProviderExprs(expr hclsyntax.Expression) (hclsyntax.Expression, string) {
+	obj, ok := expr.(*hclsyntax.ObjectConsExpr)
+	if !ok {
+		return expr, ""
+	}
+
+	var constraintExpr hclsyntax.Expression
+	source := ""
+	for _, item := range obj.Items {
+		key, diags := item.KeyExpr.Value(nil)
+		if diags.HasErrors() || key.Type() != cty.String {
+			continue
+		}
+		switch key.AsString() {
+		case "version":
+			constraintExpr = item.ValueExpr
+		case "source":
+			val, diags := item.ValueExpr.Value(nil)
+			if !diags.HasErrors() && val.Type() == cty.String && val.IsKnown() && !val.IsNull() {
+				source = val.AsString()
+			}
+		}
+	}
+
+	return constraintExpr, source
+}
+
+// findProviderVersion finds version of the provider by its local name
+// and source, where the source is normalized to the full address
+func findProviderVersion(providerVersions map[string]*version.Version, name, source string) (*version.Version, bool) {
+	addr := defaultProviderHost + "/hashicorp/" + name
+	if source != "" {
+		addr = source
+		if strings.Count(source, "/") == 1 {
+			addr = defaultProviderHost + "/" + source
+		}
+	}
+
+	for _, key := range []string{addr, name} {
+		if v, ok := providerVersions[key]; ok && v != nil {
+			return v, true
+		}
+	}
+
+	return nil, false
+}
@@ -0,0 +1,139 @@
+package validation
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/hashicorp/go-version"
+	"github.com/hashicorp/hcl/v2"
+)
+
+func TestUnsatisfiedVersionConstraints(t *testing.T) {
+	testCases := []struct {
+		name                string
+		cfg                 string
+		tfVersion           string
+		providerVersions    map[string]string
+		expectedConstraints []UnsatisfiedConstraint
+	}{
+		{
+			"satisfied constraints",
+			`terraform {
+  required_version = ">= 0.13"
+  required_providers {
+    aws = {
+      source  = "hashicorp/aws"
+      version = "~> 3.0"
+    }
+    null = "~> 2.0"
+  }
+}
+`,
+			"0.14.2",
+			map[string]string{
+				"registry.terraform.io/hashicorp/aws":  "3.20.0",
+				"registry.terraform.io/hashicorp/null": "2.1.2",
+			},
+			[]UnsatisfiedConstraint{},
+		},
+		{
+			"unknown versions",
+			`terraform {
+  required_version = ">= 0.13"
+  required_providers {
+    aws = "~> 3.0"
+  }
+}
+`,
+			"",
+			map[string]string{},
+			[]UnsatisfiedConstraint{},
+		},
+		{
+			"unsatisfied constraints",
+			`terraform {
+  required_version = "~> 0.12.0"
+  required_providers {
+    aws = {
+      source  = "hashicorp/aws"
+      version = "~> 2.0"
+    }
+    legacy = "1.0.0"
+  }
+}
+`,
+			"0.14.2",
+			map[string]string{
+				"registry.terraform.io/hashicorp/aws": "3.20.0",
+				"legacy":                              "1.1.0",
+			},
+			[]UnsatisfiedConstraint{
+				{
+					Constraint: "~> 0.12.0",
+					Version:    version.Must(version.NewVersion("0.14.2")),
+					Range: hcl.Range{
+						Filename: "test.tf",
+						Start:    hcl.Pos{Line: 2, Column: 22, Byte: 33},
+						End:      hcl.Pos{Line: 2, Column: 33, Byte: 44},
+					},
+				},
+				{
+					ProviderName: "aws",
+					Constraint:   "~> 2.0",
+					Version:      version.Must(version.NewVersion("3.20.0")),
+					Range: hcl.Range{
+						Filename: "test.tf",
+						Start:    hcl.Pos{Line: 6, Column: 17, Byte: 128},
+						End:      hcl.Pos{Line: 6, Column: 25, Byte: 136},
+					},
+				},
+				{
+					ProviderName: "legacy",
+					Constraint:   "1.0.0",
+					Version:      version.Must(version.NewVersion("1.1.0")),
+					Range: hcl.Range{
+						Filename: "test.tf",
+						Start:    hcl.Pos{Line: 8, Column: 14, Byte: 156},
+						End:      hcl.Pos{Line: 8, Column: 21, Byte: 163},
+					},
+				},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			files := map[string]*hcl.File{
+				"test.tf": hclFile(t, "test.tf", tc.cfg),
+			}
+
+			var tfVersion *version.Version
+			if tc.tfVersion != "" {
+				tfVersion = version.Must(version.NewVersion(tc.tfVersion))
+			}
+			providerVersions := make(map[string]*version.Version, 0)
+			for addr, v := range tc.providerVersions {
+				providerVersions[addr] = version.Must(version.NewVersion(v))
+			}
+
+			constraints := UnsatisfiedVersionConstraints(files, tfVersion, providerVersions)
+			if diff := cmp.Diff(tc.expectedConstraints, constraints); diff != "" {
+				t.Fatalf("constraints mismatch: %s", diff)
+			}
+		})
+	}
+}
+
+func TestUnsatisfiedConstraint_suggestions(t *testing.T) {
+	c := UnsatisfiedConstraint{
+		Constraint: "~> 0.12.0",
+		Version:    version.Must(version.NewVersion("0.14.2")),
+	}
+
+	if pinned := c.PinnedConstraint(); pinned != "0.14.2" {
+		t.Fatalf("unexpected pinned constraint: %q", pinned)
+	}
+	if relaxed := c.RelaxedConstraint(); relaxed != "~> 0.14" {
+		t.Fatalf("unexpected relaxed constraint: %q", relaxed)
+	}
+}
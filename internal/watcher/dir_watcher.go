@@ -0,0 +1,207 @@
+package watcher
+
+import (
+	"context"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// dirWatcher is a wrapper around native fsnotify.Watcher
+// which watches directories (non-recursively) for entries
+// being created or removed, such as new modules or .terraform
+// directories appearing after the workspace was walked,
+// as well as for files within them being changed
+type dirWatcher struct {
+	fw       *fsnotify.Watcher
+	dirs     map[string]bool
+	dirsMu   *sync.RWMutex
+	dirHooks []DirHook
+	logger   *log.Logger
+
+	watching   bool
+	cancelFunc context.CancelFunc
+}
+
+type DirWatcherFactory func() (DirWatcher, error)
+
+func NewDirWatcher() (DirWatcher, error) {
+	fw, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, err
+	}
+	return &dirWatcher{
+		fw:     fw,
+		logger: defaultLogger,
+		dirs:   make(map[string]bool, 0),
+		dirsMu: &sync.RWMutex{},
+	}, nil
+}
+
+func (w *dirWatcher) SetLogger(logger *log.Logger) {
+	w.logger = logger
+}
+
+func (w *dirWatcher) AddDir(dir string) error {
+	w.dirsMu.Lock()
+	defer w.dirsMu.Unlock()
+
+	if _, ok := w.dirs[dir]; ok {
+		return nil
+	}
+
+	err := w.fw.Add(dir)
+	if err != nil {
+		return err
+	}
+	w.dirs[dir] = true
+
+	return nil
+}
+
+func (w *dirWatcher) RemoveDir(dir string) error {
+	w.dirsMu.Lock()
+	defer w.dirsMu.Unlock()
+
+	return w.removeDir(dir)
+}
+
+func (w *dirWatcher) removeDir(dir string) error {
+	if _, ok := w.dirs[dir]; !ok {
+		return nil
+	}
+
+	prefix := dir + string(filepath.Separator)
+	for d := range w.dirs {
+		if d == dir || strings.HasPrefix(d, prefix) {
+			delete(w.dirs, d)
+			// the directory may be gone already, in which case
+			// fsnotify stops watching it on its own
+			w.fw.Remove(d)
+		}
+	}
+
+	return nil
+}
+
+func (w *dirWatcher) AddDirHook(h DirHook) {
+	w.dirHooks = append(w.dirHooks, h)
+}
+
+func (w *dirWatcher) run(ctx context.Context) {
+	for {
+		select {
+		case event, ok := <-w.fw.Events:
+			if !ok {
+				return
+			}
+
+			de, ok := w.dirEventFromFsEvent(event)
+			if !ok {
+				continue
+			}
+
+			for _, h := range w.dirHooks {
+				err := h(ctx, de)
+				if err != nil {
+					w.logger.Println("dir hook error:", err)
+				}
+			}
+		case err, ok := <-w.fw.Errors:
+			if !ok {
+				return
+			}
+			w.logger.Println("dir watch error:", err)
+		}
+	}
+}
+
+func (w *dirWatcher) dirEventFromFsEvent(event fsnotify.Event) (DirEvent, bool) {
+	path := filepath.Clean(event.Name)
+
+	if event.Op&fsnotify.Create == fsnotify.Create {
+		w.logger.Printf("detected creation of %s", path)
+		fi, err := os.Stat(path)
+		if err != nil {
+			// entry may have been removed in the meantime
+			return DirEvent{}, false
+		}
+		return DirEvent{
+			Path:  path,
+			Kind:  DirEntryCreated,
+			IsDir: fi.IsDir(),
+		}, true
+	}
+
+	// Renamed entries are reported as removed, since the new name
+	// (if within a watched directory) is reported as created
+	if event.Op&fsnotify.Remove == fsnotify.Remove ||
+		event.Op&fsnotify.Rename == fsnotify.Rename {
+		w.logger.Printf("detected removal of %s", path)
+
+		w.dirsMu.Lock()
+		_, isDir := w.dirs[path]
+		w.removeDir(path)
+		w.dirsMu.Unlock()
+
+		return DirEvent{
+			Path:  path,
+			Kind:  DirEntryRemoved,
+			IsDir: isDir,
+		}, true
+	}
+
+	if event.Op&fsnotify.Write == fsnotify.Write {
+		w.dirsMu.RLock()
+		_, isDir := w.dirs[path]
+		w.dirsMu.RUnlock()
+		if isDir {
+			return DirEvent{}, false
+		}
+
+		w.logger.Printf("detected change of %s", path)
+		return DirEvent{
+			Path: path,
+			Kind: DirEntryChanged,
+		}, true
+	}
+
+	return DirEvent{}, false
+}
+
+// Start starts to watch for changes in directories that were added
+// via AddDir until Stop() is called
+func (w *dirWatcher) Start() error {
+	if w.watching {
+		w.logger.Println("watching directories already in progress")
+		return nil
+	}
+
+	ctx, cancelFunc := context.WithCancel(context.Background())
+	w.cancelFunc = cancelFunc
+	w.watching = true
+
+	w.logger.Printf("watching directories for changes ...")
+	go w.run(ctx)
+
+	return nil
+}
+
+func (w *dirWatcher) Stop() error {
+	if !w.watching {
+		return nil
+	}
+
+	w.cancelFunc()
+
+	err := w.fw.Close()
+	if err == nil {
+		w.watching = false
+	}
+
+	return err
+}
@@ -19,4 +19,54 @@ type Watcher interface {
 	AddChangeHook(f ChangeHook)
 }
 
-type ChangeHook func(ctx context.Context, file TrackedFile) error
+type ChangeKind int
+
+const (
+	FileChanged ChangeKind = iota
+	FileCreated
+	FileRemoved
+)
+
+func (k ChangeKind) String() string {
+	switch k {
+	case FileChanged:
+		return "changed"
+	case FileCreated:
+		return "created"
+	case FileRemoved:
+		return "removed"
+	}
+	return "unknown"
+}
+
+// ChangeHook is called for each change of a tracked file.
+// Removed file is represented by TrackedFile with empty checksum.
+type ChangeHook func(ctx context.Context, file TrackedFile, kind ChangeKind) error
+
+type DirWatcher interface {
+	Start() error
+	Stop() error
+	SetLogger(logger *log.Logger)
+	AddDir(dir string) error
+	RemoveDir(dir string) error
+	AddDirHook(f DirHook)
+}
+
+type DirEventKind int
+
+const (
+	DirEntryCreated DirEventKind = iota
+	DirEntryRemoved
+	DirEntryChanged
+)
+
+// DirEvent represents an entry (file or directory)
+// created or removed within a watched directory,
+// or a file within a watched directory being written to
+type DirEvent struct {
+	Path  string
+	Kind  DirEventKind
+	IsDir bool
+}
+
+type DirHook func(ctx context.Context, event DirEvent) error
@@ -4,6 +4,10 @@ import (
 	"context"
 	"io/ioutil"
 	"log"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
 
 	"github.com/fsnotify/fsnotify"
 )
@@ -12,10 +16,18 @@ import (
 // It provides the ability to detect actual file changes
 // (rather than just events that may not be changing any bytes)
 type watcher struct {
-	fw           *fsnotify.Watcher
-	trackedFiles map[string]TrackedFile
-	changeHooks  []ChangeHook
-	logger       *log.Logger
+	fw             *fsnotify.Watcher
+	trackedFiles   map[string]TrackedFile
+	trackedFilesMu *sync.RWMutex
+	changeHooks    []ChangeHook
+	logger         *log.Logger
+
+	// debounceWindow represents time to wait for further events
+	// for the same path before the change is processed, such that
+	// e.g. file being replaced via rename is reported just once
+	debounceWindow time.Duration
+	pendingChanges map[string]*time.Timer
+	changedPaths   chan string
 
 	watching   bool
 	cancelFunc context.CancelFunc
@@ -23,15 +35,21 @@ type watcher struct {
 
 type WatcherFactory func() (Watcher, error)
 
+const defaultDebounceWindow = 100 * time.Millisecond
+
 func NewWatcher() (Watcher, error) {
 	fw, err := fsnotify.NewWatcher()
 	if err != nil {
 		return nil, err
 	}
 	return &watcher{
-		fw:           fw,
-		logger:       defaultLogger,
-		trackedFiles: make(map[string]TrackedFile, 0),
+		fw:             fw,
+		logger:         defaultLogger,
+		trackedFiles:   make(map[string]TrackedFile, 0),
+		trackedFilesMu: &sync.RWMutex{},
+		debounceWindow: defaultDebounceWindow,
+		pendingChanges: make(map[string]*time.Timer, 0),
+		changedPaths:   make(chan string),
 	}, nil
 }
 
@@ -41,24 +59,41 @@ func (w *watcher) SetLogger(logger *log.Logger) {
 	w.logger = logger
 }
 
+// AddPaths adds all given paths for watching, even if some of them
+// cannot be watched (e.g. once the limit of watches is reached),
+// in which case the first error is returned
 func (w *watcher) AddPaths(paths []string) error {
+	var firstErr error
 	for _, p := range paths {
 		err := w.AddPath(p)
 		if err != nil {
-			return err
+			w.logger.Printf("unable to watch %s: %s", p, err)
+			if firstErr == nil {
+				firstErr = err
+			}
 		}
 	}
-	return nil
+	return firstErr
 }
 
 func (w *watcher) AddPath(path string) error {
 	w.logger.Printf("adding %s for watching", path)
+	path = filepath.Clean(path)
 
 	tf, err := trackedFileFromPath(path)
 	if err != nil {
 		return err
 	}
+	w.trackedFilesMu.Lock()
 	w.trackedFiles[path] = tf
+	w.trackedFilesMu.Unlock()
+
+	// Files are often replaced (e.g. via rename), which is only
+	// visible in the parent directory, so that is watched too
+	err = w.fw.Add(filepath.Dir(path))
+	if err != nil {
+		w.logger.Printf("unable to watch parent directory of %s: %s", path, err)
+	}
 
 	return w.fw.Add(path)
 }
@@ -70,30 +105,29 @@ func (w *watcher) AddChangeHook(h ChangeHook) {
 func (w *watcher) run(ctx context.Context) {
 	for {
 		select {
+		case <-ctx.Done():
+			w.stopPendingChanges()
+			return
 		case event, ok := <-w.fw.Events:
 			if !ok {
+				w.stopPendingChanges()
 				return
 			}
 
-			if event.Op&fsnotify.Write == fsnotify.Write {
-				w.logger.Printf("detected write into %s", event.Name)
-				oldTf := w.trackedFiles[event.Name]
-				newTf, err := trackedFileFromPath(event.Name)
-				if err != nil {
-					w.logger.Println("failed to track file, ignoring", err)
-					continue
-				}
-				w.trackedFiles[event.Name] = newTf
-
-				if oldTf.Sha256Sum() != newTf.Sha256Sum() {
-					for _, h := range w.changeHooks {
-						err := h(ctx, newTf)
-						if err != nil {
-							w.logger.Println("change hook error:", err)
-						}
-					}
-				}
+			path := filepath.Clean(event.Name)
+			if !w.isTracked(path) {
+				// event in parent directory of a tracked file
+				continue
+			}
+			if event.Op == fsnotify.Chmod {
+				continue
 			}
+
+			w.logger.Printf("detected %s of %s", event.Op, path)
+			w.debounce(ctx, path)
+		case path := <-w.changedPaths:
+			delete(w.pendingChanges, path)
+			w.processChange(ctx, path)
 		case err, ok := <-w.fw.Errors:
 			if !ok {
 				return
@@ -103,6 +137,86 @@ func (w *watcher) run(ctx context.Context) {
 	}
 }
 
+// debounce (re)schedules processing of change of the given path,
+// coalescing any further events received within the debounce window
+func (w *watcher) debounce(ctx context.Context, path string) {
+	if t, ok := w.pendingChanges[path]; ok {
+		t.Stop()
+	}
+	w.pendingChanges[path] = time.AfterFunc(w.debounceWindow, func() {
+		select {
+		case w.changedPaths <- path:
+		case <-ctx.Done():
+		}
+	})
+}
+
+func (w *watcher) stopPendingChanges() {
+	for path, t := range w.pendingChanges {
+		t.Stop()
+		delete(w.pendingChanges, path)
+	}
+}
+
+func (w *watcher) processChange(ctx context.Context, path string) {
+	w.trackedFilesMu.RLock()
+	oldTf := w.trackedFiles[path]
+	w.trackedFilesMu.RUnlock()
+
+	var kind ChangeKind
+	newTf, err := trackedFileFromPath(path)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			w.logger.Println("failed to track file, ignoring", err)
+			return
+		}
+		if isMissingFile(oldTf) {
+			return
+		}
+		// File is kept tracked, so it can be picked up again once it reappears
+		kind = FileRemoved
+		newTf = &trackedFile{path: path}
+	} else {
+		// Any watch of a replaced file is gone along with the original file
+		err = w.fw.Add(path)
+		if err != nil {
+			w.logger.Printf("failed to re-add %s for watching: %s", path, err)
+		}
+
+		switch {
+		case isMissingFile(oldTf):
+			kind = FileCreated
+		case oldTf.Sha256Sum() != newTf.Sha256Sum():
+			kind = FileChanged
+		default:
+			return
+		}
+	}
+
+	w.trackedFilesMu.Lock()
+	w.trackedFiles[path] = newTf
+	w.trackedFilesMu.Unlock()
+
+	w.logger.Printf("%s was %s", path, kind)
+	for _, h := range w.changeHooks {
+		err := h(ctx, newTf, kind)
+		if err != nil {
+			w.logger.Println("change hook error:", err)
+		}
+	}
+}
+
+func (w *watcher) isTracked(path string) bool {
+	w.trackedFilesMu.RLock()
+	defer w.trackedFilesMu.RUnlock()
+	_, ok := w.trackedFiles[path]
+	return ok
+}
+
+func isMissingFile(tf TrackedFile) bool {
+	return tf == nil || tf.Sha256Sum() == ""
+}
+
 // StartWatching starts to watch for changes that were added
 // via AddPath(s) until Stop() is called
 func (w *watcher) Start() error {
@@ -32,3 +32,32 @@ func (w *mockWatcher) Stop() error {
 }
 
 func (w *mockWatcher) SetLogger(*log.Logger) {}
+
+func MockDirWatcher() DirWatcherFactory {
+	return func() (DirWatcher, error) {
+		return &mockDirWatcher{}, nil
+	}
+}
+
+type mockDirWatcher struct{}
+
+func (w *mockDirWatcher) AddDirHook(h DirHook) {
+}
+
+func (w *mockDirWatcher) AddDir(dir string) error {
+	return nil
+}
+
+func (w *mockDirWatcher) RemoveDir(dir string) error {
+	return nil
+}
+
+func (w *mockDirWatcher) Start() error {
+	return nil
+}
+
+func (w *mockDirWatcher) Stop() error {
+	return nil
+}
+
+func (w *mockDirWatcher) SetLogger(*log.Logger) {}
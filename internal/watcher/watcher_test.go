@@ -0,0 +1,137 @@
+package watcher
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestWatcher_changeKinds(t *testing.T) {
+	dir, err := ioutil.TempDir("", "watcher")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "selections.json")
+	writeFile(t, path, "initial")
+
+	ww, err := NewWatcher()
+	if err != nil {
+		t.Fatal(err)
+	}
+	w := ww.(*watcher)
+	w.debounceWindow = 50 * time.Millisecond
+
+	changes := make(chan ChangeKind, 10)
+	w.AddChangeHook(func(_ context.Context, file TrackedFile, kind ChangeKind) error {
+		if file.Path() != path {
+			t.Errorf("unexpected path: %q", file.Path())
+		}
+		changes <- kind
+		return nil
+	})
+
+	err = w.AddPath(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = w.Start()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Stop()
+
+	// burst of writes is reported once
+	writeFile(t, path, "first")
+	writeFile(t, path, "second")
+	expectChange(t, changes, FileChanged)
+
+	// file replaced via rename remains watched
+	tmpPath := filepath.Join(dir, "selections.json.tmp")
+	writeFile(t, tmpPath, "replaced")
+	err = os.Rename(tmpPath, path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectChange(t, changes, FileChanged)
+
+	writeFile(t, path, "after replacement")
+	expectChange(t, changes, FileChanged)
+
+	err = os.Remove(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectChange(t, changes, FileRemoved)
+
+	writeFile(t, path, "recreated")
+	expectChange(t, changes, FileCreated)
+
+	// writes without changing content are not reported
+	writeFile(t, path, "recreated")
+	expectNoChange(t, changes)
+}
+
+func TestWatcher_AddPaths_partialFailure(t *testing.T) {
+	dir, err := ioutil.TempDir("", "watcher")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	missingPath := filepath.Join(dir, "missing.json")
+	path := filepath.Join(dir, "selections.json")
+	writeFile(t, path, "initial")
+
+	ww, err := NewWatcher()
+	if err != nil {
+		t.Fatal(err)
+	}
+	w := ww.(*watcher)
+	defer w.Stop()
+
+	err = w.AddPaths([]string{missingPath, path})
+	if err == nil {
+		t.Fatal("expected error for missing path")
+	}
+
+	w.trackedFilesMu.RLock()
+	_, ok := w.trackedFiles[path]
+	w.trackedFilesMu.RUnlock()
+	if !ok {
+		t.Fatal("expected path after the failed one to be watched")
+	}
+}
+
+func expectChange(t *testing.T, changes <-chan ChangeKind, expectedKind ChangeKind) {
+	t.Helper()
+	select {
+	case kind := <-changes:
+		if kind != expectedKind {
+			t.Fatalf("expected %s, got %s", expectedKind, kind)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("timed out waiting for file to be %s", expectedKind)
+	}
+	expectNoChange(t, changes)
+}
+
+func expectNoChange(t *testing.T, changes <-chan ChangeKind) {
+	t.Helper()
+	select {
+	case kind := <-changes:
+		t.Fatalf("unexpected change: %s", kind)
+	case <-time.After(200 * time.Millisecond):
+	}
+}
+
+func writeFile(t *testing.T, path, content string) {
+	err := ioutil.WriteFile(path, []byte(content), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+}